@@ -0,0 +1,243 @@
+// fieldgen 按 gorm 模型生成 dbmysql.Field 列字段
+//
+//	//go:generate go run github.com/caoyuewen/components/cmd/fieldgen
+//
+// 扫描当前目录 (或 -dir) 下带 TableName 方法的结构体，为每个源文件生成 <file>_fields.go：
+//
+//	var GoodsOrderFields = struct{ ID dbmysql.Field[string]; ... }{...}
+//	var GoodsOrderColumns = []string{"id", ...}
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+const dbmysqlImport = "github.com/caoyuewen/components/dbs/dbmysql"
+
+// model 模型定义
+type model struct {
+	name    string
+	columns []column
+}
+
+// column 列定义
+type column struct {
+	field  string
+	name   string
+	goType string
+}
+
+func main() {
+	dir := flag.String("dir", ".", "模型所在目录")
+	flag.Parse()
+
+	if err := run(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, "fieldgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && !strings.HasSuffix(name, "_fields.go")
+	}, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		tables := tableTypes(pkg)
+
+		files := make([]string, 0, len(pkg.Files))
+		for path := range pkg.Files {
+			files = append(files, path)
+		}
+		sort.Strings(files)
+
+		for _, path := range files {
+			file := pkg.Files[path]
+			models, imports := parseModels(file, tables)
+			if len(models) == 0 {
+				continue
+			}
+
+			src, err := render(pkg.Name, models, imports)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			out := strings.TrimSuffix(path, ".go") + "_fields.go"
+			if err = os.WriteFile(out, src, 0o644); err != nil {
+				return err
+			}
+			fmt.Println("fieldgen:", filepath.Base(out))
+		}
+	}
+	return nil
+}
+
+// tableTypes 包内声明了 TableName 方法的类型
+func tableTypes(pkg *ast.Package) map[string]bool {
+	res := map[string]bool{}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "TableName" || len(fn.Recv.List) == 0 {
+				continue
+			}
+			typ := fn.Recv.List[0].Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if ident, ok := typ.(*ast.Ident); ok {
+				res[ident.Name] = true
+			}
+		}
+	}
+	return res
+}
+
+// parseModels 解析文件中的模型，返回模型与列类型用到的外部包
+func parseModels(file *ast.File, tables map[string]bool) ([]model, map[string]string) {
+	fileImports := map[string]string{}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := filepath.Base(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		fileImports[name] = path
+	}
+
+	var (
+		naming  = schema.NamingStrategy{}
+		models  []model
+		imports = map[string]string{}
+	)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || !tables[ts.Name.Name] {
+				continue
+			}
+
+			m := model{name: ts.Name.Name}
+			for _, f := range st.Fields.List {
+				if len(f.Names) == 0 {
+					continue
+				}
+
+				tag := ""
+				if f.Tag != nil {
+					raw, _ := strconv.Unquote(f.Tag.Value)
+					tag = reflect.StructTag(raw).Get("gorm")
+				}
+				settings := schema.ParseTagSetting(tag, ";")
+				if _, ignore := settings["-"]; ignore || tag == "-" {
+					continue
+				}
+
+				goType := types(f.Type, fileImports, imports)
+				for _, name := range f.Names {
+					if !name.IsExported() {
+						continue
+					}
+					col := settings["COLUMN"]
+					if col == "" {
+						col = naming.ColumnName("", name.Name)
+					}
+					m.columns = append(m.columns, column{field: name.Name, name: col, goType: goType})
+				}
+			}
+			models = append(models, m)
+		}
+	}
+	return models, imports
+}
+
+// types 类型表达式转为源码，记录用到的包
+func types(expr ast.Expr, fileImports, used map[string]string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + types(t.X, fileImports, used)
+	case *ast.ArrayType:
+		return "[]" + types(t.Elt, fileImports, used)
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			used[pkg.Name] = fileImports[pkg.Name]
+			return pkg.Name + "." + t.Sel.Name
+		}
+	}
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+func render(pkgName string, models []model, imports map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by fieldgen; DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+
+	imports["dbmysql"] = dbmysqlImport
+	paths := make([]string, 0, len(imports))
+	for name, path := range imports {
+		if filepath.Base(path) != name {
+			path = name + `" "` + path // 别名导入
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	buf.WriteString("import (\n")
+	for _, p := range paths {
+		if i := strings.Index(p, `" "`); i >= 0 {
+			fmt.Fprintf(&buf, "\t%s %q\n", p[:i], p[i+3:])
+		} else {
+			fmt.Fprintf(&buf, "\t%q\n", p)
+		}
+	}
+	buf.WriteString(")\n")
+
+	for _, m := range models {
+		fmt.Fprintf(&buf, "\n// %sFields %s 列字段\nvar %sFields = struct {\n", m.name, m.name, m.name)
+		for _, c := range m.columns {
+			fmt.Fprintf(&buf, "\t%s dbmysql.Field[%s]\n", c.field, c.goType)
+		}
+		buf.WriteString("}{\n")
+		for _, c := range m.columns {
+			fmt.Fprintf(&buf, "\t%s: dbmysql.NewField[%s](%q),\n", c.field, c.goType, c.name)
+		}
+		buf.WriteString("}\n")
+
+		fmt.Fprintf(&buf, "\n// %sColumns %s 全部列名，可作为 dbmysql.SafeOrder 的白名单\nvar %sColumns = []string{", m.name, m.name, m.name)
+		for i, c := range m.columns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%q", c.name)
+		}
+		buf.WriteString("}\n")
+	}
+
+	return format.Source(buf.Bytes())
+}
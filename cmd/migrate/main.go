@@ -0,0 +1,136 @@
+// migrate 数据库版本迁移命令
+//
+//	migrate -addr 127.0.0.1:3306 -user root -db pay [-dir ./migrations] [-models] [-dry-run] up [version]
+//	migrate ... down [steps]
+//	migrate ... status
+//	migrate ... force <version>
+//
+// 密码通过 -password 或环境变量 MYSQL_PASSWORD 传入
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/caoyuewen/components/common/models"
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"github.com/caoyuewen/components/dbs/dbmysql/migrate"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	var (
+		addr     = flag.String("addr", "127.0.0.1:3306", "数据库地址 host:port")
+		user     = flag.String("user", "root", "用户名")
+		password = flag.String("password", os.Getenv("MYSQL_PASSWORD"), "密码，默认读取 MYSQL_PASSWORD")
+		dbName   = flag.String("db", "", "数据库名")
+		dir      = flag.String("dir", "", "SQL 迁移文件目录 (NNNN_name.up.sql / NNNN_name.down.sql)")
+		withMod  = flag.Bool("models", false, "包含 common/models 的迁移")
+		table    = flag.String("table", "", "版本表名，默认 schema_migrations")
+		dryRun   = flag.Bool("dry-run", false, "只输出将要执行的迁移，不实际执行")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: migrate [flags] up [version] | down [steps] | status | force <version>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *dbName == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var migrations []migrate.Migration
+	if *withMod {
+		migrations = append(migrations, models.Migrations()...)
+	}
+	if *dir != "" {
+		list, err := migrate.LoadFS(os.DirFS(*dir), ".")
+		if err != nil {
+			fatal(err)
+		}
+		migrations = append(migrations, list...)
+	}
+
+	dbmysql.StartUp(dbmysql.MysqlInfo{
+		Address:  *addr,
+		User:     *user,
+		Password: *password,
+		DBName:   *dbName,
+		Logger:   logger.Default.LogMode(logger.Warn),
+	}, 0)
+	defer dbmysql.Close()
+
+	m, err := migrate.New(dbmysql.Client(), migrate.Options{Table: *table, DryRun: *dryRun}, migrations...)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx := context.Background()
+	arg := func() int64 {
+		if flag.NArg() < 2 {
+			return 0
+		}
+		n, err := strconv.ParseInt(flag.Arg(1), 10, 64)
+		if err != nil {
+			fatal(fmt.Errorf("invalid number: %s", flag.Arg(1)))
+		}
+		return n
+	}
+
+	switch flag.Arg(0) {
+	case "up":
+		err = m.Up(ctx, arg())
+	case "down":
+		err = m.Down(ctx, int(arg()))
+	case "force":
+		if flag.NArg() < 2 {
+			fatal(fmt.Errorf("force requires version"))
+		}
+		err = m.Force(ctx, arg())
+	case "status":
+		err = printStatus(ctx, m)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func printStatus(ctx context.Context, m *migrate.Migrator) error {
+	list, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range list {
+		status, at := "pending", ""
+		switch {
+		case s.Dirty:
+			status = "dirty"
+		case s.Missing:
+			status = "missing"
+		case s.Applied:
+			status = "applied"
+		}
+		if s.AppliedAt > 0 {
+			at = time.UnixMilli(s.AppliedAt).Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, status, at)
+	}
+	return w.Flush()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "migrate:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,268 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/caoyuewen/components/common/models"
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Mode 归档方式
+type Mode int
+
+const (
+	ModeTable Mode = iota // 移入按月分表 goods_order_archive_YYYYMM
+	ModeFile              // 导出为按月的 JSONL.gz 文件后删除；导出的订单不在 Query 的查询范围内，需用 ReadFile 读取
+)
+
+// ArchiveTablePrefix 月归档表前缀
+const ArchiveTablePrefix = "goods_order_archive_"
+
+// DefaultStatuses 默认可归档的终态：2 成功 3 失败 4 已过期 (待支付、待审核的订单不归档)
+var DefaultStatuses = []int{2, 3, 4}
+
+// Config 归档配置
+type Config struct {
+	Conn       string        // 命名连接，为空时使用默认实例
+	Retention  time.Duration // 保留期，按 created_at 判断，默认 180 天
+	Statuses   []int         // 可归档的订单状态，默认 DefaultStatuses
+	BatchSize  int           // 每批行数，每批一个事务，默认 500
+	Pause      time.Duration // 批次间隔，降低对主库的压力，默认 100ms
+	MaxBatches int           // 单次运行最多处理的批数，<= 0 不限制
+	Mode       Mode          // 归档方式，默认 ModeTable
+	Dir        string        // ModeFile 的导出目录
+}
+
+func (c *Config) setDefault() {
+	if c.Retention <= 0 {
+		c.Retention = 180 * 24 * time.Hour
+	}
+	if len(c.Statuses) == 0 {
+		c.Statuses = DefaultStatuses
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 500
+	}
+	if c.Pause <= 0 {
+		c.Pause = 100 * time.Millisecond
+	}
+}
+
+// Result 单次运行结果
+type Result struct {
+	Batches int              // 完成的批数
+	Rows    int64            // 归档的订单数
+	Targets map[string]int64 // 归档表名或文件路径 -> 行数
+}
+
+// Archiver 订单归档
+type Archiver struct {
+	cfg Config
+}
+
+// New 创建归档器
+func New(cfg Config) (*Archiver, error) {
+	cfg.setDefault()
+	if cfg.Mode == ModeFile && cfg.Dir == "" {
+		return nil, errors.New("archive: Dir is required for ModeFile")
+	}
+	return &Archiver{cfg: cfg}, nil
+}
+
+// Run 按批归档早于保留期的终态订单，直到没有可归档的订单、达到 MaxBatches 或 ctx 结束
+// 每批在一个事务内完成写入归档与删除，中途失败时已完成的批次不回滚
+func (a *Archiver) Run(ctx context.Context) (Result, error) {
+	res := Result{Targets: map[string]int64{}}
+	cutoff := time.Now().Add(-a.cfg.Retention).Unix()
+
+	if a.cfg.Mode == ModeFile {
+		if err := recoverTemp(a.cfg.Dir); err != nil {
+			return res, err
+		}
+	}
+
+	for a.cfg.MaxBatches <= 0 || res.Batches < a.cfg.MaxBatches {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		n, err := a.runBatch(ctx, cutoff, res.Targets)
+		if err != nil {
+			log.Errorf("[ARCHIVE] batch %d err: %s", res.Batches, err.Error())
+			return res, err
+		}
+		if n == 0 {
+			break
+		}
+		res.Batches++
+		res.Rows += n
+
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-time.After(a.cfg.Pause):
+		}
+	}
+
+	log.Infof("[ARCHIVE] done, batches:%d rows:%d targets:%v", res.Batches, res.Rows, res.Targets)
+	return res, nil
+}
+
+// runBatch 归档一批，返回归档行数
+func (a *Archiver) runBatch(ctx context.Context, cutoff int64, targets map[string]int64) (int64, error) {
+	db := dbmysql.Use(a.cfg.Conn).WithContext(ctx)
+
+	// 1.先不加锁取出候选 ID，按月建好归档表 (DDL 会隐式提交，不能放在事务里)
+	var candidates []models.GoodsOrder
+	err := a.scope(db, cutoff).Select("id", "created_at").
+		Order("created_at, id").Limit(a.cfg.BatchSize).Find(&candidates).Error
+	if err != nil || len(candidates) == 0 {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, v := range candidates {
+		ids = append(ids, v.ID)
+	}
+	if a.cfg.Mode == ModeTable {
+		for month := range groupByMonth(candidates) {
+			if err = ensureTable(db, ArchiveTablePrefix+month); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// 2.事务内加锁重读 (排除期间状态被修改的订单)，写入归档后经 GoodsOrderRepo 删除，触发审计与缓存失效
+	// ModeFile 在事务内只写临时分段，提交后才追加到月文件，回滚时删除
+	var (
+		moved   int64
+		written map[string]int64
+		temps   []tempSegment
+	)
+	repo := models.GoodsOrderRepo.On(a.cfg.Conn)
+	err = dbmysql.WithTxOn(ctx, a.cfg.Conn, func(ctx context.Context) error {
+		tx := dbmysql.GetDBOrTxOn(ctx, a.cfg.Conn)
+
+		var list []models.GoodsOrder
+		err := a.scope(tx, cutoff).Where("id IN ?", ids).
+			Clauses(clause.Locking{Strength: "UPDATE"}).Find(&list).Error
+		if err != nil || len(list) == 0 {
+			return err
+		}
+
+		written, temps, err = a.write(tx, list)
+		if err != nil {
+			return err
+		}
+
+		locked := make([]string, 0, len(list))
+		for _, v := range list {
+			locked = append(locked, v.ID)
+		}
+		if err = repo.DeleteWhereWithDB(tx, models.GoodsOrderFields.ID.In(locked...)); err != nil {
+			return err
+		}
+		var left int64
+		if err = tx.Model(&models.GoodsOrder{}).Where("id IN ?", locked).Count(&left).Error; err != nil {
+			return err
+		}
+		if left != 0 {
+			return fmt.Errorf("archive: %d rows not deleted, expected 0", left)
+		}
+		moved = int64(len(list))
+		return nil
+	})
+	if err != nil {
+		for _, t := range temps {
+			os.Remove(t.path)
+		}
+		return 0, err
+	}
+
+	for _, t := range temps {
+		path, err := commitTemp(t.path)
+		if err != nil {
+			// 订单已删除，临时分段保留到下次运行时由 recoverTemp 追加
+			return 0, fmt.Errorf("archive: append %s: %w", t.path, err)
+		}
+		written[path] += t.rows
+	}
+	for k, v := range written {
+		targets[k] += v
+	}
+	return moved, nil
+}
+
+// scope 可归档订单的条件
+func (a *Archiver) scope(db *gorm.DB, cutoff int64) *gorm.DB {
+	f := models.GoodsOrderFields
+	return db.Model(&models.GoodsOrder{}).Where(dbmysql.And(
+		f.CreatedAt.Lt(cutoff),
+		f.OrderStatus.In(a.cfg.Statuses...),
+	))
+}
+
+// tempSegment ModeFile 事务内写入的临时分段
+type tempSegment struct {
+	path string
+	rows int64
+}
+
+// write 按月写入归档，返回 归档表 -> 行数；ModeFile 返回待提交后追加的临时分段
+func (a *Archiver) write(tx *gorm.DB, list []models.GoodsOrder) (map[string]int64, []tempSegment, error) {
+	written := map[string]int64{}
+	var temps []tempSegment
+	for month, rows := range groupByMonth(list) {
+		if a.cfg.Mode == ModeFile {
+			path, err := writeTemp(a.cfg.Dir, month, rows)
+			if err != nil {
+				return nil, temps, err
+			}
+			temps = append(temps, tempSegment{path: path, rows: int64(len(rows))})
+			continue
+		}
+
+		table := ArchiveTablePrefix + month
+		// 重复执行时跳过已归档的行
+		if err := tx.Table(table).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return nil, temps, err
+		}
+		written[table] += int64(len(rows))
+	}
+	return written, temps, nil
+}
+
+// ==================== 辅助函数 ====================
+
+// MonthTable 订单创建时间 (秒) 对应的归档表名
+func MonthTable(createdAt int64) string {
+	return ArchiveTablePrefix + monthKey(createdAt)
+}
+
+func monthKey(createdAt int64) string {
+	return time.Unix(createdAt, 0).Format("200601")
+}
+
+func groupByMonth(list []models.GoodsOrder) map[string][]models.GoodsOrder {
+	res := map[string][]models.GoodsOrder{}
+	for _, v := range list {
+		k := monthKey(v.CreatedAt)
+		res[k] = append(res[k], v)
+	}
+	return res
+}
+
+// ensureTable 按在线表结构创建归档表
+func ensureTable(db *gorm.DB, table string) error {
+	if db.Migrator().HasTable(table) {
+		return nil
+	}
+	return db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` LIKE `%s`", table, (&models.GoodsOrder{}).TableName())).Error
+}
@@ -0,0 +1,57 @@
+package archive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caoyuewen/components/common/models"
+)
+
+func TestGroupByMonth(t *testing.T) {
+	jan := time.Date(2025, 1, 31, 12, 0, 0, 0, time.Local).Unix()
+	feb := time.Date(2025, 2, 1, 12, 0, 0, 0, time.Local).Unix()
+
+	groups := groupByMonth([]models.GoodsOrder{{ID: "1", CreatedAt: jan}, {ID: "2", CreatedAt: feb}, {ID: "3", CreatedAt: jan}})
+	if len(groups["202501"]) != 2 || len(groups["202502"]) != 1 {
+		t.Fatalf("groups: %v", groups)
+	}
+	if MonthTable(feb) != "goods_order_archive_202502" {
+		t.Fatalf("table: %s", MonthTable(feb))
+	}
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	tmp, err := writeTemp(dir, "202501", []models.GoodsOrder{{ID: "1", Remark: "a"}, {ID: "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := commitTemp(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "goods_order_202501.jsonl.gz") {
+		t.Fatalf("path: %s", path)
+	}
+
+	// 上次运行遗留的分段，包含重复订单
+	if _, err = writeTemp(dir, "202501", []models.GoodsOrder{{ID: "1", Remark: "b"}, {ID: "3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = recoverTemp(dir); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "*"+tempSuffix)); len(left) != 0 {
+		t.Fatalf("temp files left: %v", left)
+	}
+
+	list, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].ID != "1" || list[0].Remark != "b" || list[2].ID != "3" {
+		t.Fatalf("list: %+v", list)
+	}
+}
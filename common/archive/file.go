@@ -0,0 +1,135 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/caoyuewen/components/common/models"
+)
+
+// FileName 月导出文件名
+func FileName(month string) string {
+	return fmt.Sprintf("goods_order_%s.jsonl.gz", month)
+}
+
+// tempSuffix 未追加到月文件的临时分段后缀
+const tempSuffix = ".tmp"
+
+// writeTemp 将一批订单写为月文件旁的临时 gzip 分段，落盘后返回临时文件路径
+// 事务提交后由 commitTemp 追加到月文件，回滚时删除，避免未删除的订单进入导出文件
+func writeTemp(dir, month string, rows []models.GoodsOrder) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(dir, FileName(month)+".*"+tempSuffix)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for i := range rows {
+		if err = enc.Encode(&rows[i]); err != nil {
+			os.Remove(f.Name())
+			return "", err
+		}
+	}
+	if err = zw.Close(); err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// commitTemp 将临时分段追加到月文件 (gzip 支持多分段拼接) 后删除，返回月文件路径
+func commitTemp(tmp string) (string, error) {
+	base := filepath.Base(tmp)
+	i := strings.Index(base, ".jsonl.gz.")
+	if i < 0 || !strings.HasSuffix(base, tempSuffix) {
+		return "", fmt.Errorf("archive: invalid temp file %s", tmp)
+	}
+	path := filepath.Join(filepath.Dir(tmp), base[:i+len(".jsonl.gz")])
+
+	src, err := os.Open(tmp)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err = io.Copy(dst, src); err != nil {
+		return "", err
+	}
+	if err = dst.Sync(); err != nil {
+		return "", err
+	}
+	return path, os.Remove(tmp)
+}
+
+// recoverTemp 追加上次运行遗留的临时分段 (提交后追加前进程退出)
+// 无法区分遗留分段对应的事务是否已提交，未提交的订单之后会再次导出，读取时按 ID 去重
+func recoverTemp(dir string) error {
+	list, err := filepath.Glob(filepath.Join(dir, "goods_order_*.jsonl.gz.*"+tempSuffix))
+	if err != nil {
+		return err
+	}
+	for _, tmp := range list {
+		if _, err = commitTemp(tmp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFile 读取导出文件中的订单，重复的订单只保留最后一条
+func ReadFile(path string) ([]models.GoodsOrder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var (
+		list  []models.GoodsOrder
+		index = map[string]int{}
+		dec   = json.NewDecoder(zr)
+	)
+	for {
+		var v models.GoodsOrder
+		if err = dec.Decode(&v); errors.Is(err, io.EOF) {
+			return list, nil
+		} else if err != nil {
+			return list, err
+		}
+
+		if i, ok := index[v.ID]; ok {
+			list[i] = v
+			continue
+		}
+		index[v.ID] = len(list)
+		list = append(list, v)
+	}
+}
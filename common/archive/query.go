@@ -0,0 +1,116 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/caoyuewen/components/common/models"
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"gorm.io/gorm"
+)
+
+// ==================== 在线 + 归档查询 ====================
+// 供后台按订单号、用户、交易哈希查询，先查在线表，再按月份倒序查归档表；走读库
+// ModeFile 导出的订单不在查询范围内 (只查数据库)，查询已导出文件的订单需用 ReadFile
+
+// Query 订单查询
+type Query struct {
+	Conn string // 命名连接，为空时使用默认实例
+}
+
+// FindOrder 按订单 ID 查询
+func (q Query) FindOrder(ctx context.Context, id string) (models.GoodsOrder, error) {
+	return q.findOne(ctx, models.GoodsOrderFields.ID.Eq(id))
+}
+
+// FindOrderByTxHash 按交易哈希查询
+func (q Query) FindOrderByTxHash(ctx context.Context, txHash string) (models.GoodsOrder, error) {
+	return q.findOne(ctx, models.GoodsOrderFields.TxHash.Eq(txHash))
+}
+
+// FindOrdersByUid 查询用户的订单，按创建时间倒序，最多 limit 条
+func (q Query) FindOrdersByUid(ctx context.Context, uid string, limit int) ([]models.GoodsOrder, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	f := models.GoodsOrderFields
+	order := dbmysql.OrderBy(f.CreatedAt.Desc(), f.ID.Desc())
+
+	var res []models.GoodsOrder
+	err := q.each(ctx, func(db *gorm.DB) (bool, error) {
+		var list []models.GoodsOrder
+		if err := db.Where(f.Uid.Eq(uid)).Order(order).Limit(limit - len(res)).Find(&list).Error; err != nil {
+			return false, err
+		}
+		res = append(res, list...)
+		return len(res) >= limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 归档按创建月份分表，整体仍需排序
+	sort.SliceStable(res, func(i, j int) bool { return res[i].CreatedAt > res[j].CreatedAt })
+	return res, nil
+}
+
+// ArchiveTables 已存在的归档表，按月份倒序
+func (q Query) ArchiveTables(ctx context.Context) ([]string, error) {
+	var tables []string
+	err := dbmysql.UseRead(q.Conn).WithContext(ctx).Raw(
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name LIKE ?",
+		ArchiveTablePrefix+"%",
+	).Scan(&tables).Error
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(tables)
+	slices.Reverse(tables)
+	return tables, nil
+}
+
+// findOne 返回第一条匹配的订单
+func (q Query) findOne(ctx context.Context, cond interface{}) (models.GoodsOrder, error) {
+	var (
+		res   models.GoodsOrder
+		found bool
+	)
+	err := q.each(ctx, func(db *gorm.DB) (bool, error) {
+		err := db.Where(cond).Take(&res).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		found = err == nil
+		return found, err
+	})
+	if err == nil && !found {
+		err = gorm.ErrRecordNotFound
+	}
+	return res, err
+}
+
+// each 依次在在线表与各归档表上执行 fn，fn 返回 true 时停止
+func (q Query) each(ctx context.Context, fn func(db *gorm.DB) (bool, error)) error {
+	db := dbmysql.UseRead(q.Conn).WithContext(ctx)
+	if dbmysql.IsPrimaryPinned(ctx) {
+		db = dbmysql.Use(q.Conn).WithContext(ctx)
+	}
+
+	if done, err := fn(db.Model(&models.GoodsOrder{})); err != nil || done {
+		return err
+	}
+
+	tables, err := q.ArchiveTables(ctx)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if done, err := fn(db.Table(table)); err != nil || done {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+package caches
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"github.com/caoyuewen/components/dbs/dbredis"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound 数据不存在，loader 返回该错误 (或 gorm.ErrRecordNotFound) 时写入负缓存
+var ErrNotFound = errors.New("caches: not found")
+
+const (
+	RedisKeyCachePrefix    = "cache:"           // 缓存 key 前缀，完整 key 为 cache:{Namespace}:{key}
+	RedisChannelInvalidate = "cache:invalidate" // 失效广播频道，用于清理其他实例的 L1
+	nullValue              = "\x00"             // 负缓存占位，不可能是合法 JSON
+	invalidateAll          = "*"                // 广播整体失效
+	invalidateMsgSeparator = "\n"
+)
+
+// CacheOptions 缓存配置
+type CacheOptions struct {
+	Namespace       string                   // 命名空间，必填，如 "goods_order"
+	TTL             time.Duration            // 缓存时长，默认 10m
+	Jitter          float64                  // TTL 随机延长比例，避免同时过期，默认 0.1
+	NegativeTTL     time.Duration            // 不存在时的负缓存时长，默认 1m，< 0 不缓存
+	L1Size          int                      // 进程内缓存条目上限 (LRU)，0 不启用
+	L1TTL           time.Duration            // 进程内缓存时长，默认 10s
+	InvalidateDelay time.Duration            // 失效后延迟再删一次 (延迟双删)，默认 1s，< 0 不延迟删除
+	KeysForID       func(id string) []string // 仓库写入事件的主键对应的缓存 key，默认 key 即主键
+	IsNotFound      func(err error) bool     // 判断 loader 错误是否为不存在，默认 ErrNotFound / gorm.ErrRecordNotFound
+}
+
+func (o *CacheOptions) setDefault() {
+	if o.TTL <= 0 {
+		o.TTL = 10 * time.Minute
+	}
+	if o.Jitter <= 0 {
+		o.Jitter = 0.1
+	}
+	if o.NegativeTTL == 0 {
+		o.NegativeTTL = time.Minute
+	}
+	if o.L1TTL <= 0 {
+		o.L1TTL = 10 * time.Second
+	}
+	if o.InvalidateDelay == 0 {
+		o.InvalidateDelay = time.Second
+	}
+	if o.KeysForID == nil {
+		o.KeysForID = func(id string) []string { return []string{id} }
+	}
+	if o.IsNotFound == nil {
+		o.IsNotFound = func(err error) bool {
+			return errors.Is(err, ErrNotFound) || errors.Is(err, gorm.ErrRecordNotFound)
+		}
+	}
+}
+
+// Cache 基于 Redis 的旁路缓存 (cache-aside)，值以 JSON 存储
+// 并发未命中时同一 key 只调用一次 loader；可选进程内 L1，失效时通过 Redis Pub/Sub 通知其他实例
+type Cache[T any] struct {
+	opts    CacheOptions
+	group   singleflight.Group
+	l1      *lru[T]
+	subOnce sync.Once
+}
+
+// NewCache 创建缓存，可在包级变量中创建，首次使用时才访问 Redis
+func NewCache[T any](opts CacheOptions) *Cache[T] {
+	if opts.Namespace == "" {
+		panic("caches: Namespace is required")
+	}
+	opts.setDefault()
+
+	c := &Cache[T]{opts: opts}
+	if opts.L1Size > 0 {
+		c.l1 = newLRU[T](opts.L1Size)
+	}
+	return c
+}
+
+// ==================== 读写 ====================
+
+type loadResult[T any] struct {
+	val T
+	err error
+}
+
+// Get 读取缓存，未命中时调用 loader 并回写；不存在时返回 ErrNotFound
+// loader 必须读主库 (如 dbmysql.WithPrimary)：写后失效时从库可能仍是旧数据，读从库会把旧值重新缓存 TTL 之久
+// loader 在并发调用间共享，使用首个调用方 ctx 的值但不受其取消影响
+func (c *Cache[T]) Get(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	c.subscribe()
+	if v, neg, ok := c.l1.get(key); ok {
+		if neg {
+			return v, ErrNotFound
+		}
+		return v, nil
+	}
+
+	loadCtx := context.WithoutCancel(ctx)
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		v, err := c.load(loadCtx, key, loader)
+		return loadResult[T]{val: v, err: err}, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case res := <-ch:
+		r := res.Val.(loadResult[T])
+		return r.val, r.err
+	}
+}
+
+// load 先读 Redis，未命中再调用 loader
+func (c *Cache[T]) load(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	var v T
+	raw, err := dbredis.Client().Get(ctx, c.redisKey(key)).Result()
+	switch {
+	case err == nil && raw == nullValue:
+		c.l1.set(key, v, true, c.opts.L1TTL)
+		return v, ErrNotFound
+	case err == nil:
+		if err = json.Unmarshal([]byte(raw), &v); err == nil {
+			c.l1.set(key, v, false, c.opts.L1TTL)
+			return v, nil
+		}
+		log.Errorf("[CACHE] %s decode %s err: %v", c.opts.Namespace, key, err)
+	case !errors.Is(err, redis.Nil):
+		// Redis 不可用时降级为直接回源
+		log.Errorf("[CACHE] %s get %s err: %v", c.opts.Namespace, key, err)
+	}
+
+	v, err = loader(ctx)
+	if err != nil {
+		if c.opts.IsNotFound(err) {
+			if c.opts.NegativeTTL > 0 {
+				_ = c.setRaw(ctx, key, nullValue, c.opts.NegativeTTL)
+				c.l1.set(key, v, true, min(c.opts.L1TTL, c.opts.NegativeTTL))
+			}
+			return v, ErrNotFound
+		}
+		return v, err
+	}
+
+	_ = c.Set(ctx, key, v)
+	return v, nil
+}
+
+// Set 写入缓存
+func (c *Cache[T]) Set(ctx context.Context, key string, v T) error {
+	c.subscribe()
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.l1.set(key, v, false, c.opts.L1TTL)
+	return c.setRaw(ctx, key, string(data), c.jitter(c.opts.TTL))
+}
+
+func (c *Cache[T]) setRaw(ctx context.Context, key, val string, ttl time.Duration) error {
+	err := dbredis.Client().Set(ctx, c.redisKey(key), val, ttl).Err()
+	if err != nil {
+		log.Errorf("[CACHE] %s set %s err: %v", c.opts.Namespace, key, err)
+	}
+	return err
+}
+
+// ==================== 失效 ====================
+
+// Delete 删除缓存，InvalidateDelay 后再删一次，避免并发读把旧值写回
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	err := c.delete(ctx, keys)
+	if c.opts.InvalidateDelay > 0 {
+		time.AfterFunc(c.opts.InvalidateDelay, func() {
+			_ = c.delete(context.Background(), keys)
+		})
+	}
+	return err
+}
+
+func (c *Cache[T]) delete(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		c.l1.del(key)
+	}
+
+	rdb := dbredis.Client()
+	// 逐个删除，集群模式下不同 key 可能在不同槽位
+	_, err := rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, key := range keys {
+			p.Unlink(ctx, c.redisKey(key))
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[CACHE] %s delete err: %v", c.opts.Namespace, err)
+		return err
+	}
+	if c.l1 != nil {
+		c.publish(ctx, keys...)
+	}
+	return nil
+}
+
+// Clear 删除命名空间下的全部缓存
+func (c *Cache[T]) Clear(ctx context.Context) error {
+	c.l1.clear()
+	match := c.redisKey("*")
+
+	var err error
+	if cc, ok := dbredis.Client().(*redis.ClusterClient); ok {
+		err = cc.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanUnlink(ctx, node, match)
+		})
+	} else {
+		err = scanUnlink(ctx, dbredis.Client(), match)
+	}
+	if err != nil {
+		log.Errorf("[CACHE] %s clear err: %v", c.opts.Namespace, err)
+		return err
+	}
+	if c.l1 != nil {
+		c.publish(ctx, invalidateAll)
+	}
+	return nil
+}
+
+// WriteHook 仓库写入回调，按主键删除缓存，主键未知时清空命名空间；Redis 未初始化时忽略
+// 在 dbmysql.WithTx 等上下文事务中写入时于提交后失效；直接传入事务写入时在提交前失效，仅靠延迟双删兜底
+//
+//	var OrderCache = caches.NewCache[Order](caches.CacheOptions{Namespace: "order"})
+//	var OrderRepo = dbmysql.NewBaseRepository[Order]("id").OnWrite(OrderCache.WriteHook())
+func (c *Cache[T]) WriteHook() dbmysql.WriteHook {
+	return func(ctx context.Context, e dbmysql.WriteEvent) {
+		if !dbredis.IsInitialized() {
+			return
+		}
+		if e.IDs == nil {
+			_ = c.Clear(ctx)
+			return
+		}
+		var keys []string
+		for _, id := range e.IDs {
+			keys = append(keys, c.opts.KeysForID(id)...)
+		}
+		_ = c.Delete(ctx, keys...)
+	}
+}
+
+// ==================== L1 失效广播 ====================
+
+func (c *Cache[T]) publish(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		msg := c.opts.Namespace + invalidateMsgSeparator + key
+		if err := dbredis.Client().Publish(ctx, RedisChannelInvalidate, msg).Err(); err != nil {
+			log.Errorf("[CACHE] %s publish invalidate err: %v", c.opts.Namespace, err)
+			return
+		}
+	}
+}
+
+// subscribe 启用 L1 时订阅失效广播，连接断开后重新订阅
+func (c *Cache[T]) subscribe() {
+	if c.l1 == nil {
+		return
+	}
+	c.subOnce.Do(func() {
+		go func() {
+			for {
+				if !dbredis.IsInitialized() {
+					time.Sleep(time.Second)
+					continue
+				}
+				sub := dbredis.Client().Subscribe(context.Background(), RedisChannelInvalidate)
+				for msg := range sub.Channel() {
+					ns, key, ok := strings.Cut(msg.Payload, invalidateMsgSeparator)
+					if !ok || ns != c.opts.Namespace {
+						continue
+					}
+					if key == invalidateAll {
+						c.l1.clear()
+					} else {
+						c.l1.del(key)
+					}
+				}
+				_ = sub.Close()
+				// 订阅中断期间可能漏掉广播，清空 L1 保守处理
+				c.l1.clear()
+				time.Sleep(time.Second)
+			}
+		}()
+	})
+}
+
+// ==================== 辅助函数 ====================
+
+func (c *Cache[T]) redisKey(key string) string {
+	return RedisKeyCachePrefix + c.opts.Namespace + ":" + key
+}
+
+// jitter TTL 随机延长 [0, Jitter) 比例
+func (c *Cache[T]) jitter(ttl time.Duration) time.Duration {
+	return ttl + time.Duration(rand.Float64()*c.opts.Jitter*float64(ttl))
+}
+
+// scanUnlink 在单个节点上按模式扫描删除
+func scanUnlink(ctx context.Context, rdb redis.UniversalClient, match string) error {
+	iter := rdb.Scan(ctx, 0, match, 500).Iterator()
+	var keys []string
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		_, err := rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+			for _, k := range keys {
+				p.Unlink(ctx, k)
+			}
+			return nil
+		})
+		keys = keys[:0]
+		return err
+	}
+
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= 500 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// ==================== 进程内 LRU ====================
+
+// lru 带过期时间的 LRU，nil 表示未启用，所有方法可在 nil 上调用
+type lru[T any] struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry[T any] struct {
+	key    string
+	val    T
+	neg    bool
+	expire time.Time
+}
+
+func newLRU[T any](size int) *lru[T] {
+	return &lru[T]{size: size, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *lru[T]) get(key string) (val T, neg bool, ok bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, hit := l.items[key]
+	if !hit {
+		return
+	}
+	e := el.Value.(*lruEntry[T])
+	if time.Now().After(e.expire) {
+		l.ll.Remove(el)
+		delete(l.items, key)
+		return
+	}
+	l.ll.MoveToFront(el)
+	return e.val, e.neg, true
+}
+
+func (l *lru[T]) set(key string, val T, neg bool, ttl time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := &lruEntry[T]{key: key, val: val, neg: neg, expire: time.Now().Add(ttl)}
+	if el, ok := l.items[key]; ok {
+		el.Value = e
+		l.ll.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.ll.PushFront(e)
+	for l.ll.Len() > l.size {
+		last := l.ll.Back()
+		l.ll.Remove(last)
+		delete(l.items, last.Value.(*lruEntry[T]).key)
+	}
+}
+
+func (l *lru[T]) del(key string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.items[key]; ok {
+		l.ll.Remove(el)
+		delete(l.items, key)
+	}
+}
+
+func (l *lru[T]) clear() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ll.Init()
+	clear(l.items)
+}
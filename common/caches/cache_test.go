@@ -0,0 +1,55 @@
+package caches
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	l := newLRU[int](2)
+	l.set("a", 1, false, time.Minute)
+	l.set("b", 2, false, time.Minute)
+	if _, _, ok := l.get("a"); !ok {
+		t.Fatal("a should be cached")
+	}
+	l.set("c", 3, false, time.Minute) // 淘汰最久未使用的 b
+	if _, _, ok := l.get("b"); ok {
+		t.Fatal("b should be evicted")
+	}
+	if v, _, ok := l.get("a"); !ok || v != 1 {
+		t.Fatalf("a = %d, %v", v, ok)
+	}
+
+	l.set("n", 0, true, time.Minute)
+	if _, neg, ok := l.get("n"); !ok || !neg {
+		t.Fatal("negative entry expected")
+	}
+
+	l.set("e", 5, false, -time.Second)
+	if _, _, ok := l.get("e"); ok {
+		t.Fatal("expired entry returned")
+	}
+
+	l.clear()
+	if _, _, ok := l.get("a"); ok {
+		t.Fatal("cleared entry returned")
+	}
+
+	var disabled *lru[int]
+	disabled.set("a", 1, false, time.Minute)
+	if _, _, ok := disabled.get("a"); ok {
+		t.Fatal("nil lru should never hit")
+	}
+}
+
+func TestCacheJitter(t *testing.T) {
+	c := NewCache[int](CacheOptions{Namespace: "t", TTL: time.Minute, Jitter: 0.5})
+	for i := 0; i < 100; i++ {
+		if d := c.jitter(time.Minute); d < time.Minute || d >= 90*time.Second {
+			t.Fatalf("jitter out of range: %v", d)
+		}
+	}
+	if c.redisKey("1") != "cache:t:1" {
+		t.Fatalf("key = %s", c.redisKey("1"))
+	}
+}
@@ -3,8 +3,12 @@ package caches
 import (
 	"context"
 	"errors"
+	"sort"
+	"strconv"
+	"sync"
 
 	"github.com/caoyuewen/components/dbs/dbredis"
+	"github.com/caoyuewen/components/util"
 	"github.com/redis/go-redis/v9"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
@@ -12,80 +16,172 @@ import (
 
 // Redis Key
 const (
-	RedisKeyUsdtAddressPool  = "u_pool" // 地址池 (List)
-	RedisKeyUsdtAddressOrder = "u:"     // 地址订单占位 (ZSet) + address
+	RedisKeyUsdtAddressPool   = "u_pool"   // 地址池 (List)
+	RedisKeyUsdtAddressWeight = "u_pool_w" // 地址权重 (Hash) address -> priority
+	RedisKeyUsdtAddressBlack  = "u_black"  // 地址黑名单 (Set)
+	RedisKeyUsdtAddressOrder  = "u:"       // 地址订单占位 (ZSet) + address
 )
 
-var UsdtAddress usdtAddress
+var (
+	ErrPoolEmpty          = errors.New("address pool is empty")
+	ErrNoAvailableAddress = errors.New("no available address")
+)
+
+var UsdtAddress = usdtAddress{listeners: map[string]PoolChangeFunc{}}
 
-type usdtAddress struct{}
+// PoolChangeFunc 地址池变更回调，参数为变更后的全部地址
+type PoolChangeFunc func(addrs []string) error
+
+type usdtAddress struct {
+	mu        sync.RWMutex
+	listeners map[string]PoolChangeFunc
+}
 
 type UsdtAddressOrderInfo struct {
 	OrderId string
 	Amount  string
 }
 
-// FlushPool 刷新地址池到 Redis
+// UsdtPoolAddress 地址池中的地址及其权重
+type UsdtPoolAddress struct {
+	Address  string
+	Priority int // 权重 <= 0 时按 1 处理
+}
+
+// OnPoolChange 注册地址池变更回调 (同名覆盖)，FlushPool 发现地址集合变化时调用
+func (u *usdtAddress) OnPoolChange(name string, fn PoolChangeFunc) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.listeners[name] = fn
+}
+
+// FlushPool 刷新地址池到 Redis (所有地址权重相同)
 func (u *usdtAddress) FlushPool(addrList []string) error {
+	list := make([]UsdtPoolAddress, len(addrList))
+	for i, addr := range addrList {
+		list[i] = UsdtPoolAddress{Address: addr, Priority: 1}
+	}
+	return u.FlushPoolWeighted(list)
+}
+
+// FlushPoolWeighted 按权重刷新地址池到 Redis，地址集合发生变化时通知回调
+func (u *usdtAddress) FlushPoolWeighted(list []UsdtPoolAddress) error {
 	ctx := context.Background()
 	rdb := dbredis.Client()
 
-	// 清空 Redis 缓存并写入新数据 (List)
+	old, err := rdb.LRange(ctx, RedisKeyUsdtAddressPool, 0, -1).Result()
+	if err != nil {
+		log.Error("UsdtAddressFlushAll err:", err)
+		return err
+	}
+
+	// 清空 Redis 缓存并写入新数据 (List + Hash)
 	pipe := rdb.TxPipeline()
-	pipe.Del(ctx, RedisKeyUsdtAddressPool)
+	pipe.Del(ctx, RedisKeyUsdtAddressPool, RedisKeyUsdtAddressWeight)
 
-	if len(addrList) == 0 {
+	addrList := make([]string, 0, len(list))
+	if len(list) == 0 {
 		log.Warn("Warning: UsdtAddress pool is empty")
-		_, err := pipe.Exec(ctx)
-		if err != nil {
-			log.Info("UsdtAddressFlushAll err:", err)
-			return err
+	} else {
+		// 转换为 interface{} 切片
+		args := make([]interface{}, 0, len(list))
+		weights := make(map[string]interface{}, len(list))
+		for _, v := range list {
+			args = append(args, v.Address)
+			weights[v.Address] = v.Priority
+			addrList = append(addrList, v.Address)
 		}
-		return nil
-	}
-
-	// 转换为 interface{} 切片
-	args := make([]interface{}, len(addrList))
-	for i, addr := range addrList {
-		args[i] = addr
+		pipe.RPush(ctx, RedisKeyUsdtAddressPool, args...)
+		pipe.HSet(ctx, RedisKeyUsdtAddressWeight, weights)
 	}
-	pipe.RPush(ctx, RedisKeyUsdtAddressPool, args...)
 
-	_, err := pipe.Exec(ctx)
-	if err != nil {
+	if _, err = pipe.Exec(ctx); err != nil {
 		log.Error("UsdtAddressFlushAll err:", err)
 		return err
 	}
-	log.Info("UsdtAddressFlushAll success, count:", len(addrList))
+	log.Info("UsdtAddressFlushAll success, count:", len(list))
+
+	if !sameAddrSet(old, addrList) {
+		u.notifyPoolChange(addrList)
+	}
 
 	return nil
 }
 
+// notifyPoolChange 依次调用地址池变更回调，单个回调失败不影响其他回调
+// 回调可能发起网络请求，复制后在锁外执行
+func (u *usdtAddress) notifyPoolChange(addrs []string) {
+	u.mu.RLock()
+	listeners := make(map[string]PoolChangeFunc, len(u.listeners))
+	for name, fn := range u.listeners {
+		listeners[name] = fn
+	}
+	u.mu.RUnlock()
+
+	for name, fn := range listeners {
+		if err := fn(addrs); err != nil {
+			log.Errorf("UsdtAddress pool change listener %s err: %v", name, err)
+		}
+	}
+}
+
+// sameAddrSet 判断两个地址集合是否一致 (忽略顺序)
+func sameAddrSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string(nil), a...)
+	y := append([]string(nil), b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // PoolCount 获取缓存中的地址总条数
 func (u *usdtAddress) PoolCount() (int64, error) {
 	ctx := context.Background()
 	return dbredis.Client().LLen(ctx, RedisKeyUsdtAddressPool).Result()
 }
 
-// Pop 从地址池中分配一个可用地址 (检查金额冲突)
+// Pop 从地址池中按权重分配一个可用地址 (检查金额冲突, 跳过黑名单)
 func (u *usdtAddress) Pop(amount decimal.Decimal) (string, error) {
 
 	ctx := context.Background()
 	rdb := dbredis.Client()
 
-	count, err := u.PoolCount()
+	addrs, err := rdb.LRange(ctx, RedisKeyUsdtAddressPool, 0, -1).Result()
 	if err != nil {
 		return "", err
 	}
+	if len(addrs) == 0 {
+		return "", ErrPoolEmpty
+	}
 
-	for i := 0; i < int(count); i++ {
-		// 从地址池循环取一个地址 (RPopLPush 循环)
-		addr, err := rdb.RPopLPush(ctx, RedisKeyUsdtAddressPool, RedisKeyUsdtAddressPool).Result()
-		if errors.Is(err, redis.Nil) {
-			return "", errors.New("address pool is empty")
-		} else if err != nil {
-			return "", err
+	weights, err := rdb.HGetAll(ctx, RedisKeyUsdtAddressWeight).Result()
+	if err != nil {
+		return "", err
+	}
+
+	black, err := rdb.SMembers(ctx, RedisKeyUsdtAddressBlack).Result()
+	if err != nil {
+		return "", err
+	}
+
+	pool := make([]UsdtPoolAddress, 0, len(addrs))
+	for _, addr := range addrs {
+		if util.Include(black, addr) {
+			continue
 		}
+		priority, _ := strconv.Atoi(weights[addr])
+		pool = append(pool, UsdtPoolAddress{Address: addr, Priority: priority})
+	}
+
+	for _, addr := range weightedOrder(pool) {
 
 		// 针对当前地址，构造订单 ZSet key
 		key := RedisKeyUsdtAddressOrder + addr
@@ -108,7 +204,48 @@ func (u *usdtAddress) Pop(amount decimal.Decimal) (string, error) {
 		return addr, nil
 	}
 
-	return "", errors.New("no available address")
+	return "", ErrNoAvailableAddress
+}
+
+// weightedOrder 按权重随机排列地址 (不放回抽样)，权重越高越靠前的概率越大
+func weightedOrder(pool []UsdtPoolAddress) []string {
+	rest := append([]UsdtPoolAddress(nil), pool...)
+	res := make([]string, 0, len(rest))
+	for len(rest) > 0 {
+		idx := util.WeightedRandomFunc(rest, func(v UsdtPoolAddress) int {
+			if v.Priority <= 0 {
+				return 1
+			}
+			return v.Priority
+		})
+		res = append(res, rest[idx].Address)
+		rest = append(rest[:idx], rest[idx+1:]...)
+	}
+	return res
+}
+
+// PendingCount 地址当前的订单占位数量
+func (u *usdtAddress) PendingCount(addr string) (int64, error) {
+	ctx := context.Background()
+	return dbredis.Client().ZCard(ctx, RedisKeyUsdtAddressOrder+addr).Result()
+}
+
+// Blacklist 将地址加入黑名单，Pop 不再分配该地址
+func (u *usdtAddress) Blacklist(addr string) error {
+	ctx := context.Background()
+	return dbredis.Client().SAdd(ctx, RedisKeyUsdtAddressBlack, addr).Err()
+}
+
+// Unblacklist 将地址移出黑名单
+func (u *usdtAddress) Unblacklist(addr string) error {
+	ctx := context.Background()
+	return dbredis.Client().SRem(ctx, RedisKeyUsdtAddressBlack, addr).Err()
+}
+
+// IsBlacklisted 地址是否在黑名单中
+func (u *usdtAddress) IsBlacklisted(addr string) (bool, error) {
+	ctx := context.Background()
+	return dbredis.Client().SIsMember(ctx, RedisKeyUsdtAddressBlack, addr).Result()
 }
 
 // SetOrder 设置地址订单占位
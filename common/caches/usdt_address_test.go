@@ -0,0 +1,43 @@
+package caches
+
+import (
+	"testing"
+)
+
+func TestWeightedOrder(t *testing.T) {
+
+	pool := []UsdtPoolAddress{
+		{Address: "A", Priority: 100},
+		{Address: "B", Priority: 1},
+		{Address: "C", Priority: 0},
+	}
+
+	first := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		order := weightedOrder(pool)
+		if len(order) != len(pool) {
+			t.Fatalf("order len = %d, want %d", len(order), len(pool))
+		}
+		if !sameAddrSet(order, []string{"A", "B", "C"}) {
+			t.Fatalf("order %v lost addresses", order)
+		}
+		first[order[0]]++
+	}
+
+	if first["A"] < 900 {
+		t.Fatalf("high priority address picked first %d/1000 times", first["A"])
+	}
+}
+
+func TestSameAddrSet(t *testing.T) {
+
+	if !sameAddrSet([]string{"a", "b"}, []string{"b", "a"}) {
+		t.Fatal("same set in different order should be equal")
+	}
+	if sameAddrSet([]string{"a", "b"}, []string{"a", "c"}) {
+		t.Fatal("different sets should not be equal")
+	}
+	if sameAddrSet([]string{"a"}, []string{"a", "a"}) {
+		t.Fatal("different lengths should not be equal")
+	}
+}
@@ -1,15 +1,26 @@
 package models
 
 import (
+	"context"
+	"time"
+
+	"github.com/caoyuewen/components/common/caches"
 	"github.com/caoyuewen/components/dbs/dbmysql"
 )
 
-var GoodsOrderRepo = dbmysql.NewBaseRepository[GoodsOrder]("id")
+// GoodsOrderCache 按订单 ID 缓存，GoodsOrderRepo 写入后自动失效
+var GoodsOrderCache = caches.NewCache[GoodsOrder](caches.CacheOptions{Namespace: "goods_order", TTL: 5 * time.Minute})
+
+var GoodsOrderRepo = dbmysql.NewBaseRepository[GoodsOrder]("id").OnWrite(GoodsOrderCache.WriteHook())
 
 const (
 	OrderExpiredTime = 15 // 单位分钟
 )
 
+const (
+	OrderStatusSuccess = 2 // 支付成功，与 pay.OrderStatusSuccess 相同 (pay 依赖 models，状态值在此定义)
+)
+
 type GoodsOrder struct {
 	ID              string `gorm:"primaryKey;not null" json:"id"`                    // 订单 ID / 订单号
 	GoodsId         string `gorm:"not null;index" json:"goods_id"`                   // 商品 ID / VIP 套餐 ID
@@ -24,6 +35,7 @@ type GoodsOrder struct {
 	ExternalOrderId string `gorm:"type:varchar(100);index" json:"external_order_id"` // 三方渠道订单号
 	OrderStatus     int    `gorm:"type:tinyint;not null;index" json:"order_status"`  // 订单状态
 	ExternalStatus  string `gorm:"type:varchar(50);" json:"external_status"`         // 三方返回的订单状态
+	ParentId        string `gorm:"type:varchar(64);index" json:"parent_id"`          // 补款订单对应的原订单 ID
 
 	// USDT 支付相关字段
 	FromAddress string `gorm:"type:varchar(100)" json:"from_address"`   // 付款方地址
@@ -37,6 +49,8 @@ type GoodsOrder struct {
 	CreatedAt  int64  `gorm:"type:BIGINT;not null" json:"created_at"`
 	UpdatedAt  int64  `gorm:"type:BIGINT;not null" json:"updated_at"`
 
+	Version dbmysql.Version `gorm:"not null;default:0" json:"version"` // 乐观锁版本号
+
 	// 返回给前端的字段非数据库字段
 	OrderStatusName string `gorm:"-" json:"order_status_name"` // 订单状态
 	PayTypeName     string `gorm:"-" json:"pay_type_name"`     // 支付方式
@@ -45,3 +59,11 @@ type GoodsOrder struct {
 }
 
 func (*GoodsOrder) TableName() string { return "goods_order" }
+
+// FindGoodsOrderCached 按 ID 查询订单，优先读缓存；不存在时返回 caches.ErrNotFound
+// 回源强制读主库，避免写后失效窗口内从库的旧数据被重新写回缓存
+func FindGoodsOrderCached(ctx context.Context, id string) (GoodsOrder, error) {
+	return GoodsOrderCache.Get(ctx, id, func(ctx context.Context) (GoodsOrder, error) {
+		return GoodsOrderRepo.FindByIDCtx(dbmysql.WithPrimary(ctx), id)
+	})
+}
@@ -0,0 +1,63 @@
+// Code generated by fieldgen; DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/caoyuewen/components/dbs/dbmysql"
+)
+
+// GoodsOrderFields GoodsOrder 列字段
+var GoodsOrderFields = struct {
+	ID              dbmysql.Field[string]
+	GoodsId         dbmysql.Field[string]
+	GoodsTitle      dbmysql.Field[string]
+	Uid             dbmysql.Field[string]
+	UserEmail       dbmysql.Field[string]
+	ChannelId       dbmysql.Field[string]
+	ChannelName     dbmysql.Field[string]
+	PayType         dbmysql.Field[int]
+	Amount          dbmysql.Field[string]
+	RealAmount      dbmysql.Field[string]
+	ExternalOrderId dbmysql.Field[string]
+	OrderStatus     dbmysql.Field[int]
+	ExternalStatus  dbmysql.Field[string]
+	ParentId        dbmysql.Field[string]
+	FromAddress     dbmysql.Field[string]
+	ToAddress       dbmysql.Field[string]
+	TxHash          dbmysql.Field[string]
+	ExpireTime      dbmysql.Field[int64]
+	PaidAt          dbmysql.Field[int64]
+	FailReason      dbmysql.Field[string]
+	Remark          dbmysql.Field[string]
+	CreatedAt       dbmysql.Field[int64]
+	UpdatedAt       dbmysql.Field[int64]
+	Version         dbmysql.Field[dbmysql.Version]
+}{
+	ID:              dbmysql.NewField[string]("id"),
+	GoodsId:         dbmysql.NewField[string]("goods_id"),
+	GoodsTitle:      dbmysql.NewField[string]("goods_title"),
+	Uid:             dbmysql.NewField[string]("uid"),
+	UserEmail:       dbmysql.NewField[string]("user_email"),
+	ChannelId:       dbmysql.NewField[string]("channel_id"),
+	ChannelName:     dbmysql.NewField[string]("channel_name"),
+	PayType:         dbmysql.NewField[int]("pay_type"),
+	Amount:          dbmysql.NewField[string]("amount"),
+	RealAmount:      dbmysql.NewField[string]("real_amount"),
+	ExternalOrderId: dbmysql.NewField[string]("external_order_id"),
+	OrderStatus:     dbmysql.NewField[int]("order_status"),
+	ExternalStatus:  dbmysql.NewField[string]("external_status"),
+	ParentId:        dbmysql.NewField[string]("parent_id"),
+	FromAddress:     dbmysql.NewField[string]("from_address"),
+	ToAddress:       dbmysql.NewField[string]("to_address"),
+	TxHash:          dbmysql.NewField[string]("tx_hash"),
+	ExpireTime:      dbmysql.NewField[int64]("expire_time"),
+	PaidAt:          dbmysql.NewField[int64]("paid_at"),
+	FailReason:      dbmysql.NewField[string]("fail_reason"),
+	Remark:          dbmysql.NewField[string]("remark"),
+	CreatedAt:       dbmysql.NewField[int64]("created_at"),
+	UpdatedAt:       dbmysql.NewField[int64]("updated_at"),
+	Version:         dbmysql.NewField[dbmysql.Version]("version"),
+}
+
+// GoodsOrderColumns GoodsOrder 全部列名，可作为 dbmysql.SafeOrder 的白名单
+var GoodsOrderColumns = []string{"id", "goods_id", "goods_title", "uid", "user_email", "channel_id", "channel_name", "pay_type", "amount", "real_amount", "external_order_id", "order_status", "external_status", "parent_id", "from_address", "to_address", "tx_hash", "expire_time", "paid_at", "fail_reason", "remark", "created_at", "updated_at", "version"}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"github.com/caoyuewen/components/dbs/dbmysql/migrate"
+	"gorm.io/gorm"
+)
+
+// Migrations 本包模型的版本迁移，新增字段或索引时在末尾追加版本，不要修改已发布的版本
+func Migrations() []migrate.Migration {
+	return []migrate.Migration{
+		{
+			Version: 1,
+			Name:    "init_payment_tables",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&GoodsOrder{}, &UsdtAddress{}, &UsdtSweep{})
+			},
+		},
+		{
+			Version: 2,
+			Name:    "goods_order_version",
+			// 版本 1 按当前模型建表，新库已包含该列
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasColumn(&GoodsOrder{}, "Version") {
+					return nil
+				}
+				return tx.Migrator().AddColumn(&GoodsOrder{}, "Version")
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&GoodsOrder{}, "Version")
+			},
+		},
+		{
+			Version: 3,
+			Name:    "audit_log",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&dbmysql.AuditLog{})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&dbmysql.AuditLog{})
+			},
+		},
+	}
+}
@@ -21,13 +21,19 @@ func ValidateTRC20Address(addr string) error {
 
 var UsdtAddressRepo = dbmysql.NewBaseRepository[UsdtAddress]("id")
 
+const (
+	UsdtAddressActive   = 1 // 启用
+	UsdtAddressInactive = 2 // 停用
+)
+
 // UsdtAddress 充值地址
 type UsdtAddress struct {
 	ID            string `json:"id" gorm:"primaryKey;type:varchar(64)"`
 	Address       string `json:"address" gorm:"type:varchar(100);uniqueIndex;not null"`
 	IsActive      int    `json:"is_active" gorm:"column:is_active;type:tinyint;default:1;not null"` // 1 启用 2 停用
 	Priority      int    `json:"priority"  gorm:"type:int;default:1;not null"`
-	TotalReceived string `json:"total_received" gorm:"-"` // 累计收款 (计算字段，不存数据库)
+	DisableReason string `json:"disable_reason" gorm:"type:varchar(255)"` // 停用原因
+	TotalReceived string `json:"total_received" gorm:"-"`                 // 累计收款 (计算字段，不存数据库)
 	CreatedAt     int64  `json:"created_at" gorm:"autoCreateTime:milli"`
 	UpdatedAt     int64  `json:"updated_at" gorm:"autoUpdateTime:milli"`
 }
@@ -40,8 +46,8 @@ func UsdtAddressTotalReceived(address string) string {
 	var total struct {
 		Sum float64
 	}
-	// 从订单表统计已支付的金额
-	dbmysql.Client().Model(&GoodsOrder{}).
+	// 从订单表统计已支付的金额 (报表查询走读库)
+	dbmysql.ReadClient().Model(&GoodsOrder{}).
 		Select("COALESCE(SUM(CAST(real_amount AS DECIMAL(20,8))), 0) as sum").
 		Where("to_address = ? AND order_status = 2", address). // 2 = 已支付
 		Scan(&total)
@@ -0,0 +1,29 @@
+// Code generated by fieldgen; DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/caoyuewen/components/dbs/dbmysql"
+)
+
+// UsdtAddressFields UsdtAddress 列字段
+var UsdtAddressFields = struct {
+	ID            dbmysql.Field[string]
+	Address       dbmysql.Field[string]
+	IsActive      dbmysql.Field[int]
+	Priority      dbmysql.Field[int]
+	DisableReason dbmysql.Field[string]
+	CreatedAt     dbmysql.Field[int64]
+	UpdatedAt     dbmysql.Field[int64]
+}{
+	ID:            dbmysql.NewField[string]("id"),
+	Address:       dbmysql.NewField[string]("address"),
+	IsActive:      dbmysql.NewField[int]("is_active"),
+	Priority:      dbmysql.NewField[int]("priority"),
+	DisableReason: dbmysql.NewField[string]("disable_reason"),
+	CreatedAt:     dbmysql.NewField[int64]("created_at"),
+	UpdatedAt:     dbmysql.NewField[int64]("updated_at"),
+}
+
+// UsdtAddressColumns UsdtAddress 全部列名，可作为 dbmysql.SafeOrder 的白名单
+var UsdtAddressColumns = []string{"id", "address", "is_active", "priority", "disable_reason", "created_at", "updated_at"}
@@ -0,0 +1,261 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/caoyuewen/components/common/caches"
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"github.com/caoyuewen/components/util/gen"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+// UsdtAddressStats 地址池中单个地址的统计信息
+type UsdtAddressStats struct {
+	Address       string `json:"address"`
+	IsActive      int    `json:"is_active"`
+	Priority      int    `json:"priority"`
+	DisableReason string `json:"disable_reason"`
+	Blacklisted   bool   `json:"blacklisted"`     // 是否在黑名单中
+	Pending       int64  `json:"pending"`         // 当前订单占位数量
+	TotalReceived string `json:"total_received"`  // 累计收款
+	LastDepositAt int64  `json:"last_deposit_at"` // 最后一次到账时间
+}
+
+// UsdtAddressPoolRule 地址自动停用规则
+type UsdtAddressPoolRule struct {
+	MaxBalance decimal.Decimal // 余额达到该值后停用，<= 0 表示不限制
+	// BalanceFunc 查询地址当前余额 (如 pay.UsdtOnChainBalance)，MaxBalance > 0 时必填
+	// 不能使用累计收款：归集后余额归零，累计值仍会让地址一直处于停用状态
+	BalanceFunc func(address string) (decimal.Decimal, error)
+}
+
+// ErrPoolRuleBalanceFunc MaxBalance > 0 但未设置 BalanceFunc
+var ErrPoolRuleBalanceFunc = errors.New("usdt address pool rule: BalanceFunc is required when MaxBalance is set")
+
+// UsdtAddressLastDepositAt 地址最后一次到账时间，从未到账时返回 0
+func UsdtAddressLastDepositAt(address string) (int64, error) {
+
+	var last struct {
+		PaidAt int64
+	}
+	f := GoodsOrderFields
+	err := dbmysql.ReadClient().Model(&GoodsOrder{}).
+		Select("COALESCE(MAX(paid_at), 0) as paid_at").
+		Where(f.ToAddress.Eq(address)).
+		Where(f.OrderStatus.Eq(OrderStatusSuccess)).
+		Scan(&last).Error
+	if err != nil {
+		return 0, err
+	}
+	return last.PaidAt, nil
+}
+
+// UsdtAddressStatsList 全部地址的统计信息
+func UsdtAddressStatsList() ([]UsdtAddressStats, error) {
+
+	list, err := UsdtAddressRepo.Find("priority desc")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]UsdtAddressStats, 0, len(list))
+	for _, v := range list {
+		pending, err := caches.UsdtAddress.PendingCount(v.Address)
+		if err != nil {
+			return nil, err
+		}
+		black, err := caches.UsdtAddress.IsBlacklisted(v.Address)
+		if err != nil {
+			return nil, err
+		}
+		lastDepositAt, err := UsdtAddressLastDepositAt(v.Address)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, UsdtAddressStats{
+			Address:       v.Address,
+			IsActive:      v.IsActive,
+			Priority:      v.Priority,
+			DisableReason: v.DisableReason,
+			Blacklisted:   black,
+			Pending:       pending,
+			TotalReceived: UsdtAddressTotalReceived(v.Address),
+			LastDepositAt: lastDepositAt,
+		})
+	}
+
+	return res, nil
+}
+
+// UsdtAddressSyncPool 将数据库中启用且未拉黑的地址按优先级同步到 Redis 地址池
+func UsdtAddressSyncPool() error {
+
+	cond := []interface{}{
+		clause.Eq{Column: "is_active", Value: UsdtAddressActive},
+	}
+
+	find, err := UsdtAddressRepo.Find("priority desc", cond...)
+	if err != nil {
+		return err
+	}
+
+	pool := make([]caches.UsdtPoolAddress, 0, len(find))
+	for _, v := range find {
+		black, err := caches.UsdtAddress.IsBlacklisted(v.Address)
+		if err != nil {
+			return err
+		}
+		if black {
+			continue
+		}
+		pool = append(pool, caches.UsdtPoolAddress{Address: v.Address, Priority: v.Priority})
+	}
+
+	return caches.UsdtAddress.FlushPoolWeighted(pool)
+}
+
+// UsdtAddressImport 批量导入收款地址，已存在的地址跳过，导入完成后同步地址池
+// 地址格式无效时停止导入并返回错误，此前的批次已写入
+func UsdtAddressImport(ctx context.Context, addrs iter.Seq[string], priority int) (dbmysql.BatchResult, error) {
+
+	src := func(yield func(UsdtAddress, error) bool) {
+		for addr := range addrs {
+			if err := ValidateTRC20Address(addr); err != nil {
+				yield(UsdtAddress{}, fmt.Errorf("%s: %w", addr, err))
+				return
+			}
+			if !yield(UsdtAddress{ID: gen.IdString(), Address: addr, IsActive: UsdtAddressActive, Priority: priority}, nil) {
+				return
+			}
+		}
+	}
+
+	res, err := UsdtAddressRepo.BatchLoad(ctx, src, dbmysql.BatchOptions{
+		Upsert:          true,
+		ConflictColumns: []string{"address"},
+		IgnoreConflict:  true,
+		OnChunk: func(chunk int, rows int64) {
+			log.Infof("UsdtAddressImport chunk:%d inserted:%d", chunk, rows)
+		},
+	})
+	if err != nil {
+		return res, err
+	}
+	return res, UsdtAddressSyncPool()
+}
+
+// UsdtAddressDisable 停用地址并同步地址池
+func UsdtAddressDisable(address, reason string) error {
+
+	if err := usdtAddressDisable(address, reason); err != nil {
+		return err
+	}
+	return UsdtAddressSyncPool()
+}
+
+func usdtAddressDisable(address, reason string) error {
+
+	_, err := UsdtAddressRepo.UpdateWhere(map[string]interface{}{
+		"is_active":      UsdtAddressInactive,
+		"disable_reason": reason,
+	}, clause.Eq{Column: "address", Value: address})
+	if err != nil {
+		return err
+	}
+	log.Warnf("UsdtAddress disabled, address:%s reason:%s", address, reason)
+	return nil
+}
+
+// usdtAddressBlacklistReason 拉黑停用的原因前缀，移出黑名单时据此恢复启用
+const usdtAddressBlacklistReason = "blacklist: "
+
+// UsdtAddressBlacklist 拉黑地址：加入黑名单、停用并同步地址池
+func UsdtAddressBlacklist(address, reason string) error {
+
+	if err := caches.UsdtAddress.Blacklist(address); err != nil {
+		return err
+	}
+	return UsdtAddressDisable(address, usdtAddressBlacklistReason+reason)
+}
+
+// UsdtAddressUnblacklist 移出黑名单；因拉黑被停用的地址重新启用并同步地址池，其他原因停用的地址保持停用
+func UsdtAddressUnblacklist(address string) error {
+
+	if err := caches.UsdtAddress.Unblacklist(address); err != nil {
+		return err
+	}
+
+	rows, err := UsdtAddressRepo.UpdateWhere(map[string]interface{}{
+		"is_active":      UsdtAddressActive,
+		"disable_reason": "",
+	},
+		clause.Eq{Column: "address", Value: address},
+		clause.Like{Column: "disable_reason", Value: usdtAddressBlacklistReason + "%"},
+	)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	log.Infof("UsdtAddress unblacklisted and enabled, address:%s", address)
+	return UsdtAddressSyncPool()
+}
+
+// UsdtAddressCheckPool 按规则检查启用中的地址，停用拉黑或余额超限的地址，返回被停用的地址
+func UsdtAddressCheckPool(rule UsdtAddressPoolRule) ([]string, error) {
+
+	if rule.MaxBalance.IsPositive() && rule.BalanceFunc == nil {
+		return nil, ErrPoolRuleBalanceFunc
+	}
+
+	cond := []interface{}{
+		clause.Eq{Column: "is_active", Value: UsdtAddressActive},
+	}
+
+	find, err := UsdtAddressRepo.Find("priority desc", cond...)
+	if err != nil {
+		return nil, err
+	}
+
+	var retired []string
+	for _, v := range find {
+		reason := ""
+
+		black, err := caches.UsdtAddress.IsBlacklisted(v.Address)
+		if err != nil {
+			return retired, err
+		}
+
+		if black {
+			reason = usdtAddressBlacklistReason + "pool check"
+		} else if rule.MaxBalance.IsPositive() {
+			balance, err := rule.BalanceFunc(v.Address)
+			if err != nil {
+				log.Errorf("UsdtAddressCheckPool balance err, address:%s err:%v", v.Address, err)
+				continue
+			}
+			if balance.GreaterThanOrEqual(rule.MaxBalance) {
+				reason = fmt.Sprintf("balance %s reached %s", balance.String(), rule.MaxBalance.String())
+			}
+		}
+
+		if reason == "" {
+			continue
+		}
+		if err := usdtAddressDisable(v.Address, reason); err != nil {
+			return retired, err
+		}
+		retired = append(retired, v.Address)
+	}
+
+	if len(retired) > 0 {
+		return retired, UsdtAddressSyncPool()
+	}
+	return retired, nil
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"gorm.io/gorm/clause"
+)
+
+var UsdtSweepRepo = dbmysql.NewBaseRepository[UsdtSweep]("id")
+
+const (
+	UsdtSweepStatusPlanned   = 1 // 待签名
+	UsdtSweepStatusNeedGas   = 2 // TRX 不足，待补充手续费
+	UsdtSweepStatusBroadcast = 3 // 已广播，待确认
+	UsdtSweepStatusSuccess   = 4 // 成功
+	UsdtSweepStatusFailed    = 5 // 失败
+	UsdtSweepStatusCancelled = 6 // 作废 (过期未签名或人工取消)
+)
+
+var UsdtSweepStatusMap = map[int]string{
+	UsdtSweepStatusPlanned:   "待签名",
+	UsdtSweepStatusNeedGas:   "待补充手续费",
+	UsdtSweepStatusBroadcast: "已广播",
+	UsdtSweepStatusSuccess:   "成功",
+	UsdtSweepStatusFailed:    "失败",
+	UsdtSweepStatusCancelled: "已作废",
+}
+
+// UsdtSweep 充值地址归集记录
+type UsdtSweep struct {
+	ID          string `gorm:"primaryKey;type:varchar(64)" json:"id"`
+	FromAddress string `gorm:"type:varchar(100);not null;index" json:"from_address"` // 充值地址
+	ToAddress   string `gorm:"type:varchar(100);not null" json:"to_address"`         // 冷钱包地址
+	Amount      string `gorm:"type:decimal(20,8);not null" json:"amount"`            // 归集金额
+	TxID        string `gorm:"type:varchar(100);index" json:"tx_id"`                 // 交易 ID (raw_data 的 sha256)
+	RawDataHex  string `gorm:"type:text" json:"raw_data_hex"`                        // 未签名交易 raw_data, 供离线签名
+	Expiration  int64  `gorm:"type:BIGINT;not null" json:"expiration"`               // 交易过期时间 (毫秒)
+	FeeLimit    int64  `gorm:"type:BIGINT;not null" json:"fee_limit"`                // 手续费上限 (sun)
+	EstimateFee int64  `gorm:"type:BIGINT;not null" json:"estimate_fee"`             // 预估燃烧 TRX (sun)
+	ActualFee   int64  `gorm:"type:BIGINT;not null" json:"actual_fee"`               // 实际消耗 TRX (sun)
+	Status      int    `gorm:"type:tinyint;not null;index" json:"status"`            // 归集状态
+	FailReason  string `gorm:"type:varchar(255)" json:"fail_reason"`                 // 失败原因
+	CreatedAt   int64  `gorm:"type:BIGINT;not null" json:"created_at"`               // 创建时间 (毫秒)
+	UpdatedAt   int64  `gorm:"type:BIGINT;not null" json:"updated_at"`               // 更新时间 (毫秒)
+}
+
+func (*UsdtSweep) TableName() string { return "usdt_sweep" }
+
+// UsdtSweepOpenAddresses 有未完成归集 (待签名/待补充手续费/已广播) 的地址
+func UsdtSweepOpenAddresses() ([]string, error) {
+
+	cond := []interface{}{
+		clause.IN{Column: clause.Column{Name: "status"}, Values: []interface{}{
+			UsdtSweepStatusPlanned, UsdtSweepStatusNeedGas, UsdtSweepStatusBroadcast,
+		}},
+	}
+
+	find, err := UsdtSweepRepo.Find("", cond...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0, len(find))
+	for _, v := range find {
+		res = append(res, v.FromAddress)
+	}
+	return res, nil
+}
+
+// UsdtSweepListByStatus 按状态查询归集记录
+func UsdtSweepListByStatus(status int) ([]UsdtSweep, error) {
+	return UsdtSweepRepo.Find("created_at asc", clause.Eq{Column: "status", Value: status})
+}
+
+// UsdtSweepUpdateStatus 更新归集状态，只允许从 fromStatus 迁移，返回是否更新成功
+func UsdtSweepUpdateStatus(id string, fromStatus []int, updates map[string]interface{}) (bool, error) {
+
+	values := make([]interface{}, len(fromStatus))
+	for i, v := range fromStatus {
+		values[i] = v
+	}
+
+	updates["updated_at"] = time.Now().UnixMilli()
+	rows, err := UsdtSweepRepo.UpdateWhere(updates,
+		clause.Eq{Column: "id", Value: id},
+		clause.IN{Column: clause.Column{Name: "status"}, Values: values},
+	)
+	return rows > 0, err
+}
+
+// UsdtSweepMarkGasReady 已补充手续费，从待补充手续费恢复为待签名
+func UsdtSweepMarkGasReady(id string) (bool, error) {
+	return UsdtSweepUpdateStatus(id, []int{UsdtSweepStatusNeedGas}, map[string]interface{}{
+		"status": UsdtSweepStatusPlanned,
+	})
+}
+
+// UsdtSweepMarkBroadcast 签名并广播后标记为已广播
+func UsdtSweepMarkBroadcast(id, txID string) (bool, error) {
+	return UsdtSweepUpdateStatus(id, []int{UsdtSweepStatusPlanned}, map[string]interface{}{
+		"status": UsdtSweepStatusBroadcast,
+		"tx_id":  txID,
+	})
+}
+
+// UsdtSweepMarkSuccess 链上确认成功
+func UsdtSweepMarkSuccess(id string, actualFee int64) (bool, error) {
+	return UsdtSweepUpdateStatus(id, []int{UsdtSweepStatusBroadcast}, map[string]interface{}{
+		"status":     UsdtSweepStatusSuccess,
+		"actual_fee": actualFee,
+	})
+}
+
+// UsdtSweepMarkFailed 链上执行失败
+func UsdtSweepMarkFailed(id string, actualFee int64, reason string) (bool, error) {
+	return UsdtSweepUpdateStatus(id, []int{UsdtSweepStatusBroadcast}, map[string]interface{}{
+		"status":      UsdtSweepStatusFailed,
+		"actual_fee":  actualFee,
+		"fail_reason": reason,
+	})
+}
+
+// UsdtSweepCancel 作废未广播的归集
+func UsdtSweepCancel(id, reason string) (bool, error) {
+	return UsdtSweepUpdateStatus(id, []int{UsdtSweepStatusPlanned, UsdtSweepStatusNeedGas}, map[string]interface{}{
+		"status":      UsdtSweepStatusCancelled,
+		"fail_reason": reason,
+	})
+}
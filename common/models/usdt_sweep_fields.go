@@ -0,0 +1,43 @@
+// Code generated by fieldgen; DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/caoyuewen/components/dbs/dbmysql"
+)
+
+// UsdtSweepFields UsdtSweep 列字段
+var UsdtSweepFields = struct {
+	ID          dbmysql.Field[string]
+	FromAddress dbmysql.Field[string]
+	ToAddress   dbmysql.Field[string]
+	Amount      dbmysql.Field[string]
+	TxID        dbmysql.Field[string]
+	RawDataHex  dbmysql.Field[string]
+	Expiration  dbmysql.Field[int64]
+	FeeLimit    dbmysql.Field[int64]
+	EstimateFee dbmysql.Field[int64]
+	ActualFee   dbmysql.Field[int64]
+	Status      dbmysql.Field[int]
+	FailReason  dbmysql.Field[string]
+	CreatedAt   dbmysql.Field[int64]
+	UpdatedAt   dbmysql.Field[int64]
+}{
+	ID:          dbmysql.NewField[string]("id"),
+	FromAddress: dbmysql.NewField[string]("from_address"),
+	ToAddress:   dbmysql.NewField[string]("to_address"),
+	Amount:      dbmysql.NewField[string]("amount"),
+	TxID:        dbmysql.NewField[string]("tx_id"),
+	RawDataHex:  dbmysql.NewField[string]("raw_data_hex"),
+	Expiration:  dbmysql.NewField[int64]("expiration"),
+	FeeLimit:    dbmysql.NewField[int64]("fee_limit"),
+	EstimateFee: dbmysql.NewField[int64]("estimate_fee"),
+	ActualFee:   dbmysql.NewField[int64]("actual_fee"),
+	Status:      dbmysql.NewField[int]("status"),
+	FailReason:  dbmysql.NewField[string]("fail_reason"),
+	CreatedAt:   dbmysql.NewField[int64]("created_at"),
+	UpdatedAt:   dbmysql.NewField[int64]("updated_at"),
+}
+
+// UsdtSweepColumns UsdtSweep 全部列名，可作为 dbmysql.SafeOrder 的白名单
+var UsdtSweepColumns = []string{"id", "from_address", "to_address", "amount", "tx_id", "raw_data_hex", "expiration", "fee_limit", "estimate_fee", "actual_fee", "status", "fail_reason", "created_at", "updated_at"}
@@ -3,6 +3,7 @@ package pay
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -34,7 +35,7 @@ func NewAlipayService(config *AlipayConfig) (*AlipayService, error) {
 	// 创建支付宝客户端
 	client, err := alipay.NewClient(config.AppID, config.PrivateKey, config.IsProd)
 	if err != nil {
-		return nil, fmt.Errorf("create alipay client error: %v", err)
+		return nil, newChannelError(ChannelAlipay, "NewClient", ErrAuth, err)
 	}
 
 	// 设置支付宝公钥证书 (用于验签)
@@ -79,7 +80,7 @@ func (s *AlipayService) CreatePCPayOrder(req *AlipayOrderRequest) (*AlipayOrderR
 	// 发起请求
 	payUrl, err := s.client.TradePagePay(context.Background(), bm)
 	if err != nil {
-		return nil, fmt.Errorf("alipay trade page paycalback error: %v", err)
+		return nil, alipayError("CreatePCPayOrder", err)
 	}
 
 	return &AlipayOrderResponse{
@@ -98,11 +99,11 @@ func (s *AlipayService) CreateQRCodePayOrder(req *AlipayOrderRequest) (*AlipayOr
 	// 发起预创建请求
 	resp, err := s.client.TradePrecreate(context.Background(), bm)
 	if err != nil {
-		return nil, fmt.Errorf("alipay trade precreate error: %v", err)
+		return nil, alipayError("CreateQRCodePayOrder", err)
 	}
 
 	if resp.Response.Code != "10000" {
-		return nil, fmt.Errorf("alipay error: %s - %s", resp.Response.Code, resp.Response.Msg)
+		return nil, alipayCodeError("CreateQRCodePayOrder", resp.Response.ErrorResponse)
 	}
 
 	return &AlipayOrderResponse{
@@ -123,7 +124,7 @@ func (s *AlipayService) CreateH5PayOrder(req *AlipayOrderRequest) (*AlipayOrderR
 	// 发起请求
 	payUrl, err := s.client.TradeWapPay(context.Background(), bm)
 	if err != nil {
-		return nil, fmt.Errorf("alipay trade wap paycalback error: %v", err)
+		return nil, alipayError("CreateH5PayOrder", err)
 	}
 
 	return &AlipayOrderResponse{
@@ -151,11 +152,11 @@ func (s *AlipayService) QueryOrder(orderNo string) (*AlipayQueryResult, error) {
 
 	resp, err := s.client.TradeQuery(context.Background(), bm)
 	if err != nil {
-		return nil, fmt.Errorf("alipay trade query error: %v", err)
+		return nil, alipayError("QueryOrder", err)
 	}
 
 	if resp.Response.Code != "10000" {
-		return nil, fmt.Errorf("alipay error: %s - %s", resp.Response.Code, resp.Response.Msg)
+		return nil, alipayCodeError("QueryOrder", resp.Response.ErrorResponse)
 	}
 
 	isPaid := resp.Response.TradeStatus == "TRADE_SUCCESS" || resp.Response.TradeStatus == "TRADE_FINISHED"
@@ -177,16 +178,16 @@ func (s *AlipayService) VerifyNotify(c *gin.Context) (gopay.BodyMap, error) {
 	// 解析通知内容
 	notifyReq, err := alipay.ParseNotifyToBodyMap(c.Request)
 	if err != nil {
-		return nil, fmt.Errorf("parse notify error: %v", err)
+		return nil, newChannelError(ChannelAlipay, "VerifyNotify", ErrValidation, err)
 	}
 
 	// 验证签名 (使用公钥证书)
 	ok, err := alipay.VerifySignWithCert(s.config.AlipayPublicKey, notifyReq)
 	if err != nil {
-		return nil, fmt.Errorf("verify sign error: %v", err)
+		return nil, newChannelError(ChannelAlipay, "VerifyNotify", ErrAuth, err)
 	}
 	if !ok {
-		return nil, fmt.Errorf("sign verify failed")
+		return nil, codeError(ChannelAlipay, "VerifyNotify", ErrAuth, "", "sign verify failed")
 	}
 
 	return notifyReq, nil
@@ -201,16 +202,58 @@ func (s *AlipayService) CloseOrder(orderNo string) error {
 
 	resp, err := s.client.TradeClose(context.Background(), bm)
 	if err != nil {
-		return fmt.Errorf("alipay trade close error: %v", err)
+		return alipayError("CloseOrder", err)
 	}
 
 	if resp.Response.Code != "10000" {
-		return fmt.Errorf("alipay error: %s - %s", resp.Response.Code, resp.Response.Msg)
+		return alipayCodeError("CloseOrder", resp.Response.ErrorResponse)
 	}
 
 	return nil
 }
 
+// ==================== 错误转换 ====================
+
+// alipayError 转换 gopay 返回的错误，业务错误按错误码归类
+func alipayError(op string, err error) error {
+	if bizErr, ok := alipay.IsBizError(err); ok {
+		return alipayCodeError(op, alipay.ErrorResponse{
+			Code: bizErr.Code, Msg: bizErr.Msg, SubCode: bizErr.SubCode, SubMsg: bizErr.SubMsg,
+		})
+	}
+	return transportError(ChannelAlipay, op, err)
+}
+
+// alipayCodeError 按支付宝公共错误码归类
+// https://opendocs.alipay.com/common/02km9f
+func alipayCodeError(op string, r alipay.ErrorResponse) error {
+	kind := ErrChannel
+	switch r.Code {
+	case "20000": // 服务不可用
+		kind = ErrRetryable
+	case "20001", "40006": // 授权权限不足 / 权限不足
+		kind = ErrAuth
+	case "40001", "40002": // 缺少必选参数 / 非法的参数
+		kind = ErrValidation
+	case "40004": // 业务处理失败
+		if strings.HasSuffix(r.SubCode, "TRADE_NOT_EXIST") {
+			kind = ErrNotFound
+		} else if strings.HasSuffix(r.SubCode, "SYSTEM_ERROR") {
+			kind = ErrRetryable
+		}
+	}
+
+	code := r.Code
+	if r.SubCode != "" {
+		code += "/" + r.SubCode
+	}
+	msg := r.Msg
+	if r.SubMsg != "" {
+		msg += " - " + r.SubMsg
+	}
+	return codeError(ChannelAlipay, op, kind, code, msg)
+}
+
 // ==================== 全局实例 ====================
 
 var alipayService *AlipayService
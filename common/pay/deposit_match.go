@@ -0,0 +1,283 @@
+package pay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caoyuewen/components/common/caches"
+	"github.com/caoyuewen/components/common/models"
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"github.com/caoyuewen/components/util"
+	"github.com/caoyuewen/components/util/gen"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// DepositMatchResult 到账匹配结果
+type DepositMatchResult struct {
+	Order      models.GoodsOrder  // 匹配到的订单 (已更新)
+	Decision   DepositDecision    // 策略决策
+	TopUpOrder *models.GoodsOrder // 自动生成的补款订单
+	Duplicate  bool               // 该交易已处理过
+}
+
+// MatchDeposit 将链上 USDT 到账匹配到收款地址上金额最接近的待支付/已过期订单，并按策略处理
+// 差额超出策略 MaxMatchGap/MaxMatchRate 的到账不匹配任何订单，返回 ErrNotFound
+// paidAt 为到账时间 (秒)，为 0 时取当前时间；订单被并发修改时重新匹配
+func MatchDeposit(ctx context.Context, transfer TransferInfoData, paidAt int64, policy DepositPolicy) (DepositMatchResult, error) {
+
+	var res DepositMatchResult
+
+	if transfer.Status != "success" {
+		return res, fmt.Errorf("%w: transfer %s status is %s", ErrValidation, transfer.TxHash, transfer.Status)
+	}
+	if paidAt <= 0 {
+		paidAt = time.Now().Unix()
+	}
+
+	err := dbmysql.RetryOnConflict(ctx, 3, func(ctx context.Context) error {
+		var err error
+		res, err = matchDeposit(ctx, transfer, paidAt, policy)
+		return err
+	})
+	return res, err
+}
+
+// matchDeposit 单次匹配，读取走主库以便冲突重试时拿到最新版本
+func matchDeposit(ctx context.Context, transfer TransferInfoData, paidAt int64, policy DepositPolicy) (DepositMatchResult, error) {
+
+	var res DepositMatchResult
+
+	// 1.幂等：同一交易只处理一次
+	ctx = dbmysql.WithPrimary(ctx)
+	exist, err := models.GoodsOrderRepo.FindOneCtx(ctx, models.GoodsOrderFields.TxHash.Eq(transfer.TxHash))
+	if err == nil {
+		res.Order = exist
+		res.Duplicate = true
+		return res, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return res, err
+	}
+
+	// 2.查找收款地址上金额最接近的订单
+	order, err := matchDepositOrder(ctx, transfer, policy)
+	if err != nil {
+		return res, err
+	}
+
+	// 3.策略决策
+	decision, err := policy.Decide(order, transfer.Amount, paidAt)
+	if err != nil {
+		return res, err
+	}
+
+	// 4.需要补款时为补款订单分配地址，分配失败则转人工审核
+	var topUp *models.GoodsOrder
+	if decision.TopUp.IsPositive() {
+		topUp, err = newTopUpOrder(order, decision.TopUp)
+		if err != nil {
+			log.Errorf("MatchDeposit create topup order err, order:%s err:%v", order.ID, err)
+			decision.ExternalStatus = strings.Replace(decision.ExternalStatus, DepositUnderpaidTopUp, DepositUnderpaidHold, 1)
+			decision.Remark = util.Truncate(decision.Remark+"，补款订单生成失败，转人工审核", 255)
+			decision.TopUp = decimal.Zero
+		}
+	}
+
+	// 5.事务内更新订单
+	err = dbmysql.WithTx(ctx, func(ctx context.Context) error {
+		return applyDepositDecision(ctx, &order, transfer, paidAt, decision, topUp, policy)
+	})
+	if err != nil {
+		if topUp != nil {
+			_ = caches.UsdtAddress.DelOrder(topUp.ToAddress, topUp.ID)
+		}
+		return res, err
+	}
+
+	// 6.释放地址占位
+	_ = caches.UsdtAddress.DelOrder(order.ToAddress, order.ID)
+
+	log.Infof("MatchDeposit order:%s tx:%s decision:%s remark:%s", order.ID, transfer.TxHash, decision.ExternalStatus, decision.Remark)
+
+	res.Order = order
+	res.Decision = decision
+	res.TopUpOrder = topUp
+	return res, nil
+}
+
+// matchDepositOrder 在收款地址的待支付/已过期订单中选择金额最接近且差额在策略范围内的一笔
+func matchDepositOrder(ctx context.Context, transfer TransferInfoData, policy DepositPolicy) (models.GoodsOrder, error) {
+
+	f := models.GoodsOrderFields
+	cond := []interface{}{
+		f.ToAddress.Eq(transfer.To),
+		f.PayType.Eq(PayTypeUsdt),
+		f.OrderStatus.In(OrderStatusPending, OrderStatusExpired),
+	}
+
+	list, err := models.GoodsOrderRepo.FindCtx(ctx, dbmysql.OrderBy(f.CreatedAt.Asc()), cond...)
+	if err != nil {
+		return models.GoodsOrder{}, err
+	}
+
+	best, found := pickDepositOrder(list, transfer.Amount, policy)
+	if !found {
+		return best, fmt.Errorf("%w: no order matched, to:%s amount:%s tx:%s", ErrNotFound, transfer.To, transfer.Amount.String(), transfer.TxHash)
+	}
+	return best, nil
+}
+
+// pickDepositOrder 选择金额最接近的订单，差额超出 policy.matchGap 的订单不参与匹配
+func pickDepositOrder(list []models.GoodsOrder, realAmount decimal.Decimal, policy DepositPolicy) (models.GoodsOrder, bool) {
+	var (
+		best    models.GoodsOrder
+		bestGap decimal.Decimal
+		found   bool
+	)
+	for _, v := range list {
+		amount, err := decimal.NewFromString(v.Amount)
+		if err != nil {
+			continue
+		}
+		gap := amount.Sub(realAmount).Abs()
+		if gap.GreaterThan(policy.matchGap(amount)) {
+			continue
+		}
+		if !found || gap.LessThan(bestGap) {
+			best, bestGap, found = v, gap, true
+		}
+	}
+	return best, found
+}
+
+// newTopUpOrder 为少付的订单生成补款订单并分配收款地址
+func newTopUpOrder(parent models.GoodsOrder, shortfall decimal.Decimal) (*models.GoodsOrder, error) {
+
+	addr, err := caches.UsdtAddress.Pop(shortfall)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order := parent
+	order.ID = gen.IdString()
+	order.ParentId = parent.ID
+	order.Amount = shortfall.String()
+	order.RealAmount = "0"
+	order.OrderStatus = OrderStatusPending
+	order.ExternalStatus = ""
+	order.ExternalOrderId = ""
+	order.FromAddress = ""
+	order.ToAddress = addr
+	order.TxHash = ""
+	order.PaidAt = 0
+	order.FailReason = ""
+	order.Remark = "补款订单，原订单 " + parent.ID
+	order.ExpireTime = now.Add(models.OrderExpiredTime * time.Minute).Unix()
+	order.CreatedAt = now.Unix()
+	order.UpdatedAt = now.Unix()
+
+	if err := caches.UsdtAddress.SetOrder(order.ID, addr, shortfall); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// applyDepositDecision 按决策更新订单，补款订单到账时同时完成上级订单
+func applyDepositDecision(ctx context.Context, order *models.GoodsOrder, transfer TransferInfoData, paidAt int64,
+	d DepositDecision, topUp *models.GoodsOrder, policy DepositPolicy) error {
+
+	f := models.GoodsOrderFields
+	now := time.Now().Unix()
+
+	updates := map[string]interface{}{
+		"real_amount":     transfer.Amount.String(),
+		"from_address":    transfer.From,
+		"tx_hash":         transfer.TxHash,
+		"paid_at":         paidAt,
+		"order_status":    d.Status,
+		"external_status": d.ExternalStatus,
+		"remark":          util.Truncate(d.Remark, 255),
+		"updated_at":      now,
+	}
+	if d.Status == OrderStatusFailed {
+		updates["fail_reason"] = d.ExternalStatus
+	}
+
+	err := models.GoodsOrderRepo.UpdateWhereVersionCtx(ctx, order.Version, updates,
+		f.ID.Eq(order.ID),
+		f.OrderStatus.In(OrderStatusPending, OrderStatusExpired),
+	)
+	if errors.Is(err, dbmysql.ErrVersionConflict) {
+		return fmt.Errorf("%w: order %s already processed: %w", ErrConflict, order.ID, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	order.RealAmount = transfer.Amount.String()
+	order.FromAddress = transfer.From
+	order.TxHash = transfer.TxHash
+	order.PaidAt = paidAt
+	order.OrderStatus = d.Status
+	order.ExternalStatus = d.ExternalStatus
+	order.Remark = util.Truncate(d.Remark, 255)
+	order.UpdatedAt = now
+	order.Version++
+
+	// 补款订单成功后逐级完成上级订单直至原订单
+	if order.ParentId != "" && d.Status == OrderStatusSuccess {
+		if err = completeTopUpParents(ctx, *order, now); err != nil {
+			return err
+		}
+	}
+
+	if topUp != nil {
+		if err := models.GoodsOrderRepo.InsertCtx(ctx, *topUp); err != nil {
+			return err
+		}
+	}
+
+	if d.Credit.IsPositive() {
+		if err := policy.CreditBalance(ctx, *order, d.Credit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maxTopUpDepth 补款链最大层数，防止异常数据成环
+const maxTopUpDepth = 10
+
+// completeTopUpParents 补款订单到账后沿 ParentId 逐级完成处于人工审核的上级订单
+// 补款订单本身少付时会再生成补款订单，最后一笔到账需要同时完成中间的补款订单与原订单
+func completeTopUpParents(ctx context.Context, order models.GoodsOrder, now int64) error {
+
+	f := models.GoodsOrderFields
+	for i := 0; order.ParentId != "" && i < maxTopUpDepth; i++ {
+		parent, err := models.GoodsOrderRepo.FindByIDCtx(ctx, order.ParentId)
+		if err != nil {
+			return err
+		}
+
+		_, err = models.GoodsOrderRepo.UpdateWhereCtx(ctx, map[string]interface{}{
+			"order_status":    OrderStatusSuccess,
+			"external_status": DepositPaidWithTopUp,
+			"remark":          "补款订单 " + order.ID + " 已到账",
+			"updated_at":      now,
+		},
+			f.ID.Eq(parent.ID),
+			f.OrderStatus.Eq(OrderStatusHold),
+		)
+		if err != nil {
+			return err
+		}
+		order = parent
+	}
+	return nil
+}
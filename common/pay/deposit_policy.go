@@ -0,0 +1,183 @@
+package pay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/caoyuewen/components/common/models"
+	"github.com/shopspring/decimal"
+)
+
+// ==================== 到账金额/时间处理策略 ====================
+
+// 少付处理方式
+const (
+	UnderpayHold    = 1 // 转人工审核
+	UnderpayPartial = 2 // 按实付金额入账
+	UnderpayTopUp   = 3 // 自动生成补款订单，补齐后原订单成功
+)
+
+// 多付处理方式
+const (
+	OverpayAccept = 1 // 订单成功，多付部分仅记录
+	OverpayCredit = 2 // 订单成功，多付部分入账到余额
+	OverpayHold   = 3 // 转人工审核
+)
+
+// 超时到账处理方式
+const (
+	LateAccept = 1 // 视为正常到账，继续按金额策略处理
+	LateHold   = 2 // 转人工审核
+	LateReject = 3 // 订单失败 (需人工退款)
+)
+
+// 决策结果写入订单 ExternalStatus
+const (
+	DepositPaid              = "PAID"
+	DepositPaidInTolerance   = "PAID_IN_TOLERANCE"
+	DepositPaidWithTopUp     = "PAID_WITH_TOPUP"
+	DepositUnderpaidHold     = "UNDERPAID_HOLD"
+	DepositUnderpaidPartial  = "UNDERPAID_PARTIAL"
+	DepositUnderpaidTopUp    = "UNDERPAID_TOPUP"
+	DepositOverpaid          = "OVERPAID"
+	DepositOverpaidCredited  = "OVERPAID_CREDITED"
+	DepositOverpaidHold      = "OVERPAID_HOLD"
+	DepositLateHold          = "LATE_HOLD"
+	DepositLateRejected      = "LATE_REJECTED"
+	depositLateAcceptedLabel = "LATE_"
+)
+
+// DepositPolicy 到账金额与订单金额不一致、或超时到账时的处理策略
+type DepositPolicy struct {
+	ToleranceAbs  decimal.Decimal // 绝对容差，差额在容差内视为足额
+	ToleranceRate decimal.Decimal // 比例容差 (0.01 = 1%)，与绝对容差取较大值
+
+	Underpay  int           // 少付处理方式，默认 UnderpayHold
+	Overpay   int           // 多付处理方式，默认 OverpayAccept
+	Late      int           // 超时到账处理方式，默认 LateHold
+	LateGrace time.Duration // 超过 ExpireTime 多久算超时，默认 0
+
+	// 匹配订单时允许的最大金额差，取绝对值与比例 (0.5 = 50%) 的较大值，且不小于容差
+	// 差额超出的到账视为与订单无关 (如粉尘转账)，不匹配任何订单；均为 0 时只匹配容差内的到账
+	MaxMatchGap  decimal.Decimal
+	MaxMatchRate decimal.Decimal
+
+	// CreditBalance 多付入账回调 (Overpay = OverpayCredit 时必填)
+	// 在订单更新的同一事务内调用，可通过 Ctx 后缀的仓库方法或 dbmysql.GetDBOrTx(ctx) 加入事务
+	CreditBalance func(ctx context.Context, order models.GoodsOrder, surplus decimal.Decimal) error
+}
+
+// DefaultDepositPolicy 默认策略：无容差，差额不超过订单金额一半时匹配，少付/多付/超时都转人工审核或仅记录
+var DefaultDepositPolicy = DepositPolicy{
+	Underpay:     UnderpayHold,
+	Overpay:      OverpayAccept,
+	Late:         LateHold,
+	MaxMatchRate: decimal.RequireFromString("0.5"),
+}
+
+// DepositDecision 策略决策结果
+type DepositDecision struct {
+	Status         int             // 订单状态
+	ExternalStatus string          // 决策代码，写入订单 ExternalStatus
+	Remark         string          // 决策说明，写入订单 Remark
+	Diff           decimal.Decimal // 实付 - 应付
+	TopUp          decimal.Decimal // 需要补款的金额 (UnderpayTopUp)
+	Credit         decimal.Decimal // 需要入账余额的金额 (OverpayCredit)
+}
+
+// tolerance 计算订单金额的有效容差
+func (p DepositPolicy) tolerance(amount decimal.Decimal) decimal.Decimal {
+	rate := amount.Mul(p.ToleranceRate).Abs()
+	return decimal.Max(p.ToleranceAbs.Abs(), rate)
+}
+
+// matchGap 计算订单金额允许匹配的最大差额
+func (p DepositPolicy) matchGap(amount decimal.Decimal) decimal.Decimal {
+	rate := amount.Mul(p.MaxMatchRate).Abs()
+	return decimal.Max(p.tolerance(amount), p.MaxMatchGap.Abs(), rate)
+}
+
+// Decide 根据应付金额、实付金额和到账时间做出决策，不修改任何数据
+func (p DepositPolicy) Decide(order models.GoodsOrder, realAmount decimal.Decimal, paidAt int64) (DepositDecision, error) {
+
+	amount, err := decimal.NewFromString(order.Amount)
+	if err != nil {
+		return DepositDecision{}, fmt.Errorf("%w: invalid order amount %q: %v", ErrValidation, order.Amount, err)
+	}
+
+	d := DepositDecision{Diff: realAmount.Sub(amount)}
+	summary := fmt.Sprintf("应付 %s 实付 %s", amount.String(), realAmount.String())
+
+	late := order.ExpireTime > 0 && paidAt > order.ExpireTime+int64(p.LateGrace/time.Second)
+	prefix := ""
+	if late {
+		switch p.Late {
+		case LateReject:
+			d.Status = OrderStatusFailed
+			d.ExternalStatus = DepositLateRejected
+			d.Remark = summary + "，超时到账，订单失败待退款"
+			return d, nil
+		case LateAccept:
+			prefix = depositLateAcceptedLabel
+			summary += "，超时到账"
+		default:
+			d.Status = OrderStatusHold
+			d.ExternalStatus = DepositLateHold
+			d.Remark = summary + "，超时到账，转人工审核"
+			return d, nil
+		}
+	}
+
+	switch {
+	case d.Diff.Abs().LessThanOrEqual(p.tolerance(amount)):
+		d.Status = OrderStatusSuccess
+		d.ExternalStatus = DepositPaid
+		d.Remark = summary
+		if !d.Diff.IsZero() {
+			d.ExternalStatus = DepositPaidInTolerance
+			d.Remark = summary + "，差额在容差内"
+		}
+
+	case d.Diff.IsNegative():
+		shortfall := d.Diff.Neg()
+		switch p.Underpay {
+		case UnderpayPartial:
+			d.Status = OrderStatusSuccess
+			d.ExternalStatus = DepositUnderpaidPartial
+			d.Remark = fmt.Sprintf("%s，少付 %s，按实付入账", summary, shortfall.String())
+		case UnderpayTopUp:
+			d.Status = OrderStatusHold
+			d.ExternalStatus = DepositUnderpaidTopUp
+			d.TopUp = shortfall
+			d.Remark = fmt.Sprintf("%s，少付 %s，已生成补款订单", summary, shortfall.String())
+		default:
+			d.Status = OrderStatusHold
+			d.ExternalStatus = DepositUnderpaidHold
+			d.Remark = fmt.Sprintf("%s，少付 %s，转人工审核", summary, shortfall.String())
+		}
+
+	default:
+		switch p.Overpay {
+		case OverpayCredit:
+			if p.CreditBalance == nil {
+				return d, fmt.Errorf("%w: deposit policy CreditBalance is required for OverpayCredit", ErrValidation)
+			}
+			d.Status = OrderStatusSuccess
+			d.ExternalStatus = DepositOverpaidCredited
+			d.Credit = d.Diff
+			d.Remark = fmt.Sprintf("%s，多付 %s，已入账余额", summary, d.Diff.String())
+		case OverpayHold:
+			d.Status = OrderStatusHold
+			d.ExternalStatus = DepositOverpaidHold
+			d.Remark = fmt.Sprintf("%s，多付 %s，转人工审核", summary, d.Diff.String())
+		default:
+			d.Status = OrderStatusSuccess
+			d.ExternalStatus = DepositOverpaid
+			d.Remark = fmt.Sprintf("%s，多付 %s", summary, d.Diff.String())
+		}
+	}
+
+	d.ExternalStatus = prefix + d.ExternalStatus
+	return d, nil
+}
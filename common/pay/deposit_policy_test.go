@@ -0,0 +1,76 @@
+package pay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/caoyuewen/components/common/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestDepositPolicy_Decide(t *testing.T) {
+
+	order := models.GoodsOrder{ID: "1", Amount: "10", ExpireTime: 1000}
+	credit := func(ctx context.Context, order models.GoodsOrder, surplus decimal.Decimal) error { return nil }
+
+	cases := []struct {
+		name   string
+		policy DepositPolicy
+		real   string
+		paidAt int64
+		status int
+		code   string
+	}{
+		{"exact", DefaultDepositPolicy, "10", 900, OrderStatusSuccess, DepositPaid},
+		{"in abs tolerance", DepositPolicy{ToleranceAbs: decimal.RequireFromString("0.1")}, "9.95", 900, OrderStatusSuccess, DepositPaidInTolerance},
+		{"in rate tolerance", DepositPolicy{ToleranceRate: decimal.RequireFromString("0.01")}, "10.1", 900, OrderStatusSuccess, DepositPaidInTolerance},
+		{"underpay hold", DefaultDepositPolicy, "9", 900, OrderStatusHold, DepositUnderpaidHold},
+		{"underpay partial", DepositPolicy{Underpay: UnderpayPartial}, "9", 900, OrderStatusSuccess, DepositUnderpaidPartial},
+		{"underpay topup", DepositPolicy{Underpay: UnderpayTopUp}, "9", 900, OrderStatusHold, DepositUnderpaidTopUp},
+		{"overpay accept", DefaultDepositPolicy, "11", 900, OrderStatusSuccess, DepositOverpaid},
+		{"overpay credit", DepositPolicy{Overpay: OverpayCredit, CreditBalance: credit}, "11", 900, OrderStatusSuccess, DepositOverpaidCredited},
+		{"overpay hold", DepositPolicy{Overpay: OverpayHold}, "11", 900, OrderStatusHold, DepositOverpaidHold},
+		{"late hold", DefaultDepositPolicy, "10", 1001, OrderStatusHold, DepositLateHold},
+		{"late in grace", DepositPolicy{LateGrace: time.Minute}, "10", 1050, OrderStatusSuccess, DepositPaid},
+		{"late reject", DepositPolicy{Late: LateReject}, "10", 1001, OrderStatusFailed, DepositLateRejected},
+		{"late accept", DepositPolicy{Late: LateAccept}, "11", 1001, OrderStatusSuccess, "LATE_" + DepositOverpaid},
+	}
+
+	for _, c := range cases {
+		d, err := c.policy.Decide(order, decimal.RequireFromString(c.real), c.paidAt)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if d.Status != c.status || d.ExternalStatus != c.code {
+			t.Fatalf("%s: got (%d, %s), want (%d, %s)", c.name, d.Status, d.ExternalStatus, c.status, c.code)
+		}
+	}
+
+	d, _ := DepositPolicy{Underpay: UnderpayTopUp}.Decide(order, decimal.RequireFromString("7.5"), 900)
+	if !d.TopUp.Equal(decimal.RequireFromString("2.5")) {
+		t.Fatalf("topup = %s, want 2.5", d.TopUp)
+	}
+
+	if _, err := (DepositPolicy{Overpay: OverpayCredit}).Decide(order, decimal.RequireFromString("11"), 900); err == nil {
+		t.Fatal("OverpayCredit without CreditBalance should fail")
+	}
+}
+
+func TestPickDepositOrder(t *testing.T) {
+	list := []models.GoodsOrder{{ID: "a", Amount: "10"}, {ID: "b", Amount: "30"}}
+	policy := DepositPolicy{MaxMatchGap: decimal.RequireFromString("1")}
+
+	if o, ok := pickDepositOrder(list, decimal.RequireFromString("29.5"), policy); !ok || o.ID != "b" {
+		t.Fatalf("got (%s, %v), want b", o.ID, ok)
+	}
+	if _, ok := pickDepositOrder(list, decimal.RequireFromString("0.01"), policy); ok {
+		t.Fatal("dust transfer should not match")
+	}
+	if _, ok := pickDepositOrder(list, decimal.RequireFromString("5"), DefaultDepositPolicy); !ok {
+		t.Fatal("half paid order should match default policy")
+	}
+	if _, ok := pickDepositOrder(list, decimal.RequireFromString("9.9"), DepositPolicy{}); ok {
+		t.Fatal("zero policy should only match within tolerance")
+	}
+}
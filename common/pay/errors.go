@@ -0,0 +1,179 @@
+package pay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/caoyuewen/components/common/caches"
+	"github.com/caoyuewen/components/util/httpclient"
+)
+
+// ==================== 错误分类 ====================
+
+// 错误类别，通过 errors.Is 判断
+var (
+	ErrRetryable     = errors.New("pay: retryable")                // 超时、限流、三方 5xx，可重试
+	ErrAuth          = errors.New("pay: auth failed")              // 密钥错误、验签失败
+	ErrValidation    = errors.New("pay: invalid request")          // 参数错误
+	ErrNotFound      = errors.New("pay: not found")                // 订单/渠道不存在
+	ErrConflict      = errors.New("pay: conflict")                 // 订单已被处理
+	ErrPoolExhausted = errors.New("pay: address pool exhausted")   // 地址池无可用地址
+	ErrChannel       = errors.New("pay: channel rejected request") // 三方返回的业务错误
+)
+
+// 渠道名称
+const (
+	ChannelQuickNode = "quicknode"
+	ChannelUugate    = "uugate"
+	ChannelTronGrid  = "trongrid"
+	ChannelAlipay    = "alipay"
+	ChannelWechat    = "wechat"
+)
+
+// ChannelError 支付渠道错误，携带渠道、操作、三方错误码
+// errors.Is 可匹配错误类别与底层错误，errors.As 可取出 *ChannelError
+type ChannelError struct {
+	Channel    string // 渠道名称
+	Op         string // 操作，如 CallDeposit
+	Kind       error  // 错误类别 ErrRetryable / ErrAuth ...
+	Code       string // 三方错误码
+	Message    string // 三方错误信息
+	HTTPStatus int    // 三方 HTTP 状态码
+	Err        error  // 底层错误
+}
+
+func (e *ChannelError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Channel)
+	if e.Op != "" {
+		b.WriteString(" ")
+		b.WriteString(e.Op)
+	}
+	b.WriteString(": ")
+	if e.Kind != nil {
+		b.WriteString(strings.TrimPrefix(e.Kind.Error(), "pay: "))
+	}
+	if e.HTTPStatus != 0 {
+		fmt.Fprintf(&b, ", status %d", e.HTTPStatus)
+	}
+	if e.Code != "" {
+		b.WriteString(", code ")
+		b.WriteString(e.Code)
+	}
+	if e.Message != "" {
+		b.WriteString(", ")
+		b.WriteString(e.Message)
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *ChannelError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// newChannelError 构造渠道错误
+func newChannelError(channel, op string, kind error, err error) *ChannelError {
+	return &ChannelError{Channel: channel, Op: op, Kind: kind, Err: err}
+}
+
+// codeError 三方返回业务错误码
+func codeError(channel, op string, kind error, code, msg string) *ChannelError {
+	return &ChannelError{Channel: channel, Op: op, Kind: kind, Code: code, Message: msg}
+}
+
+// transportError 网络层错误，超时与连接失败归为可重试
+func transportError(channel, op string, err error) *ChannelError {
+	kind := ErrChannel
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, httpclient.ErrCircuitOpen) || errors.As(err, &netErr) {
+		kind = ErrRetryable
+	}
+	return newChannelError(channel, op, kind, err)
+}
+
+// statusError 三方 HTTP 状态码错误
+func statusError(channel, op string, status int, body []byte) *ChannelError {
+	return &ChannelError{
+		Channel:    channel,
+		Op:         op,
+		Kind:       kindOfStatus(status),
+		HTTPStatus: status,
+		Message:    truncateBody(body),
+	}
+}
+
+// poolError 地址池错误
+func poolError(channel, op string, err error) *ChannelError {
+	if errors.Is(err, caches.ErrPoolEmpty) || errors.Is(err, caches.ErrNoAvailableAddress) {
+		return newChannelError(channel, op, ErrPoolExhausted, err)
+	}
+	return newChannelError(channel, op, ErrRetryable, err)
+}
+
+// kindOfStatus 按三方 HTTP 状态码归类
+func kindOfStatus(status int) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrAuth
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusConflict:
+		return ErrConflict
+	case status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500:
+		return ErrRetryable
+	case status >= 400:
+		return ErrValidation
+	default:
+		return ErrChannel
+	}
+}
+
+func truncateBody(body []byte) string {
+	const maxLen = 512
+	if len(body) > maxLen {
+		return string(body[:maxLen]) + "..."
+	}
+	return string(body)
+}
+
+// IsRetryable 是否可重试
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRetryable)
+}
+
+// HTTPStatus 将错误映射为返回给调用方的 HTTP 状态码
+func HTTPStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrAuth):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrPoolExhausted), errors.Is(err, ErrRetryable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrChannel):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,51 @@
+package pay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/caoyuewen/components/common/caches"
+)
+
+func TestChannelError(t *testing.T) {
+
+	err := fmt.Errorf("call deposit: %w", transportError(ChannelQuickNode, "POST", context.DeadlineExceeded))
+	if !IsRetryable(err) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("timeout should be retryable and keep cause: %v", err)
+	}
+
+	var ce *ChannelError
+	if !errors.As(err, &ce) || ce.Channel != ChannelQuickNode {
+		t.Fatalf("errors.As failed: %v", err)
+	}
+
+	cases := []struct {
+		err  error
+		kind error
+		code int
+	}{
+		{statusError(ChannelTronGrid, "GET", http.StatusTooManyRequests, nil), ErrRetryable, http.StatusServiceUnavailable},
+		{statusError(ChannelTronGrid, "GET", http.StatusUnauthorized, nil), ErrAuth, http.StatusUnauthorized},
+		{statusError(ChannelTronGrid, "GET", http.StatusBadRequest, nil), ErrValidation, http.StatusBadRequest},
+		{poolError(ChannelQuickNode, "CallDeposit", caches.ErrNoAvailableAddress), ErrPoolExhausted, http.StatusServiceUnavailable},
+		{wechatError("QueryOrder", http.StatusNotFound, `{"code":"ORDER_NOT_EXIST","message":"订单不存在"}`), ErrNotFound, http.StatusNotFound},
+		{codeError(ChannelUugate, "CallDeposit", ErrChannel, "1", "failed"), ErrChannel, http.StatusBadGateway},
+		{fmt.Errorf("%w: order 1 already processed", ErrConflict), ErrConflict, http.StatusConflict},
+	}
+
+	for _, c := range cases {
+		if !errors.Is(c.err, c.kind) {
+			t.Fatalf("%v should be %v", c.err, c.kind)
+		}
+		if got := HTTPStatus(c.err); got != c.code {
+			t.Fatalf("HTTPStatus(%v) = %d, want %d", c.err, got, c.code)
+		}
+	}
+
+	if HTTPStatus(errors.New("boom")) != http.StatusInternalServerError {
+		t.Fatal("unknown errors should map to 500")
+	}
+}
@@ -0,0 +1,37 @@
+package pay
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/caoyuewen/components/util/httpclient"
+)
+
+// configureChannelHost 按三方域名设置 httpclient 配置
+func configureChannelHost(rawURL string, cfg httpclient.HostConfig) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return
+	}
+	httpclient.Configure(u.Host, cfg)
+}
+
+// channelRequest 通过 httpclient 发送三方请求，非 okStatus 状态码转为 ChannelError
+// okStatus 为空时仅 200 视为成功
+func channelRequest(ctx context.Context, channel, op string, req *httpclient.Request, okStatus ...int) ([]byte, error) {
+	resp, err := httpclient.Do(ctx, req)
+	if err != nil {
+		return nil, transportError(channel, op, fmt.Errorf("request error: %w", err))
+	}
+
+	if len(okStatus) == 0 {
+		okStatus = []int{200}
+	}
+	for _, s := range okStatus {
+		if resp.StatusCode == s {
+			return resp.Body, nil
+		}
+	}
+	return nil, statusError(channel, op, resp.StatusCode, resp.Body)
+}
@@ -1,15 +1,17 @@
 package pay
 
 import (
-	"errors"
 	"fmt"
+
+	"github.com/caoyuewen/components/common/models"
 )
 
 const (
-	OrderStatusPending = 1 // 待支付
-	OrderStatusSuccess = 2 // 成功
-	OrderStatusFailed  = 3 // 失败
-	OrderStatusExpired = 4 // 已过期
+	OrderStatusPending = 1                         // 待支付
+	OrderStatusSuccess = models.OrderStatusSuccess // 成功
+	OrderStatusFailed  = 3                         // 失败
+	OrderStatusExpired = 4                         // 已过期
+	OrderStatusHold    = 5                         // 待审核 (金额不符或超时到账)
 )
 
 const (
@@ -35,6 +37,7 @@ var OrderStatusMap = map[int]string{
 	OrderStatusSuccess: "成功",
 	OrderStatusFailed:  "失败",
 	OrderStatusExpired: "已过期",
+	OrderStatusHold:    "待审核",
 }
 
 // PaymentService 所有的第三方支付渠道必须实现以下接口
@@ -71,8 +74,7 @@ func CreatePaymentServer(c PaymentServerCondition) (PaymentService, error) {
 
 	ps, ok := PaymentMap[c.Name]
 	if !ok {
-		errStr := fmt.Sprintf("not found payment factory by name:%s", c.Name)
-		return paymentService, errors.New(errStr)
+		return paymentService, fmt.Errorf("%w: payment factory by name:%s", ErrNotFound, c.Name)
 	}
 
 	return ps.PayService, nil
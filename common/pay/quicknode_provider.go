@@ -1,13 +1,12 @@
 package pay
 
 import (
-	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math/big"
 	"net/http"
 	"strings"
@@ -15,6 +14,7 @@ import (
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/caoyuewen/components/common/caches"
+	"github.com/caoyuewen/components/util/httpclient"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 )
@@ -22,6 +22,7 @@ import (
 const (
 	network               = "tron-mainnet"
 	QuickNodeWebhooksName = "tron usdt node webhook"
+	quickNodeWebhookAPI   = "https://api.quicknode.com/webhooks/rest/v1/webhooks"
 )
 
 type QuickNodeFactory struct{}
@@ -53,13 +54,27 @@ func InitQuickNode(env string) {
 	}
 
 	payment := Payment{
-		Name:        "quicknode",
+		Name:        ChannelQuickNode,
 		PayService:  quickNodeService,
 		PaymentType: PayTypeUsdt,
 	}
 
+	configureChannelHost(quickNodeService.Domain, httpclient.HostConfig{
+		Timeout:          10 * time.Second,
+		BreakerThreshold: 5,
+		LogBody:          true,
+	})
+	configureChannelHost(quickNodeWebhookAPI, httpclient.HostConfig{
+		Timeout:          10 * time.Second,
+		BreakerThreshold: 5,
+		LogBody:          true,
+	})
+
 	paymentRegister(payment)
 
+	// 地址池变化时重建 webhook 监听的钱包列表
+	caches.UsdtAddress.OnPoolChange(QuickNodeWebhooksName, quickNodeService.CheckWebhooksConfig)
+
 }
 
 func QuickNodeService() *QuickNode {
@@ -74,13 +89,13 @@ func (that *QuickNode) CallDeposit(id, amount string) (CallDepositResult, error)
 	amountDec, err := decimal.NewFromString(amount)
 	if err != nil {
 		log.Errorf("QuickNodeCallDepositErr:amount err,id:%s,amount:%s,err:%s \n", id, amount, err.Error())
-		return res, err
+		return res, newChannelError(ChannelQuickNode, "CallDeposit", ErrValidation, err)
 	}
 
 	address, err := caches.UsdtAddress.Pop(amountDec)
 	if err != nil {
 		log.Errorf("QuickNodeCallDepositErr:UsdtAddressPop err,id:%s,amount:%s,err:%s \n", id, amount, err.Error())
-		return res, err
+		return res, poolError(ChannelQuickNode, "CallDeposit", err)
 	}
 
 	res.ToAddress = address
@@ -133,7 +148,7 @@ func (that *QuickNode) CheckWebhooksConfig(wallets []string) error {
 // CreateWebhook 创建一个新的 webhook
 func (that *QuickNode) CreateWebhook(name string, wallets []string) ([]byte, error) {
 
-	url := "https://api.quicknode.com/webhooks/rest/v1/webhooks/template/evmWalletFilter"
+	url := quickNodeWebhookAPI + "/template/evmWalletFilter"
 	apiKey := that.ApiKey
 
 	var evWallets []string
@@ -166,7 +181,7 @@ func (that *QuickNode) CreateWebhook(name string, wallets []string) ([]byte, err
 		"x-api-key":    apiKey,
 	}
 
-	return that.sendRequest(url, "POST", header, payload)
+	return that.sendRequest(url, "POST", header, payload, false)
 }
 
 type WebHooksListResult struct {
@@ -177,7 +192,7 @@ type WebHooksListResult struct {
 // WebhooksList 查询 webhooks 列表
 func (that *QuickNode) WebhooksList() (WebHooksListResult, error) {
 
-	url := "https://api.quicknode.com/webhooks/rest/v1/webhooks"
+	url := quickNodeWebhookAPI
 	apiKey := that.ApiKey
 
 	header := map[string]string{
@@ -186,14 +201,14 @@ func (that *QuickNode) WebhooksList() (WebHooksListResult, error) {
 	}
 
 	var res WebHooksListResult
-	respBytes, err := that.sendRequest(url, "GET", header, nil)
+	respBytes, err := that.sendRequest(url, "GET", header, nil, true)
 	if err != nil {
 		return res, err
 	}
 
 	err = json.Unmarshal(respBytes, &res)
 	if err != nil {
-		return res, err
+		return res, newChannelError(ChannelQuickNode, "WebhooksList", ErrChannel, err)
 	}
 
 	return res, nil
@@ -202,7 +217,7 @@ func (that *QuickNode) WebhooksList() (WebHooksListResult, error) {
 // WebhookUpdate 只适合更新 状态 回调地址 email
 func (that *QuickNode) WebhookUpdate(id string, status string) ([]byte, error) {
 
-	url := fmt.Sprintf("https://api.quicknode.com/webhooks/rest/v1/webhooks/%s", id)
+	url := quickNodeWebhookAPI + "/" + id
 
 	payload := map[string]interface{}{
 		"name":               QuickNodeWebhooksName,
@@ -221,13 +236,13 @@ func (that *QuickNode) WebhookUpdate(id string, status string) ([]byte, error) {
 		"x-api-key":    that.ApiKey,
 	}
 
-	return that.sendRequest(url, "PATCH", header, payload)
+	return that.sendRequest(url, "PATCH", header, payload, true)
 }
 
 // WebhooksDelete 删除
 func (that *QuickNode) WebhooksDelete(id string) error {
 
-	url := fmt.Sprintf("https://api.quicknode.com/webhooks/rest/v1/webhooks/%s", id)
+	url := quickNodeWebhookAPI + "/" + id
 
 	header := map[string]string{
 		"accept":       "application/json",
@@ -235,7 +250,7 @@ func (that *QuickNode) WebhooksDelete(id string) error {
 		"x-api-key":    that.ApiKey,
 	}
 
-	_, err := that.sendRequest(url, "DELETE", header, nil)
+	_, err := that.sendRequest(url, "DELETE", header, nil, true)
 
 	return err
 
@@ -343,13 +358,13 @@ func (that *QuickNode) EthGetTransactionReceipt(txHash string) (EthGetTransactio
 		"params": []interface{}{txHash},
 	}
 
-	resp, err := that.sendRequest(that.Domain, "POST", header, req)
+	resp, err := that.sendRequest(that.Domain, "POST", header, req, true)
 	if err != nil {
 		return result, err
 	}
 
 	if err := json.Unmarshal(resp, &result); err != nil {
-		return result, err
+		return result, newChannelError(ChannelQuickNode, "EthGetTransactionReceipt", ErrChannel, err)
 	}
 
 	return result, nil
@@ -374,11 +389,11 @@ func TransferInfo(payload []byte) (TransferInfoData, error) {
 	var cb QuickNodeCallbackFd
 	err := json.Unmarshal(payload, &cb)
 	if err != nil {
-		return TransferInfoData{}, err
+		return TransferInfoData{}, newChannelError(ChannelQuickNode, "TransferInfo", ErrValidation, err)
 	}
 
 	if len(cb.MatchingReceipts) == 0 || len(cb.MatchingReceipts[0].Logs) == 0 {
-		return TransferInfoData{}, errors.New("MatchingReceipts is empty")
+		return TransferInfoData{}, newChannelError(ChannelQuickNode, "TransferInfo", ErrValidation, errors.New("MatchingReceipts is empty"))
 	}
 
 	receipt := cb.MatchingReceipts[0]
@@ -411,52 +426,24 @@ func NormalizeTxHash(hash string) string {
 	return strings.ToLower(hash)
 }
 
-// sendRequest 统一的请求
-func (that *QuickNode) sendRequest(url, method string, header map[string]string, param map[string]any) ([]byte, error) {
-	log.Info("QuickNode request url:", url)
-
-	var reqBody io.Reader
+// sendRequest 统一的请求，idempotent 为 true 时 POST/PATCH 请求失败也会重试
+func (that *QuickNode) sendRequest(url, method string, header map[string]string, param map[string]any, idempotent bool) ([]byte, error) {
+	var body []byte
 	if param != nil {
 		payload, err := json.Marshal(param)
 		if err != nil {
-			return nil, fmt.Errorf("json marshal error: %v", err)
+			return nil, newChannelError(ChannelQuickNode, method, ErrValidation, fmt.Errorf("json marshal error: %w", err))
 		}
-		log.Info("QuickNode request params:", string(payload))
-		reqBody = bytes.NewReader(payload)
-	}
-
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("create request error: %v", err)
-	}
-
-	for k, v := range header {
-		req.Header.Set(k, v)
-	}
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Info("QuickNode request err:", err.Error())
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read response error: %v", err)
-	}
-
-	log.Info("QuickNode resp:", string(body))
-
-	if resp.StatusCode != http.StatusOK &&
-		resp.StatusCode != http.StatusCreated && // 201
-		resp.StatusCode != http.StatusNoContent { // 204
-		log.Info("QuickNode request status err:", resp.StatusCode)
-		return nil, fmt.Errorf("status code is %d", resp.StatusCode)
+		body = payload
 	}
 
-	return body, nil
+	return channelRequest(context.Background(), ChannelQuickNode, method, &httpclient.Request{
+		Method:     method,
+		URL:        url,
+		Header:     header,
+		Body:       body,
+		Idempotent: idempotent,
+	}, http.StatusOK, http.StatusCreated, http.StatusNoContent)
 }
 
 // TronToEvmAddress Tron → EVM 0x 地址
@@ -1,11 +1,14 @@
 package pay
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"time"
+
+	"github.com/caoyuewen/components/util/httpclient"
+	"github.com/shopspring/decimal"
 )
 
 // ==================== TRON 网络配置 ====================
@@ -22,9 +25,24 @@ var (
 	tronAPIKey string // TronGrid API Key
 )
 
+// TronGrid 限流配置: 无 key 的请求会被严格限流，有 key 时约 15 QPS
+var (
+	tronGridLimit        = httpclient.HostConfig{Timeout: 30 * time.Second, RateLimit: 3, MaxRetries: 3, BreakerThreshold: 10}
+	tronGridLimitWithKey = httpclient.HostConfig{Timeout: 30 * time.Second, RateLimit: 15, MaxRetries: 3, BreakerThreshold: 10}
+)
+
+func init() {
+	configureChannelHost(TronGridAPI, tronGridLimit)
+}
+
 // SetTronAPIKey 设置 TronGrid API Key
 func SetTronAPIKey(apiKey string) {
 	tronAPIKey = apiKey
+	if apiKey != "" {
+		configureChannelHost(TronGridAPI, tronGridLimitWithKey)
+	} else {
+		configureChannelHost(TronGridAPI, tronGridLimit)
+	}
 }
 
 // ==================== TRON API 响应结构 ====================
@@ -67,77 +85,281 @@ func GetTRC20Transactions(address string, minTimestamp int64, limit int) ([]TRC2
 		limit = 50
 	}
 
-	url := fmt.Sprintf("%s/v1/accounts/%s/transactions/trc20?only_to=true&limit=%d&contract_address=%s",
-		TronGridAPI, address, limit, UsdtContractAddress)
+	path := fmt.Sprintf("/v1/accounts/%s/transactions/trc20?only_to=true&limit=%d&contract_address=%s",
+		address, limit, UsdtContractAddress)
 
 	if minTimestamp > 0 {
-		url += fmt.Sprintf("&min_timestamp=%d", minTimestamp)
+		path += fmt.Sprintf("&min_timestamp=%d", minTimestamp)
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	body, err := tronGridRequest("GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/json")
-	if tronAPIKey != "" {
-		req.Header.Set("TRON-PRO-API-KEY", tronAPIKey)
+	var result TRC20Response
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, tronParseError("GetTRC20Transactions", err, body)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	if !result.Success {
+		return nil, codeError(ChannelTronGrid, "GetTRC20Transactions", ErrChannel, "", truncateBody(body))
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	return result.Data, nil
+}
+
+// GetTransactionInfo 获取交易详情
+func GetTransactionInfo(txHash string) (map[string]interface{}, error) {
+	body, err := tronGridRequest("GET", "/v1/transactions/"+txHash, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var result TRC20Response
+	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("parse response error: %v, body: %s", err, string(body))
+		return nil, tronParseError("GetTransactionInfo", err, body)
+	}
+
+	return result, nil
+}
+
+// ==================== 账户与资源 ====================
+
+// TronAccount 账户余额信息
+type TronAccount struct {
+	Address string              `json:"address"`
+	Balance int64               `json:"balance"` // TRX 余额 (sun)
+	Trc20   []map[string]string `json:"trc20"`   // 合约地址 -> 余额 (最小单位)
+}
+
+// TronAccountResource 账户资源 (能量/带宽)
+type TronAccountResource struct {
+	FreeNetUsed  int64 `json:"freeNetUsed"`
+	FreeNetLimit int64 `json:"freeNetLimit"`
+	NetUsed      int64 `json:"NetUsed"`
+	NetLimit     int64 `json:"NetLimit"`
+	EnergyUsed   int64 `json:"EnergyUsed"`
+	EnergyLimit  int64 `json:"EnergyLimit"`
+}
+
+// AvailableEnergy 可用能量
+func (r TronAccountResource) AvailableEnergy() int64 {
+	return max(r.EnergyLimit-r.EnergyUsed, 0)
+}
+
+// AvailableBandwidth 可用带宽 (免费带宽 + 质押带宽)
+func (r TronAccountResource) AvailableBandwidth() int64 {
+	return max(r.FreeNetLimit-r.FreeNetUsed, 0) + max(r.NetLimit-r.NetUsed, 0)
+}
+
+// TronBlockRef 构造交易时引用的区块
+type TronBlockRef struct {
+	BlockID   string
+	Number    int64
+	Timestamp int64 // 毫秒
+}
+
+// TronChainFees 链上资源单价
+type TronChainFees struct {
+	EnergyFee    int64 // 每单位能量燃烧的 sun
+	BandwidthFee int64 // 每字节带宽燃烧的 sun
+}
+
+// GetTronAccount 获取账户 TRX 与 TRC20 余额
+func GetTronAccount(address string) (TronAccount, error) {
+	var result struct {
+		Data    []TronAccount `json:"data"`
+		Success bool          `json:"success"`
 	}
 
+	body, err := tronGridRequest("GET", "/v1/accounts/"+address, nil)
+	if err != nil {
+		return TronAccount{}, err
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return TronAccount{}, tronParseError("GetTronAccount", err, body)
+	}
 	if !result.Success {
-		return nil, fmt.Errorf("trongrid api error: %s", string(body))
+		return TronAccount{}, codeError(ChannelTronGrid, "GetTronAccount", ErrChannel, "", truncateBody(body))
 	}
 
-	return result.Data, nil
+	// 未激活的地址返回空列表
+	if len(result.Data) == 0 {
+		return TronAccount{Address: address}, nil
+	}
+	return result.Data[0], nil
 }
 
-// GetTransactionInfo 获取交易详情
-func GetTransactionInfo(txHash string) (map[string]interface{}, error) {
-	url := fmt.Sprintf("%s/v1/transactions/%s", TronGridAPI, txHash)
+// GetUsdtBalance 获取地址的 USDT (TRC20) 余额
+func GetUsdtBalance(account TronAccount) decimal.Decimal {
+	for _, token := range account.Trc20 {
+		if v, ok := token[UsdtContractAddress]; ok {
+			amount, err := decimal.NewFromString(v)
+			if err != nil {
+				return decimal.Zero
+			}
+			return amount.Shift(-UsdtDecimals)
+		}
+	}
+	return decimal.Zero
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// UsdtOnChainBalance 查询地址当前链上 USDT 余额，可用作 models.UsdtAddressPoolRule.BalanceFunc
+func UsdtOnChainBalance(address string) (decimal.Decimal, error) {
+	account, err := GetTronAccount(address)
 	if err != nil {
-		return nil, err
+		return decimal.Zero, err
 	}
+	return GetUsdtBalance(account), nil
+}
 
-	req.Header.Set("Accept", "application/json")
-	if tronAPIKey != "" {
-		req.Header.Set("TRON-PRO-API-KEY", tronAPIKey)
+// GetAccountResource 获取账户能量与带宽
+func GetAccountResource(address string) (TronAccountResource, error) {
+	var res TronAccountResource
+
+	body, err := tronGridRequest("POST", "/wallet/getaccountresource", map[string]any{
+		"address": address,
+		"visible": true,
+	})
+	if err != nil {
+		return res, err
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return res, tronParseError("GetAccountResource", err, body)
+	}
+	return res, nil
+}
+
+// GetNowBlock 获取最新区块引用
+func GetNowBlock() (TronBlockRef, error) {
+	var result struct {
+		BlockID     string `json:"blockID"`
+		BlockHeader struct {
+			RawData struct {
+				Number    int64 `json:"number"`
+				Timestamp int64 `json:"timestamp"`
+			} `json:"raw_data"`
+		} `json:"block_header"`
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	body, err := tronGridRequest("POST", "/wallet/getnowblock", nil)
 	if err != nil {
-		return nil, err
+		return TronBlockRef{}, err
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return TronBlockRef{}, tronParseError("GetNowBlock", err, body)
+	}
+	if result.BlockID == "" {
+		return TronBlockRef{}, codeError(ChannelTronGrid, "GetNowBlock", ErrChannel, "", truncateBody(body))
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	return TronBlockRef{
+		BlockID:   result.BlockID,
+		Number:    result.BlockHeader.RawData.Number,
+		Timestamp: result.BlockHeader.RawData.Timestamp,
+	}, nil
+}
+
+// GetChainFees 获取能量与带宽的燃烧单价
+func GetChainFees() (TronChainFees, error) {
+	var result struct {
+		ChainParameter []struct {
+			Key   string `json:"key"`
+			Value int64  `json:"value"`
+		} `json:"chainParameter"`
+	}
+
+	body, err := tronGridRequest("POST", "/wallet/getchainparameters", nil)
 	if err != nil {
-		return nil, err
+		return TronChainFees{}, err
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return TronChainFees{}, tronParseError("GetChainFees", err, body)
 	}
 
-	var result map[string]interface{}
+	var fees TronChainFees
+	for _, p := range result.ChainParameter {
+		switch p.Key {
+		case "getEnergyFee":
+			fees.EnergyFee = p.Value
+		case "getTransactionFee":
+			fees.BandwidthFee = p.Value
+		}
+	}
+	return fees, nil
+}
+
+// TronTransactionResult 交易执行结果
+type TronTransactionResult struct {
+	Found   bool   // 是否已上链
+	Success bool   // 合约是否执行成功
+	Fee     int64  // 实际消耗 (sun)
+	Message string // 失败原因
+}
+
+// GetTransactionResult 根据交易 ID 查询执行结果
+func GetTransactionResult(txID string) (TronTransactionResult, error) {
+	var result struct {
+		ID      string `json:"id"`
+		Fee     int64  `json:"fee"`
+		Result  string `json:"result"`
+		ResMsg  string `json:"resMessage"`
+		Receipt struct {
+			Result string `json:"result"`
+		} `json:"receipt"`
+	}
+
+	body, err := tronGridRequest("POST", "/wallet/gettransactioninfobyid", map[string]any{"value": txID})
+	if err != nil {
+		return TronTransactionResult{}, err
+	}
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
+		return TronTransactionResult{}, tronParseError("GetTransactionResult", err, body)
 	}
 
-	return result, nil
+	if result.ID == "" {
+		return TronTransactionResult{}, nil
+	}
+
+	msg, _ := hex.DecodeString(result.ResMsg)
+	return TronTransactionResult{
+		Found:   true,
+		Success: result.Result != "FAILED" && (result.Receipt.Result == "" || result.Receipt.Result == "SUCCESS"),
+		Fee:     result.Fee,
+		Message: string(msg),
+	}, nil
+}
+
+// tronGridRequest TronGrid 通用请求，均为查询类接口，失败可重试
+func tronGridRequest(method, path string, param map[string]any) ([]byte, error) {
+	header := map[string]string{"Accept": "application/json"}
+
+	var body []byte
+	if param != nil {
+		payload, err := json.Marshal(param)
+		if err != nil {
+			return nil, newChannelError(ChannelTronGrid, path, ErrValidation, fmt.Errorf("json marshal error: %w", err))
+		}
+		body = payload
+		header["Content-Type"] = "application/json"
+	}
+	if tronAPIKey != "" {
+		header["TRON-PRO-API-KEY"] = tronAPIKey
+	}
+
+	return channelRequest(context.Background(), ChannelTronGrid, path, &httpclient.Request{
+		Method:     method,
+		URL:        TronGridAPI + path,
+		Header:     header,
+		Body:       body,
+		Idempotent: true,
+	})
+}
+
+// tronParseError TronGrid 返回无法解析
+func tronParseError(op string, err error, body []byte) error {
+	e := newChannelError(ChannelTronGrid, op, ErrChannel, err)
+	e.Message = truncateBody(body)
+	return e
 }
@@ -0,0 +1,425 @@
+package pay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/caoyuewen/components/common/models"
+	"github.com/caoyuewen/components/util"
+	"github.com/caoyuewen/components/util/gen"
+	"github.com/fbsobreira/gotron-sdk/pkg/address"
+	"github.com/fbsobreira/gotron-sdk/pkg/common"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ==================== 归集配置 ====================
+
+const (
+	defaultSweepFeeLimit   = 30_000_000 // 默认手续费上限 30 TRX (sun)
+	defaultSweepEnergy     = 65_000     // USDT transfer 默认能量消耗
+	defaultSweepExpiration = time.Hour  // 默认未签名交易有效期
+	maxSweepExpiration     = 24 * time.Hour
+
+	// 交易带宽 = raw_data + 签名(65) + 结果预留(64) + protobuf 字段头
+	sweepSignatureBytes = 65 + 64 + 5
+)
+
+// SweepConfig 归集配置
+type SweepConfig struct {
+	ColdWallet     string          // 冷钱包地址
+	MinAmount      decimal.Decimal // 余额低于该值不归集
+	FeeLimit       int64           // 手续费上限 (sun)，默认 30 TRX
+	Energy         int64           // 单笔转账能量，默认 65000；EstimateEnergy 为 true 时以链上预估为准
+	Expiration     time.Duration   // 未签名交易有效期，默认 1 小时，最长 24 小时
+	EstimateEnergy bool            // 是否通过 triggerconstantcontract 预估能量
+}
+
+func (c *SweepConfig) setDefault() {
+	if c.FeeLimit <= 0 {
+		c.FeeLimit = defaultSweepFeeLimit
+	}
+	if c.Energy <= 0 {
+		c.Energy = defaultSweepEnergy
+	}
+	if c.Expiration <= 0 {
+		c.Expiration = defaultSweepExpiration
+	}
+	if c.Expiration > maxSweepExpiration {
+		c.Expiration = maxSweepExpiration
+	}
+}
+
+// SweepPlanItem 单个地址的归集计划
+type SweepPlanItem struct {
+	FromAddress        string          `json:"from_address"`
+	ToAddress          string          `json:"to_address"`
+	Amount             decimal.Decimal `json:"amount"`
+	TrxBalance         int64           `json:"trx_balance"`         // 地址 TRX 余额 (sun)
+	EnergyNeeded       int64           `json:"energy_needed"`       // 需要的能量
+	EnergyAvailable    int64           `json:"energy_available"`    // 可用能量
+	BandwidthNeeded    int64           `json:"bandwidth_needed"`    // 需要的带宽
+	BandwidthAvailable int64           `json:"bandwidth_available"` // 可用带宽
+	EstimateFee        int64           `json:"estimate_fee"`        // 资源不足时需燃烧的 TRX (sun)
+	FeeLimit           int64           `json:"fee_limit"`           // 交易中的手续费上限 (sun)
+	Status             int             `json:"status"`              // models.UsdtSweepStatusPlanned / NeedGas
+	TxID               string          `json:"tx_id"`
+	RawDataHex         string          `json:"raw_data_hex"` // 未签名交易 raw_data，离线签名后广播
+	Expiration         int64           `json:"expiration"`
+}
+
+// ==================== 归集计划 ====================
+
+// PlanSweep 检查所有启用地址的 USDT 余额，为超过阈值的地址生成未签名归集交易
+// 已有未完成归集记录、或能量燃烧超过 FeeLimit 的地址会被跳过
+func PlanSweep(cfg SweepConfig) ([]SweepPlanItem, error) {
+	cfg.setDefault()
+
+	if err := models.ValidateTRC20Address(cfg.ColdWallet); err != nil {
+		return nil, fmt.Errorf("%w: cold wallet: %v", ErrValidation, err)
+	}
+
+	addrs, err := models.UsdtAddressActiveList()
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := models.UsdtSweepOpenAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := GetChainFees()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := GetNowBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []SweepPlanItem
+	for _, addr := range addrs {
+		if addr == cfg.ColdWallet || util.Include(open, addr) {
+			continue
+		}
+
+		item, ok, err := planSweepAddress(cfg, fees, block, addr)
+		if err != nil {
+			log.Errorf("PlanSweep address:%s err:%v", addr, err)
+			continue
+		}
+		if ok {
+			res = append(res, item)
+		}
+	}
+
+	return res, nil
+}
+
+// planSweepAddress 生成单个地址的归集计划，余额不足阈值时返回 false
+func planSweepAddress(cfg SweepConfig, fees TronChainFees, block TronBlockRef, addr string) (SweepPlanItem, bool, error) {
+
+	var item SweepPlanItem
+
+	account, err := GetTronAccount(addr)
+	if err != nil {
+		return item, false, err
+	}
+
+	amount := GetUsdtBalance(account)
+	if !amount.IsPositive() || amount.LessThan(cfg.MinAmount) {
+		return item, false, nil
+	}
+
+	resource, err := GetAccountResource(addr)
+	if err != nil {
+		return item, false, err
+	}
+
+	energy := cfg.Energy
+	if cfg.EstimateEnergy {
+		if e, err := EstimateTRC20TransferEnergy(addr, cfg.ColdWallet, amount); err != nil {
+			log.Warnf("PlanSweep estimate energy address:%s err:%v, use default:%d", addr, err, energy)
+		} else {
+			energy = e
+		}
+	}
+
+	expiration := block.Timestamp + cfg.Expiration.Milliseconds()
+	tx, err := BuildTRC20TransferTx(addr, cfg.ColdWallet, amount, cfg.FeeLimit, block, expiration)
+	if err != nil {
+		return item, false, err
+	}
+
+	raw, err := proto.Marshal(tx.RawData)
+	if err != nil {
+		return item, false, err
+	}
+	txID := sha256.Sum256(raw)
+
+	item = SweepPlanItem{
+		FromAddress:        addr,
+		ToAddress:          cfg.ColdWallet,
+		Amount:             amount,
+		TrxBalance:         account.Balance,
+		EnergyNeeded:       energy,
+		EnergyAvailable:    resource.AvailableEnergy(),
+		BandwidthNeeded:    int64(len(raw)) + sweepSignatureBytes,
+		BandwidthAvailable: resource.AvailableBandwidth(),
+		TxID:               hex.EncodeToString(txID[:]),
+		RawDataHex:         hex.EncodeToString(raw),
+		Expiration:         expiration,
+		FeeLimit:           tx.RawData.FeeLimit,
+	}
+	item.EstimateFee = sweepFee(item, fees)
+	if err = checkSweepFeeLimit(item, fees); err != nil {
+		return item, false, err
+	}
+
+	item.Status = models.UsdtSweepStatusPlanned
+	if item.TrxBalance < item.EstimateFee {
+		item.Status = models.UsdtSweepStatusNeedGas
+	}
+
+	return item, true, nil
+}
+
+// sweepFee 计算资源不足时需要燃烧的 TRX
+// 能量按缺口燃烧，带宽不足时整笔交易按字节燃烧
+func sweepFee(item SweepPlanItem, fees TronChainFees) int64 {
+	fee := sweepEnergyFee(item, fees)
+	if item.BandwidthAvailable < item.BandwidthNeeded {
+		fee += item.BandwidthNeeded * fees.BandwidthFee
+	}
+	return fee
+}
+
+// sweepEnergyFee 能量缺口需要燃烧的 TRX
+func sweepEnergyFee(item SweepPlanItem, fees TronChainFees) int64 {
+	if lack := item.EnergyNeeded - item.EnergyAvailable; lack > 0 {
+		return lack * fees.EnergyFee
+	}
+	return 0
+}
+
+// checkSweepFeeLimit 能量燃烧超过交易 fee_limit 时交易会因 OUT_OF_ENERGY 失败且仍扣除手续费，不生成计划
+// fee_limit 只约束能量燃烧，带宽燃烧不计入
+func checkSweepFeeLimit(item SweepPlanItem, fees TronChainFees) error {
+	if burn := sweepEnergyFee(item, fees); burn > item.FeeLimit {
+		return fmt.Errorf("%w: energy burn %d sun exceeds fee limit %d sun", ErrValidation, burn, item.FeeLimit)
+	}
+	return nil
+}
+
+// SaveSweepPlan 保存归集计划，返回归集记录
+func SaveSweepPlan(items []SweepPlanItem) ([]models.UsdtSweep, error) {
+
+	now := time.Now().UnixMilli()
+	list := make([]models.UsdtSweep, 0, len(items))
+	for _, v := range items {
+		list = append(list, models.UsdtSweep{
+			ID:          gen.IdString(),
+			FromAddress: v.FromAddress,
+			ToAddress:   v.ToAddress,
+			Amount:      v.Amount.String(),
+			TxID:        v.TxID,
+			RawDataHex:  v.RawDataHex,
+			Expiration:  v.Expiration,
+			FeeLimit:    v.FeeLimit,
+			EstimateFee: v.EstimateFee,
+			Status:      v.Status,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+
+	if err := models.UsdtSweepRepo.InsertBatch(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// RefreshSweepStatus 检查已广播的归集交易，更新为成功或失败；作废已过期未签名的归集
+// 待补充手续费的归集在地址 TRX 余额足够后恢复为待签名
+func RefreshSweepStatus() error {
+
+	broadcast, err := models.UsdtSweepListByStatus(models.UsdtSweepStatusBroadcast)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range broadcast {
+		result, err := GetTransactionResult(v.TxID)
+		if err != nil {
+			log.Errorf("RefreshSweepStatus id:%s tx:%s err:%v", v.ID, v.TxID, err)
+			continue
+		}
+		if !result.Found {
+			continue
+		}
+		if result.Success {
+			_, err = models.UsdtSweepMarkSuccess(v.ID, result.Fee)
+		} else {
+			_, err = models.UsdtSweepMarkFailed(v.ID, result.Fee, util.Truncate(result.Message, 255))
+		}
+		if err != nil {
+			log.Errorf("RefreshSweepStatus update id:%s err:%v", v.ID, err)
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	for _, status := range []int{models.UsdtSweepStatusPlanned, models.UsdtSweepStatusNeedGas} {
+		list, err := models.UsdtSweepListByStatus(status)
+		if err != nil {
+			return err
+		}
+		for _, v := range list {
+			if v.Expiration > 0 && v.Expiration < now {
+				if _, err := models.UsdtSweepCancel(v.ID, "expired before broadcast"); err != nil {
+					log.Errorf("RefreshSweepStatus cancel id:%s err:%v", v.ID, err)
+				}
+				continue
+			}
+			if status == models.UsdtSweepStatusNeedGas {
+				refreshSweepGas(v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// refreshSweepGas 地址 TRX 余额已覆盖预估手续费时，将归集恢复为待签名
+func refreshSweepGas(v models.UsdtSweep) {
+	account, err := GetTronAccount(v.FromAddress)
+	if err != nil {
+		log.Errorf("RefreshSweepStatus gas id:%s address:%s err:%v", v.ID, v.FromAddress, err)
+		return
+	}
+	if account.Balance < v.EstimateFee {
+		return
+	}
+	if _, err := models.UsdtSweepMarkGasReady(v.ID); err != nil {
+		log.Errorf("RefreshSweepStatus gas ready id:%s err:%v", v.ID, err)
+	}
+}
+
+// ==================== 交易构造 ====================
+
+// trc20TransferSelector transfer(address,uint256) 方法签名
+var trc20TransferSelector = common.Keccak256([]byte("transfer(address,uint256)"))[:4]
+
+// trc20TransferData 构造 TRC20 transfer 调用数据
+func trc20TransferData(to string, amount decimal.Decimal) ([]byte, error) {
+	toAddr, err := address.Base58ToAddress(to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to address: %v", ErrValidation, err)
+	}
+	if amount.IsNegative() {
+		return nil, fmt.Errorf("%w: amount must not be negative", ErrValidation)
+	}
+
+	value := amount.Shift(UsdtDecimals).BigInt()
+
+	data := make([]byte, 0, 4+32+32)
+	data = append(data, trc20TransferSelector...)
+	data = append(data, common.LeftPadBytes(toAddr.Bytes()[1:], 32)...) // 去掉 0x41 前缀
+	data = append(data, common.LeftPadBytes(value.Bytes(), 32)...)
+	return data, nil
+}
+
+// BuildTRC20TransferTx 构造未签名的 USDT 转账交易
+func BuildTRC20TransferTx(from, to string, amount decimal.Decimal, feeLimit int64, block TronBlockRef, expiration int64) (*core.Transaction, error) {
+
+	owner, err := address.Base58ToAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from address: %v", ErrValidation, err)
+	}
+	contract, err := address.Base58ToAddress(UsdtContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := trc20TransferData(to, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	param, err := anypb.New(&core.TriggerSmartContract{
+		OwnerAddress:    owner.Bytes(),
+		ContractAddress: contract.Bytes(),
+		Data:            data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	blockHash, err := hex.DecodeString(block.BlockID)
+	if err != nil || len(blockHash) < 16 {
+		return nil, fmt.Errorf("%w: invalid block id: %s", ErrValidation, block.BlockID)
+	}
+
+	// ref_block_bytes 取区块高度的第 6~8 字节，ref_block_hash 取区块哈希的第 8~16 字节
+	num := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		num[i] = byte(block.Number >> (8 * (7 - i)))
+	}
+
+	return &core.Transaction{
+		RawData: &core.TransactionRaw{
+			RefBlockBytes: num[6:8],
+			RefBlockHash:  blockHash[8:16],
+			Expiration:    expiration,
+			Timestamp:     block.Timestamp,
+			FeeLimit:      feeLimit,
+			Contract: []*core.Transaction_Contract{{
+				Type:      core.Transaction_Contract_TriggerSmartContract,
+				Parameter: param,
+			}},
+		},
+	}, nil
+}
+
+// EstimateTRC20TransferEnergy 通过 triggerconstantcontract 预估转账能量
+func EstimateTRC20TransferEnergy(from, to string, amount decimal.Decimal) (int64, error) {
+
+	data, err := trc20TransferData(to, amount)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := tronGridRequest("POST", "/wallet/triggerconstantcontract", map[string]any{
+		"owner_address":     from,
+		"contract_address":  UsdtContractAddress,
+		"function_selector": "transfer(address,uint256)",
+		"parameter":         hex.EncodeToString(data[4:]),
+		"visible":           true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		EnergyUsed int64 `json:"energy_used"`
+		Result     struct {
+			Result  bool   `json:"result"`
+			Message string `json:"message"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, tronParseError("EstimateTRC20TransferEnergy", err, body)
+	}
+	if !result.Result.Result || result.EnergyUsed <= 0 {
+		msg, _ := hex.DecodeString(result.Result.Message)
+		return 0, codeError(ChannelTronGrid, "EstimateTRC20TransferEnergy", ErrChannel, "", string(msg))
+	}
+	return result.EnergyUsed, nil
+}
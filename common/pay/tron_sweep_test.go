@@ -0,0 +1,87 @@
+package pay
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTrc20TransferData(t *testing.T) {
+
+	data, err := trc20TransferData("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", decimal.RequireFromString("1.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "a9059cbb" +
+		"000000000000000000000000a614f803b6fd780986a42c78ec9c7f77e6ded13c" +
+		"000000000000000000000000000000000000000000000000000000000016e360"
+	if got := hex.EncodeToString(data); got != want {
+		t.Fatalf("data = %s, want %s", got, want)
+	}
+}
+
+func TestBuildTRC20TransferTx(t *testing.T) {
+
+	block := TronBlockRef{
+		BlockID:   "0000000003d9f0a1c2b3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f6071829",
+		Number:    0x03d9f0a1,
+		Timestamp: 1700000000000,
+	}
+
+	tx, err := BuildTRC20TransferTx("TPT2MUXWRvpy17MPcVdLTfAg38rocspzxB", "TMykpgR4V51s3qDMvk8ztdEFZJjCnm5kcZ",
+		decimal.NewFromInt(10), defaultSweepFeeLimit, block, block.Timestamp+60_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := hex.EncodeToString(tx.RawData.RefBlockBytes); got != "f0a1" {
+		t.Fatalf("ref_block_bytes = %s, want f0a1", got)
+	}
+	if got := hex.EncodeToString(tx.RawData.RefBlockHash); got != "c2b3d4e5f6071829" {
+		t.Fatalf("ref_block_hash = %s, want c2b3d4e5f6071829", got)
+	}
+	if tx.RawData.FeeLimit != defaultSweepFeeLimit || len(tx.RawData.Contract) != 1 {
+		t.Fatalf("unexpected raw data: %+v", tx.RawData)
+	}
+}
+
+func TestSweepFee(t *testing.T) {
+
+	fees := TronChainFees{EnergyFee: 100, BandwidthFee: 1000}
+
+	item := SweepPlanItem{
+		EnergyNeeded: 65000, EnergyAvailable: 15000,
+		BandwidthNeeded: 345, BandwidthAvailable: 600,
+	}
+	if fee := sweepFee(item, fees); fee != 50000*100 {
+		t.Fatalf("fee = %d, want %d", fee, 50000*100)
+	}
+
+	item.BandwidthAvailable = 100
+	if fee := sweepFee(item, fees); fee != 50000*100+345*1000 {
+		t.Fatalf("fee = %d, want %d", fee, 50000*100+345*1000)
+	}
+}
+
+func TestCheckSweepFeeLimit(t *testing.T) {
+
+	fees := TronChainFees{EnergyFee: 100, BandwidthFee: 1000}
+
+	item := SweepPlanItem{EnergyNeeded: 65000, FeeLimit: 10_000_000}
+	if err := checkSweepFeeLimit(item, fees); err != nil {
+		t.Fatalf("burn 6.5 TRX within 10 TRX limit: %v", err)
+	}
+
+	item.FeeLimit = 5_000_000
+	if err := checkSweepFeeLimit(item, fees); !errors.Is(err, ErrValidation) {
+		t.Fatalf("burn over fee limit should be rejected, got %v", err)
+	}
+
+	item.EnergyAvailable = 20000
+	if err := checkSweepFeeLimit(item, fees); err != nil {
+		t.Fatalf("burn 4.5 TRX within 5 TRX limit: %v", err)
+	}
+}
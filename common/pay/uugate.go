@@ -1,16 +1,16 @@
 package pay
 
 import (
-	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/caoyuewen/components/util/httpclient"
 	"github.com/shopspring/decimal"
 )
 
@@ -55,11 +55,17 @@ func InitUugate(env string) {
 	}
 
 	payment := Payment{
-		Name:        "uugate",
+		Name:        ChannelUugate,
 		PayService:  uugateService,
 		PaymentType: PayTypeUsdt,
 	}
 
+	configureChannelHost(uugateService.Domain, httpclient.HostConfig{
+		Timeout:          10 * time.Second,
+		BreakerThreshold: 5,
+		LogBody:          true,
+	})
+
 	paymentRegister(payment)
 }
 
@@ -146,7 +152,7 @@ func (that *Uugate) CallDeposit(id, amount string) (CallDepositResult, error) {
 	amountDec, err := decimal.NewFromString(amount)
 	if err != nil {
 		fmt.Printf("UugateCallDepositErr:amount err,id:%s,amount:%s,err:%s \n", id, amount, err.Error())
-		return resp, err
+		return resp, newChannelError(ChannelUugate, "CallDeposit", ErrValidation, err)
 	}
 
 	// 1.构造 uugate 请求体
@@ -171,7 +177,7 @@ func (that *Uugate) CallDeposit(id, amount string) (CallDepositResult, error) {
 	fmt.Printf("UugateCallDeposit id:%s,url:%s,req:%s\n", id, url, string(payload))
 
 	// 2.向 uugate 发送充值请求
-	respBytes, err := that.sendRequest(url, req)
+	respBytes, err := that.sendRequest(url, req, false)
 	if err != nil {
 		fmt.Printf("UugateCallDepositErr sendRequest id:%s,url:%s,req:%s\n", id, url, string(payload))
 		return resp, err
@@ -185,13 +191,13 @@ func (that *Uugate) CallDeposit(id, amount string) (CallDepositResult, error) {
 	if err != nil {
 		fmt.Printf("UugateCallDepositErr:JsonUnmarshal err, id:%s,url:%s,req:%s resp:%s \n",
 			id, url, string(payload), string(respBytes))
-		return resp, err
+		return resp, newChannelError(ChannelUugate, "CallDeposit", ErrChannel, err)
 	}
 
 	if !(payResp.Code == 0 && payResp.Msg == "success") {
 		fmt.Printf("UugateCallDepositErr:status err, id:%s,url:%s,req:%s resp:%s \n",
 			id, url, string(payload), string(respBytes))
-		return resp, fmt.Errorf("%s", payResp.Msg)
+		return resp, codeError(ChannelUugate, "CallDeposit", ErrChannel, strconv.Itoa(payResp.Code), payResp.Msg)
 	}
 
 	// 4.封装到通用返回
@@ -233,7 +239,7 @@ func (that *Uugate) CallDepositOrderQuery(orderId, externalOrderId string) (Paym
 		resp.Status = OrderStatusSuccess
 		// todo finishTime
 	default:
-		return resp, errors.New("unknown status:" + uugateResp.ReceiveOrder.Status)
+		return resp, codeError(ChannelUugate, "CallDepositOrderQuery", ErrChannel, "", "unknown status:"+uugateResp.ReceiveOrder.Status)
 	}
 
 	resp.OrderNo = uugateResp.ReceiveOrder.CustomerOrderNo
@@ -271,7 +277,7 @@ func (that *Uugate) getReceiveOrderStatus(orderId string) (uugateDepositQueryRes
 
 	// 2.向 uugate 发送请求
 
-	bytesRes, err := that.sendRequest(url, req)
+	bytesRes, err := that.sendRequest(url, req, true)
 	if err != nil {
 		fmt.Printf("UugateCallDepositOrderQueryErr id:%s,url:%s,req:%s\n", orderId, url, string(payload))
 		return resp, err
@@ -283,13 +289,13 @@ func (that *Uugate) getReceiveOrderStatus(orderId string) (uugateDepositQueryRes
 	if err != nil {
 		fmt.Printf("UugateCallDepositOrderQueryErr:JsonUnmarshal err, id:%s,url:%s,req:%s resp:%s \n",
 			orderId, url, string(payload), string(bytesRes))
-		return resp, err
+		return resp, newChannelError(ChannelUugate, "CallDepositOrderQuery", ErrChannel, err)
 	}
 
 	if !(resp.Code == 0 && resp.Msg == "success") {
 		fmt.Printf("UugateCallDepositOrderQueryErr:status err, id:%s,url:%s,req:%s resp:%s \n",
 			orderId, url, string(payload), string(bytesRes))
-		return resp, fmt.Errorf("%s", resp.Msg)
+		return resp, codeError(ChannelUugate, "CallDepositOrderQuery", ErrChannel, strconv.Itoa(resp.Code), resp.Msg)
 	}
 
 	return resp, nil
@@ -303,25 +309,19 @@ func (that *Uugate) sign(req UugateFd) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func (that *Uugate) sendRequest(url string, params any) ([]byte, error) {
-
-	bodyBytes, _ := json.Marshal(params)
-
-	req, err := http.NewRequest("POST", url, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("create request error: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+// sendRequest 统一的请求，idempotent 为 true 的查询类请求失败时会重试
+func (that *Uugate) sendRequest(url string, params any, idempotent bool) ([]byte, error) {
 
-	// 发送请求
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	bodyBytes, err := json.Marshal(params)
 	if err != nil {
-		return nil, fmt.Errorf("request error: %w", err)
+		return nil, newChannelError(ChannelUugate, "POST", ErrValidation, fmt.Errorf("json marshal error: %w", err))
 	}
-	defer resp.Body.Close()
 
-	// 读取响应
-	respBytes, _ := io.ReadAll(resp.Body)
-	return respBytes, nil
+	return channelRequest(context.Background(), ChannelUugate, "POST", &httpclient.Request{
+		Method:     http.MethodPost,
+		URL:        url,
+		Header:     map[string]string{"Content-Type": "application/json"},
+		Body:       bodyBytes,
+		Idempotent: idempotent,
+	})
 }
@@ -2,7 +2,7 @@ package pay
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"time"
 
 	"github.com/go-pay/gopay"
@@ -34,13 +34,13 @@ func NewWechatService(config *WechatConfig) (*WechatService, error) {
 	// 创建微信支付 V3 客户端
 	client, err := wechat.NewClientV3(config.MchID, config.SerialNo, config.APIv3Key, config.PrivateKey)
 	if err != nil {
-		return nil, fmt.Errorf("create wechat client error: %v", err)
+		return nil, newChannelError(ChannelWechat, "NewClient", ErrAuth, err)
 	}
 
 	// 自动验签
 	err = client.AutoVerifySign()
 	if err != nil {
-		return nil, fmt.Errorf("auto verify sign error: %v", err)
+		return nil, newChannelError(ChannelWechat, "AutoVerifySign", ErrAuth, err)
 	}
 
 	return &WechatService{
@@ -88,11 +88,11 @@ func (s *WechatService) CreateNativePayOrder(req *WechatOrderRequest) (*WechatOr
 
 	resp, err := s.client.V3TransactionNative(context.Background(), bm)
 	if err != nil {
-		return nil, fmt.Errorf("wechat native paycalback error: %v", err)
+		return nil, transportError(ChannelWechat, "CreateNativePayOrder", err)
 	}
 
 	if resp.Code != wechat.Success {
-		return nil, fmt.Errorf("wechat error: %s", resp.Error)
+		return nil, wechatError("CreateNativePayOrder", resp.Code, resp.Error)
 	}
 
 	return &WechatOrderResponse{
@@ -126,11 +126,11 @@ func (s *WechatService) CreateH5PayOrder(req *WechatOrderRequest) (*WechatOrderR
 
 	resp, err := s.client.V3TransactionH5(context.Background(), bm)
 	if err != nil {
-		return nil, fmt.Errorf("wechat h5 paycalback error: %v", err)
+		return nil, transportError(ChannelWechat, "CreateH5PayOrder", err)
 	}
 
 	if resp.Code != wechat.Success {
-		return nil, fmt.Errorf("wechat error: %s", resp.Error)
+		return nil, wechatError("CreateH5PayOrder", resp.Code, resp.Error)
 	}
 
 	return &WechatOrderResponse{
@@ -143,7 +143,7 @@ func (s *WechatService) CreateH5PayOrder(req *WechatOrderRequest) (*WechatOrderR
 // CreateJSAPIPayOrder 创建 JSAPI 支付订单 (公众号/小程序)
 func (s *WechatService) CreateJSAPIPayOrder(req *WechatOrderRequest) (*WechatOrderResponse, error) {
 	if req.OpenID == "" {
-		return nil, fmt.Errorf("openid is required for JSAPI paycalback")
+		return nil, codeError(ChannelWechat, "CreateJSAPIPayOrder", ErrValidation, "", "openid is required for JSAPI payment")
 	}
 
 	expire := time.Now().Add(30 * time.Minute).Format(time.RFC3339)
@@ -165,17 +165,17 @@ func (s *WechatService) CreateJSAPIPayOrder(req *WechatOrderRequest) (*WechatOrd
 
 	resp, err := s.client.V3TransactionJsapi(context.Background(), bm)
 	if err != nil {
-		return nil, fmt.Errorf("wechat jsapi paycalback error: %v", err)
+		return nil, transportError(ChannelWechat, "CreateJSAPIPayOrder", err)
 	}
 
 	if resp.Code != wechat.Success {
-		return nil, fmt.Errorf("wechat error: %s", resp.Error)
+		return nil, wechatError("CreateJSAPIPayOrder", resp.Code, resp.Error)
 	}
 
 	// 生成 JSAPI 调起参数
 	jsapi, err := s.client.PaySignOfJSAPI(s.config.AppID, resp.Response.PrepayId)
 	if err != nil {
-		return nil, fmt.Errorf("generate jsapi sign error: %v", err)
+		return nil, newChannelError(ChannelWechat, "CreateJSAPIPayOrder", ErrAuth, err)
 	}
 
 	return &WechatOrderResponse{
@@ -203,11 +203,11 @@ type WechatQueryResult struct {
 func (s *WechatService) QueryOrder(orderNo string) (*WechatQueryResult, error) {
 	resp, err := s.client.V3TransactionQueryOrder(context.Background(), wechat.OutTradeNo, orderNo)
 	if err != nil {
-		return nil, fmt.Errorf("wechat query order error: %v", err)
+		return nil, transportError(ChannelWechat, "QueryOrder", err)
 	}
 
 	if resp.Code != wechat.Success {
-		return nil, fmt.Errorf("wechat error: %s", resp.Error)
+		return nil, wechatError("QueryOrder", resp.Code, resp.Error)
 	}
 
 	isPaid := resp.Response.TradeState == "SUCCESS"
@@ -245,7 +245,7 @@ func (s *WechatService) VerifyNotify(notifyReq *wechat.V3NotifyReq) (*WechatNoti
 	// 解密通知内容
 	result, err := notifyReq.DecryptPayCipherText(s.config.APIv3Key)
 	if err != nil {
-		return nil, fmt.Errorf("decrypt notify error: %v", err)
+		return nil, newChannelError(ChannelWechat, "VerifyNotify", ErrAuth, err)
 	}
 
 	notify := &WechatNotifyResult{
@@ -269,16 +269,46 @@ func (s *WechatService) VerifyNotify(notifyReq *wechat.V3NotifyReq) (*WechatNoti
 func (s *WechatService) CloseOrder(orderNo string) error {
 	resp, err := s.client.V3TransactionCloseOrder(context.Background(), orderNo)
 	if err != nil {
-		return fmt.Errorf("wechat close order error: %v", err)
+		return transportError(ChannelWechat, "CloseOrder", err)
 	}
 
 	if resp.Code != wechat.Success {
-		return fmt.Errorf("wechat error: %s", resp.Error)
+		return wechatError("CloseOrder", resp.Code, resp.Error)
 	}
 
 	return nil
 }
 
+// ==================== 错误转换 ====================
+
+// wechatError 按 HTTP 状态码与微信错误码归类
+// https://pay.weixin.qq.com/docs/merchant/development/interface-rules/error-code.html
+func wechatError(op string, status int, body string) error {
+	var r struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	e := &ChannelError{Channel: ChannelWechat, Op: op, Kind: kindOfStatus(status), HTTPStatus: status, Message: body}
+	if json.Unmarshal([]byte(body), &r) == nil && r.Code != "" {
+		e.Code = r.Code
+		e.Message = r.Message
+	}
+
+	switch e.Code {
+	case "ORDER_NOT_EXIST", "RESOURCE_NOT_EXISTS":
+		e.Kind = ErrNotFound
+	case "SIGN_ERROR":
+		e.Kind = ErrAuth
+	case "SYSTEM_ERROR", "FREQUENCY_LIMITED":
+		e.Kind = ErrRetryable
+	case "PARAM_ERROR", "INVALID_REQUEST":
+		e.Kind = ErrValidation
+	case "ORDERPAID", "ORDER_CLOSED":
+		e.Kind = ErrConflict
+	}
+	return e
+}
+
 // ==================== 全局实例 ====================
 
 var wechatService *WechatService
@@ -0,0 +1,62 @@
+package dbmongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// indexDecl 仓库声明的索引
+type indexDecl struct {
+	database   string
+	collection string
+	models     []mongo.IndexModel
+}
+
+var (
+	indexMu    sync.Mutex
+	indexDecls []indexDecl
+)
+
+// registerIndexes 登记索引，已连接时立即创建
+func registerIndexes(database, collection string, models []mongo.IndexModel) {
+	decl := indexDecl{database: database, collection: collection, models: models}
+
+	indexMu.Lock()
+	indexDecls = append(indexDecls, decl)
+	indexMu.Unlock()
+
+	if !IsInitialized() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := decl.ensure(ctx); err != nil {
+		log.Errorf("[MONGO] ensure indexes on %s err: %s", collection, err.Error())
+	}
+}
+
+// EnsureIndexes 创建全部仓库声明的索引，已存在的同名同定义索引会被忽略
+func EnsureIndexes(ctx context.Context) error {
+	indexMu.Lock()
+	decls := append([]indexDecl(nil), indexDecls...)
+	indexMu.Unlock()
+
+	var errs []error
+	for _, decl := range decls {
+		if err := decl.ensure(ctx); err != nil {
+			log.Errorf("[MONGO] ensure indexes on %s err: %s", decl.collection, err.Error())
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d indexDecl) ensure(ctx context.Context) error {
+	_, err := Database(d.database).Collection(d.collection).Indexes().CreateMany(ctx, d.models)
+	return err
+}
@@ -2,87 +2,407 @@ package dbmongo
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
 
 var (
-	client *mongo.Client
-	once   sync.Once
-	mu     sync.Mutex
+	client          atomic.Pointer[mongo.Client]
+	defaultDatabase atomic.Value // string
+	initialized     atomic.Bool
+	startMu         sync.Mutex
+	stop            chan struct{}
+	cfg             MongoInfo
+	pool            poolStats
 )
 
+// MongoInfo MongoDB 配置信息
 type MongoInfo struct {
-	Address  string
-	User     string
-	Password string
+	Address  string // 地址 host:port，多个用逗号分隔
+	User     string // 用户名，为空时不认证
+	Password string // 密码
+	Database string // 默认数据库，仓库未指定数据库时使用
+
+	// 完整连接串 (可选)，设置后先应用连接串，再由下列非零字段覆盖
+	URI string
+
+	AuthSource     string      // 认证库，默认 admin
+	ReplicaSet     string      // 副本集名称
+	TLS            bool        // 是否启用 TLS
+	TLSConfig      *tls.Config // 自定义 TLS 配置，设置后 TLS 视为启用
+	ReadPreference string      // 读偏好 primary/primaryPreferred/secondary/secondaryPreferred/nearest，默认 primary
+
+	// 连接池与超时配置（可选）
+	MaxPoolSize            uint64        // 最大连接数，默认 100
+	MinPoolSize            uint64        // 最小连接数，默认 0
+	MaxConnIdleTime        time.Duration // 空闲连接最大生命周期，默认 10m
+	ConnectTimeout         time.Duration // 建立连接超时，默认 10s
+	ServerSelectionTimeout time.Duration // 选择服务器超时，默认 10s
+	Timeout                time.Duration // 单次操作超时 (ctx 无截止时间时生效)，默认 0 不限制
 }
 
-// StartUp initializes the global MongoDB client or cluster client with the provided options and starts the connection checker
-func StartUp(info MongoInfo, checkInterval time.Duration) {
-	uri := "mongodb://" + info.User + ":" + info.Password + "@" + info.Address
-	StartUpByUri(uri, checkInterval)
+// setDefault 设置默认配置，使用连接串时不设置，以连接串参数为准
+func (info *MongoInfo) setDefault() {
+	if info.URI != "" {
+		return
+	}
+	if info.MaxPoolSize == 0 {
+		info.MaxPoolSize = 100
+	}
+	if info.MaxConnIdleTime <= 0 {
+		info.MaxConnIdleTime = 10 * time.Minute
+	}
+	if info.ConnectTimeout <= 0 {
+		info.ConnectTimeout = 10 * time.Second
+	}
+	if info.ServerSelectionTimeout <= 0 {
+		info.ServerSelectionTimeout = 10 * time.Second
+	}
 }
 
-// StartUpByUri initializes the global MongoDB client or cluster client with the provided options and starts the connection checker
-func StartUpByUri(uri string, checkInterval time.Duration) {
-	var err error
-	once.Do(func() {
-		client, err = mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+// clientOptions 生成驱动配置，用户名密码通过 Credential 传递，无需转义
+func (info *MongoInfo) clientOptions() (*options.ClientOptions, error) {
+	opts := options.Client()
+	if info.URI != "" {
+		opts.ApplyURI(info.URI)
+		if err := opts.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if info.Address != "" {
+		hosts := strings.Split(info.Address, ",")
+		for i := range hosts {
+			hosts[i] = strings.TrimSpace(hosts[i])
+		}
+		opts.SetHosts(hosts)
+	}
+	if info.User != "" {
+		authSource := info.AuthSource
+		if authSource == "" {
+			authSource = "admin"
+		}
+		opts.SetAuth(options.Credential{Username: info.User, Password: info.Password, AuthSource: authSource})
+	} else if info.AuthSource != "" && opts.Auth != nil {
+		opts.Auth.AuthSource = info.AuthSource
+	}
+	if info.ReplicaSet != "" {
+		opts.SetReplicaSet(info.ReplicaSet)
+	}
+	if info.TLSConfig != nil {
+		opts.SetTLSConfig(info.TLSConfig)
+	} else if info.TLS {
+		opts.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	if info.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(info.ReadPreference)
 		if err != nil {
-			log.Error("[MONGO] Failed to initialize MongoDB: " + err.Error())
-			return
+			return nil, err
 		}
-		if err = client.Ping(context.Background(), nil); err != nil {
-			log.Error("[MONGO] Failed to ping MongoDB: " + err.Error())
-			return
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
 		}
-		log.Info("[MONGO] MongoDB initialized success")
-		go connectionChecker(uri, checkInterval)
+		opts.SetReadPreference(rp)
+	}
 
-	})
-	return
+	opts.SetPoolMonitor(pool.monitor())
+	if info.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(info.MaxPoolSize)
+	}
+	if info.MinPoolSize > 0 {
+		opts.SetMinPoolSize(info.MinPoolSize)
+	}
+	if info.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(info.MaxConnIdleTime)
+	}
+	if info.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(info.ConnectTimeout)
+	}
+	if info.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(info.ServerSelectionTimeout)
+	}
+	if info.Timeout > 0 {
+		opts.SetTimeout(info.Timeout)
+	}
+	return opts, nil
 }
 
-// connectionChecker periodically checks the MongoDB connection and reconnects if necessary
-func connectionChecker(uri string, checkInterval time.Duration) {
-	ticker := time.NewTicker(checkInterval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		if err := client.Ping(context.Background(), nil); err != nil {
-			log.Warn("[MONGO] MongoDB connection lost, attempting to reconnect...")
-			reconnect(uri)
+// database 配置的默认库：优先 Database 字段，其次连接串中的库名
+func (info *MongoInfo) database() string {
+	if info.Database != "" {
+		return info.Database
+	}
+	if info.URI != "" {
+		if cs, err := connstring.ParseAndValidate(info.URI); err == nil {
+			return cs.Database
 		}
 	}
+	return ""
 }
 
-// reconnect attempts to reconnect to MongoDB
-func reconnect(uri string) {
-	mu.Lock()
-	defer mu.Unlock()
+// ==================== 启动与关闭 ====================
+
+// StartUp 初始化全局 MongoDB 客户端，连接并 Ping 成功后启动健康检查并创建仓库声明的索引
+// checkInterval <= 0 时不做健康检查；已初始化时直接返回 nil
+// 索引创建失败 (如唯一索引遇到已有重复数据) 时返回错误，此时客户端已初始化可用
+func StartUp(info MongoInfo, checkInterval time.Duration) error {
+	startMu.Lock()
+	defer startMu.Unlock()
+
+	if initialized.Load() {
+		log.Warn("[MONGO] StartUp called more than once, ignored")
+		return nil
+	}
+
+	info.setDefault()
+	c, err := connect(info)
+	if err != nil {
+		log.Errorf("[MONGO] Failed to initialize MongoDB: %v", err)
+		return err
+	}
+
+	cfg = info
+	defaultDatabase.Store(info.database())
+	client.Store(c)
+	initialized.Store(true)
+	log.Infof("[MONGO] MongoDB initialized success, database:%s", info.database())
+
+	stop = make(chan struct{})
+	if checkInterval > 0 {
+		go connectionChecker(checkInterval, stop)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err = EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("[MONGO] ensure indexes: %w", err)
+	}
+	return nil
+}
+
+// StartUpByUri 使用连接串初始化
+func StartUpByUri(uri string, checkInterval time.Duration) error {
+	return StartUp(MongoInfo{URI: uri}, checkInterval)
+}
+
+// connect 建立连接并 Ping
+func connect(info MongoInfo) (*mongo.Client, error) {
+	opts, err := info.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := info.ConnectTimeout + info.ServerSelectionTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.Ping(ctx, nil); err != nil {
+		_ = c.Disconnect(context.Background())
+		return nil, fmt.Errorf("ping: %w", err)
+	}
+	return c, nil
+}
+
+// Close 停止健康检查并断开连接
+func Close() error {
+	startMu.Lock()
+	defer startMu.Unlock()
+
+	if !initialized.Load() {
+		return nil
+	}
+	close(stop)
+	initialized.Store(false)
+
+	c := client.Swap(nil)
+	if c == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return c.Disconnect(ctx)
+}
+
+// ==================== 健康检查 ====================
+
+// connectionChecker 定期检查连接，Ping 失败时重建客户端
+// 驱动本身会自动重连，这里处理客户端整体失效 (如 DNS/拓扑变化) 的情况
+func connectionChecker(checkInterval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
 
 	for {
-		newClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
-		if err == nil {
-			if err = newClient.Ping(context.Background(), nil); err == nil {
-				client = newClient
-				log.Info("[MONGO] Successfully reconnected to MongoDB")
-				break
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !IsConnected() {
+				reconnect()
 			}
 		}
-		log.Error("[MONGO] Reconnect failed, retrying... " + err.Error())
-		time.Sleep(5 * time.Second) // wait before retrying
 	}
 }
 
-// Client returns the global MongoDB client instance
+// reconnect 新建客户端并替换，旧客户端延迟断开以便进行中的操作完成
+func reconnect() {
+	log.Warn("[MONGO] MongoDB connection lost, attempting to reconnect...")
+
+	startMu.Lock()
+	info := cfg
+	startMu.Unlock()
+
+	c, err := connect(info)
+	if err != nil {
+		log.Errorf("[MONGO] Reconnect failed: %v", err)
+		return
+	}
+
+	startMu.Lock()
+	defer startMu.Unlock()
+	if !initialized.Load() {
+		// 期间已 Close
+		_ = c.Disconnect(context.Background())
+		return
+	}
+
+	old := client.Swap(c)
+	log.Info("[MONGO] Successfully reconnected to MongoDB")
+	if old != nil {
+		time.AfterFunc(time.Minute, func() {
+			_ = old.Disconnect(context.Background())
+		})
+	}
+}
+
+// ==================== 客户端访问 ====================
+
+// ErrNotInitialized 客户端未初始化
+var ErrNotInitialized = errors.New("dbmongo: client not initialized, call StartUp first")
+
+// Client 获取全局 MongoDB 客户端，未初始化或已 Close 时 panic；需要判空的调用方使用 TryClient
 func Client() *mongo.Client {
-	mu.Lock()
-	defer mu.Unlock()
-	return client
+	c := client.Load()
+	if c == nil {
+		panic(ErrNotInitialized.Error())
+	}
+	return c
+}
+
+// TryClient 获取全局 MongoDB 客户端，未初始化或已 Close 时返回 false，不会 panic
+func TryClient() (*mongo.Client, bool) {
+	c := client.Load()
+	return c, c != nil
+}
+
+// Database 获取数据库，name 为空时使用默认数据库
+func Database(name string) *mongo.Database {
+	if name == "" {
+		name, _ = defaultDatabase.Load().(string)
+	}
+	return Client().Database(name)
+}
+
+// IsInitialized 返回是否已初始化
+func IsInitialized() bool {
+	return initialized.Load()
+}
+
+// IsConnected 检测连接是否正常，按配置的读偏好选择节点 Ping，避免主节点选举期间误判断线
+func IsConnected() bool {
+	c := client.Load()
+	if c == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := c.Ping(ctx, nil); err != nil {
+		log.Errorf("[MONGO] Connection lost: %v", err)
+		return false
+	}
+	return true
+}
+
+// Stats 获取连接池统计信息
+func Stats() map[string]interface{} {
+	startMu.Lock()
+	maxPoolSize := cfg.MaxPoolSize
+	startMu.Unlock()
+
+	if !initialized.Load() {
+		return nil
+	}
+	return pool.stats(maxPoolSize)
+}
+
+// ==================== 连接池统计 ====================
+
+// poolStats 由驱动连接池事件累计的统计
+type poolStats struct {
+	open         atomic.Int64
+	inUse        atomic.Int64
+	created      atomic.Int64
+	closed       atomic.Int64
+	checkOuts    atomic.Int64
+	checkOutFail atomic.Int64
+	cleared      atomic.Int64
+}
+
+func (p *poolStats) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				p.open.Add(1)
+				p.created.Add(1)
+			case event.ConnectionClosed:
+				p.open.Add(-1)
+				p.closed.Add(1)
+			case event.GetSucceeded:
+				p.inUse.Add(1)
+				p.checkOuts.Add(1)
+			case event.GetFailed:
+				p.checkOutFail.Add(1)
+			case event.ConnectionReturned:
+				p.inUse.Add(-1)
+			case event.PoolCleared:
+				p.cleared.Add(1)
+			}
+		},
+	}
+}
+
+func (p *poolStats) stats(maxPoolSize uint64) map[string]interface{} {
+	open, inUse := p.open.Load(), p.inUse.Load()
+	return map[string]interface{}{
+		"max_pool_size":    maxPoolSize,
+		"open_connections": open,
+		"in_use":           inUse,
+		"idle":             max(open-inUse, 0),
+		"created":          p.created.Load(),
+		"closed":           p.closed.Load(),
+		"check_outs":       p.checkOuts.Load(),
+		"check_out_failed": p.checkOutFail.Load(),
+		"pool_cleared":     p.cleared.Load(),
+	}
 }
@@ -0,0 +1,52 @@
+package dbmongo
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestClientOptions(t *testing.T) {
+	info := MongoInfo{Address: "a:27017, b:27017", User: "u", Password: "p@ss:/w", ReplicaSet: "rs0", ReadPreference: "secondaryPreferred"}
+	info.setDefault()
+
+	opts, err := info.clientOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Hosts) != 2 || opts.Hosts[1] != "b:27017" {
+		t.Fatalf("hosts: %v", opts.Hosts)
+	}
+	if opts.Auth.Password != "p@ss:/w" || opts.Auth.AuthSource != "admin" {
+		t.Fatalf("auth: %+v", opts.Auth)
+	}
+	if *opts.ReplicaSet != "rs0" || opts.ReadPreference.Mode() != readpref.SecondaryPreferredMode || *opts.MaxPoolSize != 100 {
+		t.Fatalf("opts: %+v", opts)
+	}
+
+	if _, err = (&MongoInfo{Address: "a", ReadPreference: "bogus"}).clientOptions(); err == nil {
+		t.Fatal("expected invalid read preference error")
+	}
+}
+
+func TestURIDatabase(t *testing.T) {
+	info := MongoInfo{URI: "mongodb://u:p@host:27017/orders?maxPoolSize=20"}
+	info.setDefault()
+
+	opts, err := info.clientOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *opts.MaxPoolSize != 20 {
+		t.Fatalf("uri pool size overridden: %d", *opts.MaxPoolSize)
+	}
+	if info.database() != "orders" {
+		t.Fatalf("database: %s", info.database())
+	}
+}
+
+func TestTryClient(t *testing.T) {
+	if c, ok := TryClient(); ok || c != nil {
+		t.Fatalf("TryClient before StartUp = (%v, %v)", c, ok)
+	}
+}
@@ -0,0 +1,315 @@
+package dbmongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNoID 对象没有 _id 字段
+var ErrNoID = errors.New("dbmongo: document has no _id")
+
+// MongoRepository 通用 MongoDB 仓库，方法与 dbmysql.BaseRepository 对应
+// filter 为 nil 时匹配全部文档；ID 按原样匹配 _id，字符串形式的 ObjectID 需先用 primitive.ObjectIDFromHex 转换
+type MongoRepository[T any] struct {
+	database   string // 数据库名，为空时使用连接的默认数据库
+	collection string // 集合名
+}
+
+// NewMongoRepository 创建仓库，indexes 在 StartUp 连接成功后自动创建 (已启动时立即创建)
+func NewMongoRepository[T any](database, collection string, indexes ...mongo.IndexModel) MongoRepository[T] {
+	r := MongoRepository[T]{database: database, collection: collection}
+	if len(indexes) > 0 {
+		registerIndexes(database, collection, indexes)
+	}
+	return r
+}
+
+// Collection 获取集合
+func (r *MongoRepository[T]) Collection() *mongo.Collection {
+	return Database(r.database).Collection(r.collection)
+}
+
+// ==================== 创建操作 ====================
+
+// Insert 插入单条文档，返回 _id
+func (r *MongoRepository[T]) Insert(ctx context.Context, obj T) (interface{}, error) {
+	res, err := r.Collection().InsertOne(ctx, obj)
+	if err != nil {
+		log.Errorf("Mongo Insert err: %s", err.Error())
+		return nil, err
+	}
+	return res.InsertedID, nil
+}
+
+// InsertBatch 批量插入，按顺序写入，遇到错误时停止
+func (r *MongoRepository[T]) InsertBatch(ctx context.Context, objs []T) error {
+	if len(objs) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(objs))
+	for _, v := range objs {
+		docs = append(docs, v)
+	}
+	if _, err := r.Collection().InsertMany(ctx, docs); err != nil {
+		log.Errorf("Mongo InsertBatch err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Upsert 按 filter 替换文档，不存在时插入
+func (r *MongoRepository[T]) Upsert(ctx context.Context, filter interface{}, obj T) error {
+	_, err := r.Collection().ReplaceOne(ctx, r.filter(filter), obj, options.Replace().SetUpsert(true))
+	if err != nil {
+		log.Errorf("Mongo Upsert err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// UpsertFields 按 filter 更新指定字段，不存在时插入
+func (r *MongoRepository[T]) UpsertFields(ctx context.Context, filter interface{}, updates map[string]interface{}) error {
+	_, err := r.Collection().UpdateOne(ctx, r.filter(filter), bson.M{"$set": updates}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Errorf("Mongo UpsertFields err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// ==================== 更新操作 ====================
+
+// Update 按对象的 _id 替换整个文档
+func (r *MongoRepository[T]) Update(ctx context.Context, obj T) error {
+	id, err := documentID(obj)
+	if err != nil {
+		return err
+	}
+	if _, err = r.Collection().ReplaceOne(ctx, bson.M{"_id": id}, obj); err != nil {
+		log.Errorf("Mongo Update err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// UpdateByID 根据 ID 更新指定字段 ($set)
+func (r *MongoRepository[T]) UpdateByID(ctx context.Context, id any, updates map[string]interface{}) (int64, error) {
+	res, err := r.Collection().UpdateByID(ctx, id, bson.M{"$set": updates})
+	if err != nil {
+		log.Errorf("Mongo UpdateByID err: %s", err.Error())
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// UpdateWhere 根据条件更新指定字段 ($set)
+func (r *MongoRepository[T]) UpdateWhere(ctx context.Context, filter interface{}, updates map[string]interface{}) (int64, error) {
+	return r.UpdateWhereRaw(ctx, filter, bson.M{"$set": updates})
+}
+
+// UpdateWhereRaw 根据条件执行原始更新文档 ($inc/$push 等)
+func (r *MongoRepository[T]) UpdateWhereRaw(ctx context.Context, filter interface{}, update interface{}) (int64, error) {
+	res, err := r.Collection().UpdateMany(ctx, r.filter(filter), update)
+	if err != nil {
+		log.Errorf("Mongo UpdateWhere err: %s", err.Error())
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// ==================== 删除操作 ====================
+
+// Delete 根据 ID 删除
+func (r *MongoRepository[T]) Delete(ctx context.Context, id any) error {
+	if _, err := r.Collection().DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		log.Errorf("Mongo Delete err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// DeleteByIDs 批量删除
+func (r *MongoRepository[T]) DeleteByIDs(ctx context.Context, ids []any) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DeleteWhere(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// DeleteWhere 条件删除，filter 不能为空
+func (r *MongoRepository[T]) DeleteWhere(ctx context.Context, filter interface{}) error {
+	if filter == nil {
+		return fmt.Errorf("dbmongo: DeleteWhere on %s requires a filter", r.collection)
+	}
+	if _, err := r.Collection().DeleteMany(ctx, filter); err != nil {
+		log.Errorf("Mongo DeleteWhere err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// ==================== 查询操作 ====================
+
+// FindByID 根据 ID 查询，不存在时返回 mongo.ErrNoDocuments
+func (r *MongoRepository[T]) FindByID(ctx context.Context, id any) (T, error) {
+	return r.FindOne(ctx, bson.M{"_id": id})
+}
+
+// FindByIDs 根据多个 ID 查询
+func (r *MongoRepository[T]) FindByIDs(ctx context.Context, ids []any) ([]T, error) {
+	if len(ids) == 0 {
+		return []T{}, nil
+	}
+	return r.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, nil)
+}
+
+// FindOne 根据条件查询单条文档，不存在时返回 mongo.ErrNoDocuments
+func (r *MongoRepository[T]) FindOne(ctx context.Context, filter interface{}) (T, error) {
+	var t T
+	err := r.Collection().FindOne(ctx, r.filter(filter)).Decode(&t)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Errorf("Mongo FindOne err: %s", err.Error())
+	}
+	return t, err
+}
+
+// FindAll 查询全部文档
+func (r *MongoRepository[T]) FindAll(ctx context.Context) ([]T, error) {
+	return r.Find(ctx, nil, nil)
+}
+
+// Find 根据条件查询，sort 如 bson.D{{"created_at", -1}}
+func (r *MongoRepository[T]) Find(ctx context.Context, filter interface{}, sort bson.D) ([]T, error) {
+	opts := options.Find()
+	if len(sort) > 0 {
+		opts.SetSort(sort)
+	}
+	return r.find(ctx, filter, opts)
+}
+
+// Count 统计数量
+func (r *MongoRepository[T]) Count(ctx context.Context, filter interface{}) (int64, error) {
+	count, err := r.Collection().CountDocuments(ctx, r.filter(filter))
+	if err != nil {
+		log.Errorf("Mongo Count err: %s", err.Error())
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists 检查文档是否存在
+func (r *MongoRepository[T]) Exists(ctx context.Context, filter interface{}) (bool, error) {
+	count, err := r.Collection().CountDocuments(ctx, r.filter(filter), options.Count().SetLimit(1))
+	if err != nil {
+		log.Errorf("Mongo Exists err: %s", err.Error())
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindPage 分页查询
+func (r *MongoRepository[T]) FindPage(ctx context.Context, offset, limit int, sort bson.D, filter interface{}) ([]T, int64, error) {
+	count, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if count == 0 {
+		return []T{}, 0, nil
+	}
+
+	opts := options.Find().SetSkip(int64(offset)).SetLimit(int64(limit))
+	if len(sort) > 0 {
+		opts.SetSort(sort)
+	}
+	list, err := r.find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, count, nil
+}
+
+// PageResult 分页结果
+type PageResult[T any] struct {
+	List       []T   `json:"list"`
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// FindPageResult 分页查询，返回 PageResult
+func (r *MongoRepository[T]) FindPageResult(ctx context.Context, page, pageSize int, sort bson.D, filter interface{}) (*PageResult[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	list, total, err := r.FindPage(ctx, (page-1)*pageSize, pageSize, sort, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	return &PageResult[T]{
+		List:       list,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ==================== 辅助函数 ====================
+
+func (r *MongoRepository[T]) find(ctx context.Context, filter interface{}, opts *options.FindOptions) ([]T, error) {
+	cur, err := r.Collection().Find(ctx, r.filter(filter), opts)
+	if err != nil {
+		log.Errorf("Mongo Find err: %s", err.Error())
+		return nil, err
+	}
+
+	list := []T{}
+	if err = cur.All(ctx, &list); err != nil {
+		log.Errorf("Mongo Find decode err: %s", err.Error())
+		return nil, err
+	}
+	return list, nil
+}
+
+// filter nil 时匹配全部
+func (r *MongoRepository[T]) filter(filter interface{}) interface{} {
+	if filter == nil {
+		return bson.D{}
+	}
+	return filter
+}
+
+// documentID 取出文档的 _id
+func documentID(obj interface{}) (interface{}, error) {
+	raw, err := bson.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	v, err := bson.Raw(raw).LookupErr("_id")
+	if err != nil {
+		return nil, ErrNoID
+	}
+
+	var id interface{}
+	if err = v.Unmarshal(&id); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
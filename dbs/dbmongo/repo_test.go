@@ -0,0 +1,32 @@
+package dbmongo
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDocumentID(t *testing.T) {
+	type withID struct {
+		ID   primitive.ObjectID `bson:"_id,omitempty"`
+		Name string             `bson:"name"`
+	}
+
+	oid := primitive.NewObjectID()
+	id, err := documentID(withID{ID: oid, Name: "a"})
+	if err != nil || id != oid {
+		t.Fatalf("id=%v err=%v", id, err)
+	}
+
+	if _, err = documentID(withID{Name: "a"}); !errors.Is(err, ErrNoID) {
+		t.Fatalf("expected ErrNoID, got %v", err)
+	}
+
+	id, err = documentID(struct {
+		ID string `bson:"_id"`
+	}{ID: "order-1"})
+	if err != nil || id != "order-1" {
+		t.Fatalf("id=%v err=%v", id, err)
+	}
+}
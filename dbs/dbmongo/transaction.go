@@ -0,0 +1,150 @@
+package dbmongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MaxTxRetries 事务遇到 TransientTransactionError 时的最大重试次数
+var MaxTxRetries = 3
+
+// 提交结果未知时的最大重试次数
+const maxCommitRetries = 3
+
+// ==================== 基础事务操作 ====================
+// 事务中的 ctx 为 mongo.SessionContext，用它调用 MongoRepository 或 Collection 即加入事务
+// 所有事务函数都会恢复 fn 中的 panic 并中止事务；fn 可能因重试被执行多次，不要在其中做不可重入的外部调用
+
+// Transaction 执行事务，自动处理提交和回滚
+func Transaction(fn func(ctx context.Context) error) error {
+	return TransactionWithContext(context.Background(), fn)
+}
+
+// TransactionWithContext 带上下文的事务执行，总是开启新会话
+func TransactionWithContext(ctx context.Context, fn func(ctx context.Context) error) error {
+	sess, err := Client().StartSession()
+	if err != nil {
+		log.Errorf("[MONGO] Start session failed: %v", err)
+		return err
+	}
+	defer sess.EndSession(context.Background())
+
+	for attempt := 0; ; attempt++ {
+		err = mongo.WithSession(ctx, sess, func(sc mongo.SessionContext) error {
+			return runTx(sc, fn)
+		})
+		if err == nil || attempt >= MaxTxRetries || !hasErrorLabel(err, "TransientTransactionError") {
+			return err
+		}
+		log.Warnf("[MONGO] Transient transaction error, retry %d: %v", attempt+1, err)
+	}
+}
+
+// WithTx 在事务中执行操作，支持嵌套调用：ctx 中已有会话时直接加入外层事务
+// MongoDB 不支持保存点，内层返回错误时由外层决定整体回滚
+func WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InTransaction(ctx) {
+		return fn(ctx)
+	}
+	return TransactionWithContext(ctx, fn)
+}
+
+// SafeTransaction 安全事务，与 Transaction 相同 (均带 panic 恢复)，与 dbmysql 保持一致
+func SafeTransaction(fn func(ctx context.Context) error) error {
+	return Transaction(fn)
+}
+
+// ==================== 上下文会话 ====================
+
+// GetSessionFromContext 从上下文获取会话
+func GetSessionFromContext(ctx context.Context) mongo.Session {
+	return mongo.SessionFromContext(ctx)
+}
+
+// InTransaction 上下文中的会话是否有进行中的事务
+// 仅有会话 (如 mongo.WithSession 且未 StartTransaction) 时返回 false
+func InTransaction(ctx context.Context) bool {
+	sess := mongo.SessionFromContext(ctx)
+	if sess == nil {
+		return false
+	}
+	// 驱动未在 Session 上公开事务状态，经 XSession 读取底层会话
+	xs, ok := sess.(mongo.XSession)
+	if !ok {
+		return false
+	}
+	cs := xs.ClientSession()
+	return cs != nil && cs.TransactionRunning()
+}
+
+// ==================== 事务辅助函数 ====================
+
+// TxDo 在同一事务中依次执行多个操作
+func TxDo(ctx context.Context, operations ...func(ctx context.Context) error) error {
+	return WithTx(ctx, func(ctx context.Context) error {
+		for _, op := range operations {
+			if err := op(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TxDoWithResult 事务操作并返回结果
+func TxDoWithResult[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) (result T, err error) {
+	err = WithTx(ctx, func(ctx context.Context) error {
+		result, err = fn(ctx)
+		return err
+	})
+	return
+}
+
+// ==================== 内部实现 ====================
+
+// runTx 单次事务：开始、执行、提交，失败或 panic 时中止
+func runTx(sc mongo.SessionContext, fn func(ctx context.Context) error) error {
+	if err := sc.StartTransaction(); err != nil {
+		return err
+	}
+
+	if err := safeCall(sc, fn); err != nil {
+		if abortErr := sc.AbortTransaction(context.Background()); abortErr != nil {
+			log.Errorf("[MONGO] Abort transaction failed: %v", abortErr)
+		}
+		return err
+	}
+
+	// 提交结果未知时重试提交 (提交是幂等的)
+	for i := 0; ; i++ {
+		err := sc.CommitTransaction(context.Background())
+		if err == nil || i >= maxCommitRetries || !hasErrorLabel(err, "UnknownTransactionCommitResult") {
+			if err != nil {
+				log.Errorf("[MONGO] Commit transaction failed: %v", err)
+			}
+			return err
+		}
+		log.Warnf("[MONGO] Unknown commit result, retry %d: %v", i+1, err)
+	}
+}
+
+// safeCall 执行 fn，panic 转为错误
+func safeCall(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("transaction panic: %v", r)
+			log.Errorf("[MONGO] Transaction panic recovered: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// hasErrorLabel 错误链中是否带有指定标签
+func hasErrorLabel(err error, label string) bool {
+	var le mongo.LabeledError
+	return errors.As(err, &le) && le.HasErrorLabel(label)
+}
@@ -0,0 +1,55 @@
+package dbmongo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestHasErrorLabel(t *testing.T) {
+	err := fmt.Errorf("update order: %w", mongo.CommandError{Code: 112, Labels: []string{"TransientTransactionError"}})
+	if !hasErrorLabel(err, "TransientTransactionError") {
+		t.Fatal("label not found through wrap")
+	}
+	if hasErrorLabel(err, "UnknownTransactionCommitResult") || hasErrorLabel(fmt.Errorf("plain"), "TransientTransactionError") {
+		t.Fatal("unexpected label")
+	}
+}
+
+func TestSafeCall(t *testing.T) {
+	err := safeCall(context.Background(), func(ctx context.Context) error { panic("boom") })
+	if err == nil || err.Error() != "transaction panic: boom" {
+		t.Fatalf("err=%v", err)
+	}
+	if InTransaction(context.Background()) {
+		t.Fatal("background ctx has no session")
+	}
+}
+
+func TestInTransaction(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	sess, err := client.StartSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sess.EndSession(context.Background())
+
+	ctx := mongo.NewSessionContext(context.Background(), sess)
+	if InTransaction(ctx) {
+		t.Fatal("session without transaction")
+	}
+	if err = sess.StartTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	if !InTransaction(ctx) {
+		t.Fatal("transaction started")
+	}
+}
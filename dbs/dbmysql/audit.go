@@ -0,0 +1,395 @@
+package dbmysql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ==================== 审计上下文 ====================
+
+type actorKeyType struct{}
+type requestIDKeyType struct{}
+
+var (
+	actorKey     = actorKeyType{}
+	requestIDKey = requestIDKeyType{}
+)
+
+// WithActor 在上下文中设置操作人
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFromContext 获取上下文中的操作人
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+// WithRequestID 在上下文中设置请求 ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext 获取上下文中的请求 ID
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// ==================== 审计记录 ====================
+
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+
+	// auditUpsert 内部使用：按 scope 重新读取写入后的行，区分插入 (AuditCreate) 与更新 (AuditUpdate)
+	auditUpsert = "upsert"
+)
+
+// MaxAuditRows 单次更新/删除逐行审计的行数上限，超过时只记录一条 RecordID 为 "*" 的汇总，不保存快照
+const MaxAuditRows = 500
+
+// AuditLog 审计记录，Before/After 为变更列的 JSON (创建、删除时为整行)
+type AuditLog struct {
+	ID        int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Table     string `gorm:"column:table_name;type:varchar(64);not null;index:idx_audit_record" json:"table_name"`
+	RecordID  string `gorm:"type:varchar(64);not null;index:idx_audit_record" json:"record_id"`
+	Action    string `gorm:"type:varchar(16);not null" json:"action"`
+	Actor     string `gorm:"type:varchar(64);index" json:"actor"`
+	RequestID string `gorm:"type:varchar(64);index" json:"request_id"`
+	Before    string `gorm:"type:text" json:"before"`
+	After     string `gorm:"type:text" json:"after"`
+	CreatedAt int64  `gorm:"type:BIGINT;not null;index" json:"created_at"`
+}
+
+func (*AuditLog) TableName() string { return "audit_log" }
+
+// Audited 返回开启审计的副本，写操作会在同一事务内记录审计日志
+// 需先创建 audit_log 表；每次更新/删除会额外读取变更前后的行，按需对单个仓库开启
+func (r BaseRepository[T]) Audited() BaseRepository[T] {
+	r.audit = true
+	return r
+}
+
+// FindAuditLogs 查询记录的审计日志，按时间倒序
+func (r *BaseRepository[T]) FindAuditLogs(id any) ([]AuditLog, error) {
+	s, err := r.schema(r.DB())
+	if err != nil {
+		return nil, err
+	}
+
+	var list []AuditLog
+	err = r.DB().Where("table_name = ? AND record_id = ?", s.Table, fmt.Sprint(id)).Order("id desc").Find(&list).Error
+	return list, err
+}
+
+// ==================== 审计写入 ====================
+
+// auditWrite 执行更新/删除；开启审计时在事务内记录受影响行的前后快照
+// scope 限定受影响的行，用于读取变更前快照
+func (r *BaseRepository[T]) auditWrite(db *gorm.DB, action string, scope func(q *gorm.DB) *gorm.DB, fn func(tx *gorm.DB) error) error {
+	upsert := action == auditUpsert
+	if upsert {
+		action = AuditUpdate
+	}
+
+	if !r.audit && upsert {
+		// upsert 的 scope 同时覆盖插入与更新的行，写入后读取一次即可
+		if err := fn(db); err != nil {
+			return err
+		}
+		ids, err := r.hookIDs(db, scope)
+		if err != nil {
+			log.Errorf("[MYSQL] write hook ids err: %s", err.Error())
+		}
+		r.fireWrite(db, action, ids)
+		return nil
+	}
+	if !r.audit {
+		ids, err := r.hookIDs(db, scope)
+		if err != nil {
+			return err
+		}
+		if err = fn(db); err != nil {
+			return err
+		}
+		if ids != nil && len(ids) == 0 && action != AuditDelete {
+			// 写入前无匹配行 (如 upsert 插入)，按 scope 重新读取
+			if ids, err = r.hookIDs(db, scope); err != nil {
+				log.Errorf("[MYSQL] write hook ids err: %s", err.Error())
+			}
+		}
+		r.fireWrite(db, action, ids)
+		return nil
+	}
+
+	var ids []string
+	err := r.auditTx(db).Transaction(func(tx *gorm.DB) error {
+		var before []T
+		if err := scope(tx.Model((*T)(nil))).Limit(MaxAuditRows + 1).Find(&before).Error; err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if len(before) > MaxAuditRows {
+			// 批量写入不保存快照，避免前后各读取一次全部匹配行
+			ids = nil
+			return r.writeAuditSummary(tx, action)
+		}
+
+		var after []T
+		if action != AuditDelete {
+			// 按变更前的主键重新读取 (更新可能改变 scope 条件列)；无变更前记录或 upsert 时还需按 scope 读取插入的行
+			query := scope(tx.Model((*T)(nil)))
+			if len(before) > 0 {
+				ids, err := r.pkValues(tx, before)
+				if err != nil {
+					return err
+				}
+				byPK := tx.Model((*T)(nil)).Where(fmt.Sprintf("%s IN ?", r.pkColumn), ids)
+				if upsert {
+					query = byPK.Or(scope(tx.Session(&gorm.Session{NewDB: true})))
+				} else {
+					query = byPK
+				}
+			}
+			if err := query.Find(&after).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if ids, err = r.hookIDsOf(tx, append(before, after...)); err != nil {
+			return err
+		}
+		return r.writeAudit(tx, action, before, after)
+	})
+	if err == nil {
+		r.fireWrite(r.auditTx(db), action, ids)
+	}
+	return err
+}
+
+// auditWriteByPK 同 auditWrite，用于按主键写入：未开启审计时直接以 ids 作为回调主键，不再查询
+func (r *BaseRepository[T]) auditWriteByPK(db *gorm.DB, action string, ids []interface{}, scope func(q *gorm.DB) *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if r.audit {
+		return r.auditWrite(db, action, scope, fn)
+	}
+	if err := fn(db); err != nil {
+		return err
+	}
+	r.fireWrite(db, action, r.hookIDsOfValues(ids))
+	return nil
+}
+
+// auditCreate 执行创建；开启审计时在事务内记录新增行
+func (r *BaseRepository[T]) auditCreate(db *gorm.DB, objs []T, fn func(tx *gorm.DB) error) error {
+	if !r.audit {
+		if err := fn(db); err != nil {
+			return err
+		}
+	} else if err := r.auditTx(db).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return r.writeAudit(tx, AuditCreate, nil, objs)
+	}); err != nil {
+		return err
+	}
+
+	// 自增主键在写入后才回填
+	ids, err := r.hookIDsOf(db, objs)
+	if err != nil {
+		log.Errorf("[MYSQL] write hook ids err: %s", err.Error())
+	}
+	if r.audit {
+		db = r.auditTx(db)
+	}
+	r.fireWrite(db, AuditCreate, ids)
+	return nil
+}
+
+// objScope 按对象主键限定范围
+func (r *BaseRepository[T]) objScope(db *gorm.DB, obj T) func(q *gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		ids, err := r.pkValues(db, []T{obj})
+		if err != nil {
+			q.AddError(err)
+			return q
+		}
+		return q.Where(fmt.Sprintf("%s IN ?", r.pkColumn), ids)
+	}
+}
+
+// auditTx 审计写入使用上下文中的事务，保证与业务写入同时提交
+func (r *BaseRepository[T]) auditTx(db *gorm.DB) *gorm.DB {
+	if db.Statement.Context != nil {
+		if tx := GetTxFromContextOn(db.Statement.Context, r.conn); tx != nil {
+			return tx.WithContext(db.Statement.Context)
+		}
+	}
+	return db
+}
+
+// writeAudit 按主键配对前后快照，写入有变化的记录
+func (r *BaseRepository[T]) writeAudit(tx *gorm.DB, action string, before, after []T) error {
+	s, err := r.schema(tx)
+	if err != nil {
+		return err
+	}
+	pk := s.LookUpField(r.pkColumn)
+	if pk == nil {
+		return fmt.Errorf("dbmysql: audit %s: unknown primary key %s", s.Table, r.pkColumn)
+	}
+
+	ctx := tx.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	beforeRows := map[string]map[string]interface{}{}
+	var ids []string
+	for i := range before {
+		id, row := auditRow(ctx, s, pk, reflect.ValueOf(&before[i]).Elem())
+		beforeRows[id] = row
+		ids = append(ids, id)
+	}
+	afterRows := map[string]map[string]interface{}{}
+	for i := range after {
+		id, row := auditRow(ctx, s, pk, reflect.ValueOf(&after[i]).Elem())
+		afterRows[id] = row
+		if _, ok := beforeRows[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	logs := make([]AuditLog, 0, len(ids))
+	for _, id := range ids {
+		b, a := auditDiff(beforeRows[id], afterRows[id])
+		if b == nil && a == nil {
+			continue
+		}
+		act := action
+		if b == nil {
+			act = AuditCreate // upsert 插入
+		}
+		logs = append(logs, AuditLog{
+			Table:     s.Table,
+			RecordID:  id,
+			Action:    act,
+			Actor:     ActorFromContext(ctx),
+			RequestID: RequestIDFromContext(ctx),
+			Before:    auditJSON(b),
+			After:     auditJSON(a),
+			CreatedAt: now,
+		})
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	if err = tx.CreateInBatches(logs, 100).Error; err != nil {
+		log.Errorf("[MYSQL] write audit log err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// writeAuditSummary 记录一条批量写入的汇总审计
+func (r *BaseRepository[T]) writeAuditSummary(tx *gorm.DB, action string) error {
+	s, err := r.schema(tx)
+	if err != nil {
+		return err
+	}
+	ctx := tx.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err = tx.Create(&AuditLog{
+		Table:     s.Table,
+		RecordID:  "*",
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+		After:     auditJSON(map[string]interface{}{"rows_over": MaxAuditRows}),
+		CreatedAt: time.Now().UnixMilli(),
+	}).Error; err != nil {
+		log.Errorf("[MYSQL] write audit log err: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// auditRow 行数据转为 列名 -> 值
+func auditRow(ctx context.Context, s *schema.Schema, pk *schema.Field, rv reflect.Value) (string, map[string]interface{}) {
+	row := make(map[string]interface{}, len(s.DBNames))
+	for _, name := range s.DBNames {
+		v, _ := s.FieldsByDBName[name].ValueOf(ctx, rv)
+		row[name] = v
+	}
+	id, _ := pk.ValueOf(ctx, rv)
+	return fmt.Sprint(id), row
+}
+
+// auditDiff 只保留变化的列；一侧为空时返回另一侧整行
+func auditDiff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	if before == nil || after == nil {
+		return before, after
+	}
+
+	b, a := map[string]interface{}{}, map[string]interface{}{}
+	for k, v := range after {
+		if !reflect.DeepEqual(before[k], v) {
+			b[k], a[k] = before[k], v
+		}
+	}
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return b, a
+}
+
+func auditJSON(row map[string]interface{}) string {
+	if row == nil {
+		return ""
+	}
+	b, _ := json.Marshal(row)
+	return string(b)
+}
+
+// pkValues 提取主键值
+func (r *BaseRepository[T]) pkValues(db *gorm.DB, list []T) ([]interface{}, error) {
+	s, err := r.schema(db)
+	if err != nil {
+		return nil, err
+	}
+	pk := s.LookUpField(r.pkColumn)
+	if pk == nil {
+		return nil, fmt.Errorf("dbmysql: unknown primary key %s on %s", r.pkColumn, s.Table)
+	}
+
+	ids := make([]interface{}, 0, len(list))
+	for i := range list {
+		v, _ := pk.ValueOf(context.Background(), reflect.ValueOf(&list[i]).Elem())
+		ids = append(ids, v)
+	}
+	return ids, nil
+}
+
+func (r *BaseRepository[T]) schema(db *gorm.DB) (*schema.Schema, error) {
+	var t T
+	return schema.Parse(&t, &schemaCache, db.NamingStrategy)
+}
@@ -0,0 +1,339 @@
+package dbmysql
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultChunkSize 批量操作默认每批行数
+const DefaultChunkSize = 500
+
+// BatchOptions 批量操作配置
+type BatchOptions struct {
+	ChunkSize       int      // 每批行数，默认 500
+	ConflictColumns []string // upsert 冲突列，默认主键 (MySQL 按表上任意唯一键判断冲突，审计与写入回调按这些列的值定位行)
+	UpdateColumns   []string // upsert 冲突时更新的列，为空时更新除主键、冲突列和创建时间外的全部列
+	IgnoreConflict  bool     // upsert 冲突时跳过不更新
+	Upsert          bool     // BatchLoad 使用 upsert 写入，否则直接插入
+
+	// OnChunk 每批完成后回调，chunk 从 0 开始，rows 为该批影响行数
+	OnChunk func(chunk int, rows int64)
+}
+
+func (o BatchOptions) chunkSize() int {
+	if o.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return o.ChunkSize
+}
+
+// BatchResult 批量操作结果
+// upsert 时 MySQL 对插入的行计 1，对更新的行计 2，未变化的行计 0
+type BatchResult struct {
+	Chunks       []int64 // 每批影响行数
+	RowsAffected int64   // 合计影响行数
+}
+
+func (res *BatchResult) add(chunk int, rows int64, opts BatchOptions) {
+	res.Chunks = append(res.Chunks, rows)
+	res.RowsAffected += rows
+	if opts.OnChunk != nil {
+		opts.OnChunk(chunk, rows)
+	}
+}
+
+// ==================== 批量插入 ====================
+
+// BatchInsert 分批插入，每批一条 INSERT 语句；某批失败时返回已完成批次的结果
+func (r *BaseRepository[T]) BatchInsert(objs []T, opts BatchOptions) (BatchResult, error) {
+	return r.BatchInsertWithDB(r.DB(), objs, opts)
+}
+
+// BatchInsertWithDB 使用指定 DB 分批插入，需要整体原子时传入事务
+func (r *BaseRepository[T]) BatchInsertWithDB(db *gorm.DB, objs []T, opts BatchOptions) (BatchResult, error) {
+	return r.eachChunk(objs, opts, "BatchInsert", func(chunk []T) (int64, error) {
+		return r.insertChunk(db, chunk)
+	})
+}
+
+func (r *BaseRepository[T]) insertChunk(db *gorm.DB, chunk []T) (int64, error) {
+	var rows int64
+	err := r.auditCreate(db, chunk, func(tx *gorm.DB) error {
+		result := tx.Create(&chunk)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	return rows, err
+}
+
+// ==================== 批量 upsert ====================
+
+// BatchUpsert 分批插入，冲突时按 opts 更新或跳过
+func (r *BaseRepository[T]) BatchUpsert(objs []T, opts BatchOptions) (BatchResult, error) {
+	return r.BatchUpsertWithDB(r.DB(), objs, opts)
+}
+
+// BatchUpsertWithDB 使用指定 DB 分批 upsert
+func (r *BaseRepository[T]) BatchUpsertWithDB(db *gorm.DB, objs []T, opts BatchOptions) (BatchResult, error) {
+	onConflict, err := r.onConflict(db, opts)
+	if err != nil {
+		return BatchResult{}, err
+	}
+	return r.eachChunk(objs, opts, "BatchUpsert", func(chunk []T) (int64, error) {
+		return r.upsertChunk(db, chunk, onConflict)
+	})
+}
+
+func (r *BaseRepository[T]) upsertChunk(db *gorm.DB, chunk []T, onConflict clause.OnConflict) (int64, error) {
+	scope, err := r.conflictScope(db, chunk, onConflict.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	err = r.auditWrite(db, auditUpsert, scope, func(tx *gorm.DB) error {
+		result := tx.Clauses(onConflict).Create(&chunk)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	return rows, err
+}
+
+// conflictScope 按冲突列的值限定范围，冲突列非主键时新对象的主键与表中已有行不同
+func (r *BaseRepository[T]) conflictScope(db *gorm.DB, chunk []T, columns []clause.Column) (func(q *gorm.DB) *gorm.DB, error) {
+	s, err := r.schema(db)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]*schema.Field, len(columns))
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		if fields[i] = s.LookUpField(c.Name); fields[i] == nil {
+			return nil, fmt.Errorf("dbmysql: unknown conflict column %s on %s", c.Name, s.Table)
+		}
+		names[i] = fields[i].DBName
+	}
+
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	values := make([]interface{}, 0, len(chunk))
+	for i := range chunk {
+		rv := reflect.ValueOf(&chunk[i]).Elem()
+		if len(fields) == 1 {
+			v, _ := fields[0].ValueOf(ctx, rv)
+			values = append(values, v)
+			continue
+		}
+		tuple := make([]interface{}, len(fields))
+		for j, f := range fields {
+			tuple[j], _ = f.ValueOf(ctx, rv)
+		}
+		values = append(values, tuple)
+	}
+
+	cond := fmt.Sprintf("%s IN ?", names[0])
+	if len(names) > 1 {
+		cond = fmt.Sprintf("(%s) IN ?", strings.Join(names, ", "))
+	}
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Where(cond, values)
+	}, nil
+}
+
+// onConflict 按配置生成冲突处理子句
+func (r *BaseRepository[T]) onConflict(db *gorm.DB, opts BatchOptions) (clause.OnConflict, error) {
+	conflict := opts.ConflictColumns
+	if len(conflict) == 0 {
+		conflict = []string{r.pkColumn}
+	}
+
+	oc := clause.OnConflict{}
+	for _, c := range conflict {
+		oc.Columns = append(oc.Columns, clause.Column{Name: c})
+	}
+	if opts.IgnoreConflict {
+		oc.DoNothing = true
+		return oc, nil
+	}
+
+	update := opts.UpdateColumns
+	if len(update) == 0 {
+		s, err := r.schema(db)
+		if err != nil {
+			return oc, err
+		}
+		for _, f := range s.Fields {
+			if f.DBName == "" || f.PrimaryKey || f.AutoCreateTime > 0 || f.DBName == "created_at" ||
+				slices.Contains(conflict, f.DBName) {
+				continue
+			}
+			update = append(update, f.DBName)
+		}
+	}
+	if len(update) == 0 {
+		oc.DoNothing = true
+		return oc, nil
+	}
+	oc.DoUpdates = clause.AssignmentColumns(update)
+	return oc, nil
+}
+
+// ==================== 批量更新 ====================
+
+// BatchUpdate 按主键分批更新指定列，每批一条 UPDATE ... SET col = CASE pk WHEN ... END 语句
+// columns 为数据库列名，值取自各对象的对应字段；模型声明版本字段且 columns 不含该列时版本号同时 +1
+func (r *BaseRepository[T]) BatchUpdate(objs []T, columns []string, opts BatchOptions) (BatchResult, error) {
+	return r.BatchUpdateWithDB(r.DB(), objs, columns, opts)
+}
+
+// BatchUpdateWithDB 使用指定 DB 分批更新
+func (r *BaseRepository[T]) BatchUpdateWithDB(db *gorm.DB, objs []T, columns []string, opts BatchOptions) (BatchResult, error) {
+	if len(columns) == 0 {
+		return BatchResult{}, fmt.Errorf("dbmysql: BatchUpdate requires columns")
+	}
+	return r.eachChunk(objs, opts, "BatchUpdate", func(chunk []T) (int64, error) {
+		return r.updateChunk(db, chunk, columns)
+	})
+}
+
+func (r *BaseRepository[T]) updateChunk(db *gorm.DB, chunk []T, columns []string) (int64, error) {
+	ids, updates, err := r.caseUpdates(db, chunk, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	scope := func(q *gorm.DB) *gorm.DB {
+		return q.Where(fmt.Sprintf("%s IN ?", r.pkColumn), ids)
+	}
+
+	var rows int64
+	err = r.auditWriteByPK(db, AuditUpdate, ids, scope, func(tx *gorm.DB) error {
+		result := scope(tx.Model((*T)(nil))).Updates(withVersionBump[T](tx, updates))
+		rows = result.RowsAffected
+		return result.Error
+	})
+	return rows, err
+}
+
+// caseUpdates 为每列生成 CASE pk WHEN id THEN value ... END 表达式
+func (r *BaseRepository[T]) caseUpdates(db *gorm.DB, chunk []T, columns []string) ([]interface{}, map[string]interface{}, error) {
+	s, err := r.schema(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids, err := r.pkValues(db, chunk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	updates := make(map[string]interface{}, len(columns))
+	for _, col := range columns {
+		f := s.LookUpField(col)
+		if f == nil || f.DBName == "" {
+			return nil, nil, fmt.Errorf("dbmysql: BatchUpdate %s: unknown column %s", s.Table, col)
+		}
+
+		sql := "CASE ?"
+		args := []interface{}{clause.Column{Name: r.pkColumn}}
+		for i := range chunk {
+			v, _ := f.ValueOf(ctx, reflect.ValueOf(&chunk[i]).Elem())
+			sql += " WHEN ? THEN ?"
+			args = append(args, ids[i], v)
+		}
+		updates[f.DBName] = gorm.Expr(sql+" END", args...)
+	}
+	return ids, updates, nil
+}
+
+// ==================== 流式导入 ====================
+
+// BatchLoad 从 src 流式读取记录并分批写入，适用于地址池等大批量导入；ctx 中有事务时加入事务
+// src 返回错误或 ctx 取消时停止，已写入的批次不回滚
+func (r *BaseRepository[T]) BatchLoad(ctx context.Context, src iter.Seq2[T, error], opts BatchOptions) (BatchResult, error) {
+	return r.BatchLoadWithDB(r.CtxDB(ctx), ctx, src, opts)
+}
+
+// BatchLoadWithDB 使用指定 DB 流式导入
+func (r *BaseRepository[T]) BatchLoadWithDB(db *gorm.DB, ctx context.Context, src iter.Seq2[T, error], opts BatchOptions) (BatchResult, error) {
+	var res BatchResult
+
+	write := func(chunk []T) (int64, error) { return r.insertChunk(db, chunk) }
+	if opts.Upsert {
+		onConflict, err := r.onConflict(db, opts)
+		if err != nil {
+			return res, err
+		}
+		write = func(chunk []T) (int64, error) { return r.upsertChunk(db, chunk, onConflict) }
+	}
+
+	size := opts.chunkSize()
+	buf := make([]T, 0, size)
+	flush := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rows, err := write(buf)
+		if err != nil {
+			return err
+		}
+		res.add(len(res.Chunks), rows, opts)
+		buf = make([]T, 0, size)
+		return nil
+	}
+
+	for obj, err := range src {
+		if err != nil {
+			log.Errorf("BatchLoad source err after %d chunks: %s", len(res.Chunks), err.Error())
+			return res, err
+		}
+		buf = append(buf, obj)
+		if len(buf) < size {
+			continue
+		}
+		if err = flush(); err != nil {
+			log.Errorf("BatchLoad err after %d chunks: %s", len(res.Chunks), err.Error())
+			return res, err
+		}
+	}
+	if err := flush(); err != nil {
+		log.Errorf("BatchLoad err after %d chunks: %s", len(res.Chunks), err.Error())
+		return res, err
+	}
+	return res, nil
+}
+
+// ==================== 辅助函数 ====================
+
+// eachChunk 按批执行 fn，某批失败时停止并返回已完成批次的结果
+func (r *BaseRepository[T]) eachChunk(objs []T, opts BatchOptions, op string, fn func(chunk []T) (int64, error)) (BatchResult, error) {
+	var res BatchResult
+	for chunk := range slices.Chunk(objs, opts.chunkSize()) {
+		i := len(res.Chunks)
+		rows, err := fn(chunk)
+		if err != nil {
+			log.Errorf("%s err at chunk %d: %s", op, i, err.Error())
+			return res, err
+		}
+		res.add(i, rows, opts)
+	}
+	return res, nil
+}
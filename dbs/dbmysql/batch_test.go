@@ -0,0 +1,101 @@
+package dbmysql
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type batchModel struct {
+	ID     int64 `gorm:"primaryKey"`
+	Status int
+	Remark string
+}
+
+func TestBatchUpdateSQL(t *testing.T) {
+	db := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true})
+	repo := NewBaseRepository[batchModel]("id")
+
+	var sqls []string
+	db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+	})
+
+	objs := []batchModel{{ID: 1, Status: 2}, {ID: 2, Status: 3}, {ID: 3, Status: 4}}
+	var chunks []int
+	res, err := repo.BatchUpdateWithDB(db, objs, []string{"status"}, BatchOptions{
+		ChunkSize: 2,
+		OnChunk:   func(chunk int, rows int64) { chunks = append(chunks, chunk) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Chunks) != 2 || len(chunks) != 2 || chunks[1] != 1 {
+		t.Fatalf("res=%+v chunks=%v", res, chunks)
+	}
+
+	want := "UPDATE `batch_models` SET `status`=CASE `id` WHEN ? THEN ? WHEN ? THEN ? END WHERE id IN (?,?)"
+	if len(sqls) != 2 || sqls[0] != want {
+		t.Fatalf("sql: %v", sqls)
+	}
+
+	if _, err = repo.BatchUpdateWithDB(db, objs, []string{"missing"}, BatchOptions{}); err == nil {
+		t.Fatal("expected unknown column error")
+	}
+}
+
+func TestBatchUpsertSQL(t *testing.T) {
+	db := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true})
+	repo := NewBaseRepository[batchModel]("id")
+
+	stmt := db.Clauses(mustOnConflict(t, repo, db, BatchOptions{})).Create(&[]batchModel{{ID: 1}}).Statement
+	if sql := stmt.SQL.String(); !strings.HasSuffix(sql, "ON DUPLICATE KEY UPDATE `status`=VALUES(`status`),`remark`=VALUES(`remark`)") {
+		t.Fatalf("upsert sql: %s", sql)
+	}
+
+	oc := mustOnConflict(t, repo, db, BatchOptions{UpdateColumns: []string{"remark"}})
+	if len(oc.DoUpdates) != 1 || oc.DoNothing {
+		t.Fatalf("update columns: %+v", oc)
+	}
+	if oc = mustOnConflict(t, repo, db, BatchOptions{IgnoreConflict: true}); !oc.DoNothing {
+		t.Fatalf("ignore conflict: %+v", oc)
+	}
+}
+
+func mustOnConflict(t *testing.T, repo BaseRepository[batchModel], db *gorm.DB, opts BatchOptions) clause.OnConflict {
+	oc, err := repo.onConflict(db, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oc
+}
+
+func TestConflictScope(t *testing.T) {
+	db := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true})
+	repo := NewBaseRepository[batchModel]("id")
+	objs := []batchModel{{ID: 0, Status: 1, Remark: "a"}, {ID: 0, Status: 2, Remark: "b"}}
+
+	scope, err := repo.conflictScope(db, objs, []clause.Column{{Name: "remark"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB { return scope(tx.Model(&batchModel{})).Find(&[]batchModel{}) })
+	if !strings.HasSuffix(sql, "WHERE remark IN ('a','b')") {
+		t.Fatalf("single column: %s", sql)
+	}
+
+	scope, err = repo.conflictScope(db, objs, []clause.Column{{Name: "status"}, {Name: "remark"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql = db.ToSQL(func(tx *gorm.DB) *gorm.DB { return scope(tx.Model(&batchModel{})).Find(&[]batchModel{}) })
+	if !strings.HasSuffix(sql, "WHERE (status, remark) IN ((1,'a'),(2,'b'))") {
+		t.Fatalf("multi column: %s", sql)
+	}
+
+	if _, err = repo.conflictScope(db, objs, []clause.Column{{Name: "missing"}}); err == nil {
+		t.Fatal("expected unknown column error")
+	}
+}
@@ -0,0 +1,204 @@
+package dbmysql
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// ==================== 类型安全字段 ====================
+
+// Field 模型列，V 为列对应的 Go 类型，由 cmd/fieldgen 按模型生成
+//
+//	models.GoodsOrderRepo.Find(dbmysql.OrderBy(f.CreatedAt.Desc()), f.OrderStatus.Eq(2), f.Uid.Eq(uid))
+type Field[V any] struct {
+	column string
+}
+
+// NewField 创建列字段
+func NewField[V any](column string) Field[V] {
+	return Field[V]{column: column}
+}
+
+// Column 列名
+func (f Field[V]) Column() string {
+	return f.column
+}
+
+func (f Field[V]) col() clause.Column {
+	return clause.Column{Name: f.column}
+}
+
+// Eq 等于
+func (f Field[V]) Eq(v V) clause.Expression {
+	return clause.Eq{Column: f.col(), Value: v}
+}
+
+// Neq 不等于
+func (f Field[V]) Neq(v V) clause.Expression {
+	return clause.Neq{Column: f.col(), Value: v}
+}
+
+// Gt 大于
+func (f Field[V]) Gt(v V) clause.Expression {
+	return clause.Gt{Column: f.col(), Value: v}
+}
+
+// Gte 大于等于
+func (f Field[V]) Gte(v V) clause.Expression {
+	return clause.Gte{Column: f.col(), Value: v}
+}
+
+// Lt 小于
+func (f Field[V]) Lt(v V) clause.Expression {
+	return clause.Lt{Column: f.col(), Value: v}
+}
+
+// Lte 小于等于
+func (f Field[V]) Lte(v V) clause.Expression {
+	return clause.Lte{Column: f.col(), Value: v}
+}
+
+// Between 闭区间
+func (f Field[V]) Between(min, max V) clause.Expression {
+	return clause.And(f.Gte(min), f.Lte(max))
+}
+
+// In 在列表中，列表为空时条件恒为假
+func (f Field[V]) In(vs ...V) clause.Expression {
+	values := make([]interface{}, len(vs))
+	for i, v := range vs {
+		values[i] = v
+	}
+	return clause.IN{Column: f.col(), Values: values}
+}
+
+// NotIn 不在列表中
+func (f Field[V]) NotIn(vs ...V) clause.Expression {
+	return clause.Not(f.In(vs...))
+}
+
+// Like 模糊匹配，pattern 需自行包含 % 通配符
+func (f Field[V]) Like(pattern string) clause.Expression {
+	return clause.Like{Column: f.col(), Value: pattern}
+}
+
+// IsNull 为 NULL
+func (f Field[V]) IsNull() clause.Expression {
+	return clause.Eq{Column: f.col(), Value: nil}
+}
+
+// IsNotNull 不为 NULL
+func (f Field[V]) IsNotNull() clause.Expression {
+	return clause.Neq{Column: f.col(), Value: nil}
+}
+
+// Set 更新赋值
+func (f Field[V]) Set(v V) Assignment {
+	return Assignment{column: f.column, value: v}
+}
+
+// Asc 正序
+func (f Field[V]) Asc() SortField {
+	return SortField{Column: f.column}
+}
+
+// Desc 倒序
+func (f Field[V]) Desc() SortField {
+	return SortField{Column: f.column, Desc: true}
+}
+
+// ==================== 条件组合 ====================
+
+// And 条件与
+func And(exprs ...clause.Expression) clause.Expression {
+	return clause.And(exprs...)
+}
+
+// Or 条件或
+func Or(exprs ...clause.Expression) clause.Expression {
+	return clause.Or(exprs...)
+}
+
+// Not 条件取反
+func Not(exprs ...clause.Expression) clause.Expression {
+	return clause.Not(exprs...)
+}
+
+// Conds 将条件转为 Find/Count/FindPage/UpdateWhere 接受的 conds 参数，便于动态拼接
+func Conds(exprs ...clause.Expression) []interface{} {
+	res := make([]interface{}, 0, len(exprs))
+	for _, e := range exprs {
+		if e != nil {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// Assignment 更新赋值
+type Assignment struct {
+	column string
+	value  interface{}
+}
+
+// Updates 将赋值转为 UpdateByID/UpdateWhere 接受的 updates 参数
+func Updates(assigns ...Assignment) map[string]interface{} {
+	res := make(map[string]interface{}, len(assigns))
+	for _, a := range assigns {
+		res[a.column] = a.value
+	}
+	return res
+}
+
+// ==================== 排序 ====================
+
+// OrderBy 将排序字段转为 Find/FindPage 接受的 order 参数
+func OrderBy(sort ...SortField) string {
+	parts := make([]string, 0, len(sort))
+	for _, f := range sort {
+		if f.Desc {
+			parts = append(parts, f.Column+" desc")
+		} else {
+			parts = append(parts, f.Column+" asc")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SafeOrder 校验外部传入的排序 (如 "created_at desc,id")，只允许白名单中的列与 asc/desc
+// 返回规范化后的 order 参数，order 为空时返回空串
+func SafeOrder(order string, whitelist ...string) (string, error) {
+	allowed := make(map[string]struct{}, len(whitelist))
+	for _, c := range whitelist {
+		allowed[c] = struct{}{}
+	}
+
+	var sort []SortField
+	for _, part := range strings.Split(order, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return "", fmt.Errorf("dbmysql: invalid order %q", part)
+		}
+		if _, ok := allowed[fields[0]]; !ok {
+			return "", fmt.Errorf("dbmysql: order by column %q not allowed", fields[0])
+		}
+
+		f := SortField{Column: fields[0]}
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				f.Desc = true
+			default:
+				return "", fmt.Errorf("dbmysql: invalid order direction %q", fields[1])
+			}
+		}
+		sort = append(sort, f)
+	}
+	return OrderBy(sort...), nil
+}
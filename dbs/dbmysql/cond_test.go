@@ -0,0 +1,60 @@
+package dbmysql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeOrder(t *testing.T) {
+	whitelist := []string{"id", "created_at", "amount"}
+
+	got, err := SafeOrder(" created_at DESC, id", whitelist...)
+	if err != nil || got != "created_at desc, id asc" {
+		t.Fatalf("got %q err=%v", got, err)
+	}
+
+	for _, order := range []string{
+		"password desc",
+		"id; drop table goods_order",
+		"id desc, amount sideways",
+		"(select 1) desc",
+		"id desc limit 1",
+	} {
+		if _, err = SafeOrder(order, whitelist...); err == nil {
+			t.Fatalf("order %q should be rejected", order)
+		}
+	}
+}
+
+func TestFieldConds(t *testing.T) {
+	var (
+		status = NewField[int]("order_status")
+		uid    = NewField[string]("uid")
+		paid   = NewField[int64]("paid_at")
+	)
+
+	db := dryRunDB(t)
+	query := db.Model(&cursorModel{})
+	for _, c := range Conds(status.In(1, 5), uid.Neq("u1"), Or(paid.Between(1, 2), paid.IsNull()), nil) {
+		query = query.Where(c)
+	}
+
+	var list []cursorModel
+	stmt := query.Order(OrderBy(paid.Desc(), uid.Asc())).Find(&list).Statement
+	sql := stmt.SQL.String()
+
+	for _, want := range []string{
+		"`order_status` IN (?,?)",
+		"`uid` <> ?",
+		"((`paid_at` >= ? AND `paid_at` <= ?) OR `paid_at` IS NULL)",
+		"ORDER BY paid_at desc, uid asc",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("sql %q missing %q", sql, want)
+		}
+	}
+
+	if u := Updates(status.Set(2), uid.Set("u2")); u["order_status"] != 2 || u["uid"] != "u2" {
+		t.Fatalf("unexpected updates: %v", u)
+	}
+}
@@ -0,0 +1,257 @@
+package dbmysql
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidCursor = errors.New("dbmysql: invalid cursor")
+
+// SortField 排序字段
+type SortField struct {
+	Column string // 数据库列名
+	Desc   bool   // 是否倒序
+}
+
+// ParseSort 解析 "created_at desc, amount" 格式的排序
+func ParseSort(order string) []SortField {
+	var res []SortField
+	for _, part := range strings.Split(order, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		res = append(res, SortField{
+			Column: fields[0],
+			Desc:   len(fields) > 1 && strings.EqualFold(fields[1], "desc"),
+		})
+	}
+	return res
+}
+
+// CursorPage 游标分页结果，NextCursor 为空表示没有下一页
+type CursorPage[T any] struct {
+	List       []T    `json:"list"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// cursorData 游标内容：排序签名 + 最后一条记录的排序键
+type cursorData struct {
+	Sort string            `json:"s"`
+	Keys []json.RawMessage `json:"k"`
+}
+
+// ==================== 游标分页 ====================
+
+// FindCursor 游标分页查询，不统计总数；cursor 为空表示第一页
+// 排序字段末尾自动追加主键保证顺序唯一，主键方向与最后一个排序字段一致
+func (r *BaseRepository[T]) FindCursor(cursor string, limit int, sort []SortField, conds ...interface{}) (*CursorPage[T], error) {
+	return r.FindCursorWithDB(r.DB(), cursor, limit, sort, conds...)
+}
+
+// FindCursorWithDB 使用指定 DB 游标分页查询
+func (r *BaseRepository[T]) FindCursorWithDB(db *gorm.DB, cursor string, limit int, sort []SortField, conds ...interface{}) (*CursorPage[T], error) {
+	if limit < 1 {
+		limit = 10
+	}
+
+	s, fields, sort, err := r.cursorFields(db, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Model((*T)(nil))
+	for _, cond := range conds {
+		query = query.Where(cond)
+	}
+
+	if cursor != "" {
+		keys, err := decodeCursor(cursor, sortSignature(sort))
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(keysetExpr(sort, keys))
+	}
+
+	for _, f := range sort {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Column}, Desc: f.Desc})
+	}
+
+	var list []T
+	if err = query.Limit(limit + 1).Find(&list).Error; err != nil {
+		log.Errorf("FindCursor err: %s", err.Error())
+		return nil, err
+	}
+
+	page := &CursorPage[T]{List: list}
+	if len(list) > limit {
+		page.List = list[:limit]
+		page.HasMore = true
+		page.NextCursor, err = encodeCursor(s, fields, sortSignature(sort), &page.List[limit-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// EachBatch 按游标顺序分批遍历全部符合条件的记录（走读库，ctx 中有事务时走事务），fn 返回错误时停止
+func (r *BaseRepository[T]) EachBatch(ctx context.Context, batchSize int, sort []SortField, fn func(batch []T) error, conds ...interface{}) error {
+	return r.EachBatchWithDB(r.CtxReadDB(ctx), ctx, batchSize, sort, fn, conds...)
+}
+
+// EachBatchWithDB 使用指定 DB 分批遍历
+func (r *BaseRepository[T]) EachBatchWithDB(db *gorm.DB, ctx context.Context, batchSize int, sort []SortField, fn func(batch []T) error, conds ...interface{}) error {
+	if batchSize < 1 {
+		batchSize = 500
+	}
+
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := r.FindCursorWithDB(db.WithContext(ctx), cursor, batchSize, sort, conds...)
+		if err != nil {
+			return err
+		}
+		if len(page.List) > 0 {
+			if err = fn(page.List); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ==================== 游标编解码 ====================
+
+// cursorFields 解析排序字段对应的模型字段，并追加主键
+func (r *BaseRepository[T]) cursorFields(db *gorm.DB, sort []SortField) (*schema.Schema, []*schema.Field, []SortField, error) {
+	var t T
+	s, err := schema.Parse(&t, &schemaCache, db.NamingStrategy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	res := make([]SortField, 0, len(sort)+1)
+	hasPK := false
+	for _, f := range sort {
+		res = append(res, f)
+		if f.Column == r.pkColumn {
+			hasPK = true
+		}
+	}
+	if !hasPK {
+		desc := len(res) > 0 && res[len(res)-1].Desc
+		res = append(res, SortField{Column: r.pkColumn, Desc: desc})
+	}
+
+	fields := make([]*schema.Field, 0, len(res))
+	for _, f := range res {
+		field := s.LookUpField(f.Column)
+		if field == nil || field.DBName == "" {
+			return nil, nil, nil, fmt.Errorf("dbmysql: unknown sort column %s on %s", f.Column, s.Table)
+		}
+		fields = append(fields, field)
+	}
+	return s, fields, res, nil
+}
+
+// sortSignature 排序签名，用于校验游标与当前排序一致
+func sortSignature(sort []SortField) string {
+	parts := make([]string, 0, len(sort))
+	for _, f := range sort {
+		if f.Desc {
+			parts = append(parts, "-"+f.Column)
+		} else {
+			parts = append(parts, f.Column)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// encodeCursor 将记录的排序键编码为不透明游标
+func encodeCursor(s *schema.Schema, fields []*schema.Field, signature string, row any) (string, error) {
+	rv := reflect.ValueOf(row).Elem()
+
+	data := cursorData{Sort: signature, Keys: make([]json.RawMessage, 0, len(fields))}
+	for _, f := range fields {
+		v, _ := f.ValueOf(context.Background(), rv)
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("dbmysql: encode cursor %s.%s: %w", s.Table, f.DBName, err)
+		}
+		data.Keys = append(data.Keys, b)
+	}
+
+	b, _ := json.Marshal(data)
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor 解析游标，数字保持原始精度
+func decodeCursor(cursor, signature string) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var data cursorData
+	if err = json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if data.Sort != signature || len(data.Keys) != strings.Count(signature, ",")+1 {
+		return nil, fmt.Errorf("%w: sort mismatch", ErrInvalidCursor)
+	}
+
+	keys := make([]any, 0, len(data.Keys))
+	for _, raw := range data.Keys {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var v any
+		if err = dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		if n, ok := v.(json.Number); ok {
+			v = n.String()
+		}
+		keys = append(keys, v)
+	}
+	return keys, nil
+}
+
+// keysetExpr 构造 (a > ?) OR (a = ? AND b < ?) OR ... 形式的翻页条件
+func keysetExpr(sort []SortField, keys []any) clause.Expression {
+	ors := make([]clause.Expression, 0, len(sort))
+	for i, f := range sort {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, clause.Eq{Column: clause.Column{Name: sort[j].Column}, Value: keys[j]})
+		}
+
+		col := clause.Column{Name: f.Column}
+		if f.Desc {
+			ands = append(ands, clause.Lt{Column: col, Value: keys[i]})
+		} else {
+			ands = append(ands, clause.Gt{Column: col, Value: keys[i]})
+		}
+		ors = append(ors, clause.And(ands...))
+	}
+	return clause.Or(ors...)
+}
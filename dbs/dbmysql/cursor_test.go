@@ -0,0 +1,86 @@
+package dbmysql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type cursorModel struct {
+	ID        int64 `gorm:"primaryKey"`
+	Amount    string
+	CreatedAt int64
+}
+
+// dryRunDB 只生成 SQL 不连接数据库
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "u:p@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestParseSort(t *testing.T) {
+	got := sortSignature(ParseSort("created_at DESC, amount ,id asc"))
+	if got != "-created_at,amount,id" {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	db := dryRunDB(t)
+	repo := NewBaseRepository[cursorModel]("id")
+
+	s, fields, sort, err := repo.cursorFields(db, ParseSort("created_at desc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sortSignature(sort) != "-created_at,-id" {
+		t.Fatalf("pk not appended: %s", sortSignature(sort))
+	}
+
+	row := cursorModel{ID: 1234567890123456789, CreatedAt: 1700000000}
+	cursor, err := encodeCursor(s, fields, sortSignature(sort), &row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := decodeCursor(cursor, sortSignature(sort))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys[0] != "1700000000" || keys[1] != "1234567890123456789" {
+		t.Fatalf("keys lost precision: %v", keys)
+	}
+
+	if _, err = decodeCursor(cursor, "created_at,id"); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("want sort mismatch, got %v", err)
+	}
+	if _, err = decodeCursor("!!", sortSignature(sort)); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("want invalid cursor, got %v", err)
+	}
+}
+
+func TestKeysetSQL(t *testing.T) {
+	db := dryRunDB(t)
+	sort := []SortField{{Column: "created_at", Desc: true}, {Column: "amount"}, {Column: "id"}}
+
+	var list []cursorModel
+	stmt := db.Model(&cursorModel{}).Where(keysetExpr(sort, []any{"1", "2", "3"})).Find(&list).Statement
+	sql := stmt.SQL.String()
+
+	want := "(`created_at` < ? OR (`created_at` = ? AND `amount` > ?) OR (`created_at` = ? AND `amount` = ? AND `id` > ?))"
+	if !strings.Contains(sql, want) {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+	if len(stmt.Vars) != 6 {
+		t.Fatalf("unexpected vars: %v", stmt.Vars)
+	}
+}
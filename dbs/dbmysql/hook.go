@@ -0,0 +1,188 @@
+package dbmysql
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MaxHookIDs 单次写入通知的主键数上限，超过时 WriteEvent.IDs 为 nil
+const MaxHookIDs = 1000
+
+// WriteEvent 仓库写入事件
+type WriteEvent struct {
+	Table  string   // 表名
+	Action string   // AuditCreate / AuditUpdate / AuditDelete / AuditRestore
+	IDs    []string // 受影响的主键；nil 表示行数过多无法列出，监听方应整体失效
+}
+
+// WriteHook 写入成功后的回调，用于缓存失效等
+// 在 WithTx/WithTxOn/TransactionWithContext 的上下文事务中写入时，回调在最外层事务提交后执行，回滚时不执行；
+// 直接传入事务 (如 db.Transaction 的 tx) 时无法感知提交，回调发生在提交前，监听方需自行容忍 (如延迟双删)
+type WriteHook func(ctx context.Context, e WriteEvent)
+
+// OnWrite 返回注册了写入回调的副本，所有写方法成功后依次调用
+// 按主键写入时直接使用已知主键；未开启审计的条件写入 (UpdateWhere 等) 为得到受影响的主键会多查一次主键
+func (r BaseRepository[T]) OnWrite(hooks ...WriteHook) BaseRepository[T] {
+	r.hooks = append(slices.Clip(r.hooks), hooks...)
+	return r
+}
+
+// hookIDs 按 scope 查询受影响行的主键，无回调或行数超过 MaxHookIDs 时返回 nil
+func (r *BaseRepository[T]) hookIDs(db *gorm.DB, scope func(q *gorm.DB) *gorm.DB) ([]string, error) {
+	if len(r.hooks) == 0 {
+		return nil, nil
+	}
+
+	var rows []T
+	if err := scope(db.Model((*T)(nil))).Select(r.pkColumn).Limit(MaxHookIDs + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return r.hookIDsOf(db, rows)
+}
+
+// hookIDsOf 对象主键转为字符串并去重
+func (r *BaseRepository[T]) hookIDsOf(db *gorm.DB, list []T) ([]string, error) {
+	if len(r.hooks) == 0 {
+		return nil, nil
+	}
+	if len(list) > MaxHookIDs {
+		return nil, nil
+	}
+
+	vals, err := r.pkValues(db, list)
+	if err != nil {
+		return nil, err
+	}
+	return r.hookIDsOfValues(vals), nil
+}
+
+// hookIDsOfValues 主键值转为字符串并去重，无回调或超过 MaxHookIDs 时返回 nil
+func (r *BaseRepository[T]) hookIDsOfValues(vals []interface{}) []string {
+	if len(r.hooks) == 0 || len(vals) > MaxHookIDs {
+		return nil
+	}
+	ids := make([]string, 0, len(vals))
+	seen := make(map[string]struct{}, len(vals))
+	for _, v := range vals {
+		id := fmt.Sprint(v)
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func int64Values(ids []int64) []interface{} {
+	vals := make([]interface{}, len(ids))
+	for i, id := range ids {
+		vals[i] = id
+	}
+	return vals
+}
+
+// fireWrite 调用写入回调，写入发生在上下文事务中时推迟到提交后
+func (r *BaseRepository[T]) fireWrite(db *gorm.DB, action string, ids []string) {
+	if len(r.hooks) == 0 {
+		return
+	}
+
+	s, err := r.schema(db)
+	if err != nil {
+		log.Errorf("[MYSQL] write hook schema err: %s", err.Error())
+		return
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	e := WriteEvent{Table: s.Table, Action: action, IDs: ids}
+	if q := afterCommitOf(ctx, r.conn, db); q != nil {
+		q.add(func() { r.runHooks(ctx, e) })
+		return
+	}
+	r.runHooks(ctx, e)
+}
+
+// runHooks 依次调用写入回调，回调 panic 不影响写入结果
+func (r *BaseRepository[T]) runHooks(ctx context.Context, e WriteEvent) {
+	for _, hook := range r.hooks {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					log.Errorf("[MYSQL] write hook on %s panic: %v", e.Table, p)
+				}
+			}()
+			hook(ctx, e)
+		}()
+	}
+}
+
+// ==================== 提交后回调 ====================
+
+// afterCommitKeyType 上下文事务的提交后回调队列键，按实例名区分
+type afterCommitKeyType struct {
+	name string
+}
+
+// afterCommit 上下文事务提交后执行的回调，嵌套事务共用最外层的队列
+type afterCommit struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (a *afterCommit) add(fn func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.fns = append(a.fns, fn)
+}
+
+func (a *afterCommit) run() {
+	a.mu.Lock()
+	fns := a.fns
+	a.fns = nil
+	a.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func afterCommitKey(name string) afterCommitKeyType {
+	if name == "" {
+		name = DefaultName
+	}
+	return afterCommitKeyType{name: name}
+}
+
+// withAfterCommit 为即将开启的上下文事务挂载回调队列；已在上下文事务中时复用外层队列，outer 为 false
+func withAfterCommit(ctx context.Context, name string) (_ context.Context, q *afterCommit, outer bool) {
+	if GetTxFromContextOn(ctx, name) != nil {
+		if q, ok := ctx.Value(afterCommitKey(name)).(*afterCommit); ok {
+			return ctx, q, false
+		}
+	}
+	ctx, q = newAfterCommit(ctx, name)
+	return ctx, q, true
+}
+
+// newAfterCommit 为独立的新事务挂载回调队列
+func newAfterCommit(ctx context.Context, name string) (context.Context, *afterCommit) {
+	q := &afterCommit{}
+	return context.WithValue(ctx, afterCommitKey(name), q), q
+}
+
+// afterCommitOf db 使用上下文中的事务时返回该事务的回调队列，否则返回 nil
+func afterCommitOf(ctx context.Context, name string, db *gorm.DB) *afterCommit {
+	tx := GetTxFromContextOn(ctx, name)
+	if tx == nil || tx.Statement.ConnPool != db.Statement.ConnPool {
+		return nil
+	}
+	q, _ := ctx.Value(afterCommitKey(name)).(*afterCommit)
+	return q
+}
@@ -0,0 +1,88 @@
+package dbmysql
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestWriteHook(t *testing.T) {
+	db := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true})
+
+	var events []WriteEvent
+	repo := NewBaseRepository[batchModel]("id").OnWrite(func(_ context.Context, e WriteEvent) {
+		events = append(events, e)
+	})
+
+	if err := repo.InsertBatchWithDB(db, []batchModel{{ID: 1}, {ID: 2}, {ID: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.UpdateByIDsWithDB(db, []int64{3}, map[string]interface{}{"status": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("events = %+v", events)
+	}
+	if e := events[0]; e.Table != "batch_models" || e.Action != AuditCreate || !slices.Equal(e.IDs, []string{"1", "2"}) {
+		t.Fatalf("create event = %+v", e)
+	}
+	// 按主键写入直接使用已知主键，不查询受影响行
+	if e := events[1]; e.Action != AuditUpdate || !slices.Equal(e.IDs, []string{"3"}) {
+		t.Fatalf("update event = %+v", e)
+	}
+
+	// 条件写入在 DryRun 中查询不到受影响行，主键为空但不是 nil (nil 表示需要整体失效)
+	if _, err := repo.UpdateWhereWithDB(db, map[string]interface{}{"status": 1}, "status = ?", 0); err != nil {
+		t.Fatal(err)
+	}
+	if e := events[2]; e.IDs == nil || len(e.IDs) != 0 {
+		t.Fatalf("update where event = %+v", e)
+	}
+
+	plain := NewBaseRepository[batchModel]("id")
+	if ids, err := plain.hookIDsOf(db, []batchModel{{ID: 1}}); err != nil || ids != nil {
+		t.Fatalf("repo without hooks should not collect ids: %v %v", ids, err)
+	}
+}
+
+func TestWriteHookAfterCommit(t *testing.T) {
+	db := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true})
+
+	var events []WriteEvent
+	repo := NewBaseRepository[batchModel]("id").OnWrite(func(_ context.Context, e WriteEvent) {
+		events = append(events, e)
+	})
+
+	// 模拟 WithTx：上下文中挂载事务与回调队列
+	ctx, queue, outer := withAfterCommit(context.Background(), "")
+	if !outer {
+		t.Fatal("first transaction should own the queue")
+	}
+	ctx = context.WithValue(ctx, txKey, db)
+	if _, q, outer := withAfterCommit(ctx, ""); outer || q != queue {
+		t.Fatal("nested transaction should reuse the outer queue")
+	}
+
+	if err := repo.DeleteWithDB(db.WithContext(ctx), 7); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("hook fired before commit: %+v", events)
+	}
+	queue.run()
+	if len(events) != 1 || !slices.Equal(events[0].IDs, []string{"7"}) || events[0].Action != AuditDelete {
+		t.Fatalf("events after commit = %+v", events)
+	}
+
+	// 未使用上下文中的事务写入时立即回调
+	other := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true})
+	if err := repo.DeleteWithDB(other.WithContext(ctx), 8); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("write outside ctx tx should fire immediately: %+v", events)
+	}
+}
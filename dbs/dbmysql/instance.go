@@ -13,13 +13,13 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// 数据库连接单例
+// DefaultName 默认实例名，StartUp 初始化的连接以该名称注册
+const DefaultName = "default"
+
+// 默认数据库连接单例
 var (
-	dbc         atomic.Pointer[gorm.DB]
-	info        MysqlInfo
-	check       time.Duration
-	once        sync.Once
-	initialized atomic.Bool
+	std  = &Instance{name: DefaultName}
+	once sync.Once
 )
 
 // MysqlInfo MySQL 配置信息
@@ -35,21 +35,15 @@ type MysqlInfo struct {
 	MaxOpenConns    int           // 最大打开连接数，默认 100
 	ConnMaxLifetime time.Duration // 连接最大生命周期，默认 30s
 	ConnMaxIdleTime time.Duration // 空闲连接最大生命周期，默认 10m
-}
 
-// StartUp 初始化 MySQL 连接
-func StartUp(msqlInfo MysqlInfo, checkInterval time.Duration) {
-	once.Do(func() {
-		check = checkInterval
-		info = msqlInfo
-		setDefaultConfig()
-		connDB()
-		go checkConnection()
-	})
+	// 读写分离配置（可选）
+	Replicas      []ReplicaInfo // 从库列表，为空时读写均走主库；账号需 REPLICATION CLIENT 权限才能检查复制延迟
+	ReplicaPolicy ReplicaPolicy // 从库选择策略，默认轮询
+	MaxReplicaLag time.Duration // 从库最大允许复制延迟，超过则回退主库，默认 5s
 }
 
-// setDefaultConfig 设置默认配置
-func setDefaultConfig() {
+// setDefault 设置默认配置
+func (info *MysqlInfo) setDefault() {
 	if info.MaxIdleConns <= 0 {
 		info.MaxIdleConns = 20
 	}
@@ -62,19 +56,75 @@ func setDefaultConfig() {
 	if info.ConnMaxIdleTime <= 0 {
 		info.ConnMaxIdleTime = 10 * time.Minute
 	}
+	if info.MaxReplicaLag <= 0 {
+		info.MaxReplicaLag = 5 * time.Second
+	}
+}
+
+// Instance 单个数据库连接实例 (主库 + 可选从库)，带健康检查
+type Instance struct {
+	name        string
+	info        MysqlInfo
+	check       time.Duration
+	dbc         atomic.Pointer[gorm.DB]
+	initialized atomic.Bool
+	replicas    []*replica
+	replicaRR   atomic.Uint64
+	stop        chan struct{}
+}
+
+// StartUp 初始化默认 MySQL 连接
+func StartUp(msqlInfo MysqlInfo, checkInterval time.Duration) {
+	once.Do(func() {
+		if err := std.start(msqlInfo, checkInterval); err != nil {
+			panic(err.Error())
+		}
+		registry.Store(DefaultName, std)
+	})
+}
+
+// start 连接主库与从库并启动健康检查
+func (i *Instance) start(msqlInfo MysqlInfo, checkInterval time.Duration) error {
+	i.check = checkInterval
+	i.info = msqlInfo
+	i.info.setDefault()
+	i.stop = make(chan struct{})
+
+	if err := i.connDB(); err != nil {
+		return err
+	}
+	i.connReplicas()
+	if i.check > 0 {
+		go i.checkConnection()
+	}
+	return nil
 }
 
 // connDB 连接数据库
-func connDB() {
+func (i *Instance) connDB() error {
+	db, err := i.openDB(i.info.Address, i.info.User, i.info.Password)
+	if err != nil {
+		log.Errorf("[MYSQL] [%s] Database connection failed: %v", i.name, err)
+		return err
+	}
+	log.Infof("[MYSQL] [%s] Connected successfully: %s/%s", i.name, i.info.Address, i.info.DBName)
+
+	i.dbc.Store(db)
+	i.initialized.Store(true)
+	return nil
+}
+
+// openDB 按实例配置的库名与连接池参数打开连接
+func (i *Instance) openDB(address, user, password string) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&timeout=10s&readTimeout=30s&writeTimeout=60s",
-		info.User,
-		info.Password,
-		info.Address,
-		info.DBName,
+		user,
+		password,
+		address,
+		i.info.DBName,
 	)
 
 	config := &gorm.Config{
-		Logger:                                   info.Logger,
+		Logger:                                   i.info.Logger,
 		SkipDefaultTransaction:                   true, // 跳过默认事务，提升性能
 		PrepareStmt:                              true, // 缓存预编译语句
 		DisableForeignKeyConstraintWhenMigrating: true, // 迁移时禁用外键约束
@@ -82,49 +132,51 @@ func connDB() {
 
 	db, err := gorm.Open(mysql.Open(dsn), config)
 	if err != nil {
-		log.Errorf("[MYSQL] Database connection failed: %v", err)
-		panic(err.Error())
+		return nil, err
 	}
-	log.Infof("[MYSQL] Connected successfully: %s/%s", info.Address, info.DBName)
 
 	// 获取底层 sql.DB 设置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Errorf("[MYSQL] Failed to get sql.DB: %v", err)
-		panic(err.Error())
+		return nil, fmt.Errorf("get sql.DB: %w", err)
 	}
 
 	// 设置连接池参数
-	sqlDB.SetMaxIdleConns(info.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(info.MaxOpenConns)
-	sqlDB.SetConnMaxLifetime(info.ConnMaxLifetime)
-	sqlDB.SetConnMaxIdleTime(info.ConnMaxIdleTime)
+	sqlDB.SetMaxIdleConns(i.info.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(i.info.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(i.info.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(i.info.ConnMaxIdleTime)
 
-	dbc.Store(db)
-	initialized.Store(true)
+	return db, nil
 }
 
 // checkConnection 定期检查数据库连接
-func checkConnection() {
-	ticker := time.NewTicker(check)
+func (i *Instance) checkConnection() {
+	ticker := time.NewTicker(i.check)
 	defer ticker.Stop()
-	for range ticker.C {
-		if !isConnected() {
-			reconnectDB()
+	for {
+		select {
+		case <-i.stop:
+			return
+		case <-ticker.C:
+			if !i.IsConnected() {
+				i.reconnectDB()
+			}
+			i.checkReplicas()
 		}
 	}
 }
 
-// isConnected 检测数据库连接是否正常
-func isConnected() bool {
-	db := dbc.Load()
+// IsConnected 检测数据库连接是否正常
+func (i *Instance) IsConnected() bool {
+	db := i.dbc.Load()
 	if db == nil {
 		return false
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Errorf("[MYSQL] Failed to get sql.DB: %v", err)
+		log.Errorf("[MYSQL] [%s] Failed to get sql.DB: %v", i.name, err)
 		return false
 	}
 
@@ -132,82 +184,64 @@ func isConnected() bool {
 	defer cancel()
 
 	if err := sqlDB.PingContext(ctx); err != nil {
-		log.Errorf("[MYSQL] Connection lost: %v", err)
+		log.Errorf("[MYSQL] [%s] Connection lost: %v", i.name, err)
 		return false
 	}
 	return true
 }
 
 // reconnectDB 重连数据库
-func reconnectDB() {
-	log.Info("[MYSQL] Attempting to reconnect...")
-	
-	// 重新连接
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&timeout=10s&readTimeout=30s&writeTimeout=60s",
-		info.User,
-		info.Password,
-		info.Address,
-		info.DBName,
-	)
+func (i *Instance) reconnectDB() {
+	log.Infof("[MYSQL] [%s] Attempting to reconnect...", i.name)
 
-	config := &gorm.Config{
-		Logger:                                   info.Logger,
-		SkipDefaultTransaction:                   true,
-		PrepareStmt:                              true,
-		DisableForeignKeyConstraintWhenMigrating: true,
-	}
-
-	db, err := gorm.Open(mysql.Open(dsn), config)
-	if err != nil {
-		log.Errorf("[MYSQL] Reconnect failed: %v", err)
-		return
-	}
-
-	sqlDB, err := db.DB()
+	// 重新连接
+	db, err := i.openDB(i.info.Address, i.info.User, i.info.Password)
 	if err != nil {
-		log.Errorf("[MYSQL] Failed to get sql.DB: %v", err)
+		log.Errorf("[MYSQL] [%s] Reconnect failed: %v", i.name, err)
 		return
 	}
 
-	sqlDB.SetMaxIdleConns(info.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(info.MaxOpenConns)
-	sqlDB.SetConnMaxLifetime(info.ConnMaxLifetime)
-	sqlDB.SetConnMaxIdleTime(info.ConnMaxIdleTime)
-
-	dbc.Store(db)
-	log.Info("[MYSQL] Reconnected successfully")
+	i.dbc.Store(db)
+	log.Infof("[MYSQL] [%s] Reconnected successfully", i.name)
 }
 
-// Client 获取数据库客户端实例
-func Client() *gorm.DB {
-	if !initialized.Load() {
-		panic("[MYSQL] Client not initialized, call StartUp first")
-	}
-	return dbc.Load()
+// Name 实例名
+func (i *Instance) Name() string {
+	return i.name
 }
 
-// ClientWithContext 获取带上下文的数据库客户端
-func ClientWithContext(ctx context.Context) *gorm.DB {
-	db := dbc.Load()
-	if db == nil {
-		return nil
+// DB 获取主库客户端
+func (i *Instance) DB() *gorm.DB {
+	if !i.initialized.Load() {
+		panic(fmt.Sprintf("[MYSQL] [%s] Client not initialized", i.name))
 	}
-	return db.WithContext(ctx)
-}
-
-// IsConnected 检查连接是否正常
-func IsConnected() bool {
-	return isConnected()
+	return i.dbc.Load()
 }
 
 // IsInitialized 返回是否已初始化
-func IsInitialized() bool {
-	return initialized.Load()
+func (i *Instance) IsInitialized() bool {
+	return i.initialized.Load()
 }
 
-// Close 关闭数据库连接
-func Close() error {
-	db := dbc.Load()
+// Close 关闭主库与从库连接，并停止健康检查
+func (i *Instance) Close() error {
+	if i.stop != nil {
+		select {
+		case <-i.stop:
+		default:
+			close(i.stop)
+		}
+	}
+
+	for _, r := range i.replicas {
+		if db := r.db.Load(); db != nil {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		}
+	}
+
+	db := i.dbc.Load()
 	if db == nil {
 		return nil
 	}
@@ -220,8 +254,8 @@ func Close() error {
 }
 
 // Stats 获取连接池统计信息
-func Stats() map[string]interface{} {
-	db := dbc.Load()
+func (i *Instance) Stats() map[string]interface{} {
+	db := i.dbc.Load()
 	if db == nil {
 		return nil
 	}
@@ -243,3 +277,42 @@ func Stats() map[string]interface{} {
 		"max_lifetime_closed":  stats.MaxLifetimeClosed,
 	}
 }
+
+// ==================== 默认实例 ====================
+
+// Client 获取数据库客户端实例
+func Client() *gorm.DB {
+	if !std.initialized.Load() {
+		panic("[MYSQL] Client not initialized, call StartUp first")
+	}
+	return std.dbc.Load()
+}
+
+// ClientWithContext 获取带上下文的数据库客户端
+func ClientWithContext(ctx context.Context) *gorm.DB {
+	db := std.dbc.Load()
+	if db == nil {
+		return nil
+	}
+	return db.WithContext(ctx)
+}
+
+// IsConnected 检查连接是否正常
+func IsConnected() bool {
+	return std.IsConnected()
+}
+
+// IsInitialized 返回是否已初始化
+func IsInitialized() bool {
+	return std.IsInitialized()
+}
+
+// Close 关闭数据库连接
+func Close() error {
+	return std.Close()
+}
+
+// Stats 获取连接池统计信息
+func Stats() map[string]interface{} {
+	return std.Stats()
+}
@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lock 基于 MySQL GET_LOCK 的集群锁，锁与会话绑定，需在同一连接上加解锁
+type lock struct {
+	conn *sql.Conn
+	name string
+}
+
+// acquireLock 获取命名锁，timeout 内未获取到返回错误；持锁连接断开时锁自动释放
+func acquireLock(ctx context.Context, db *gorm.DB, name string, timeout time.Duration) (*lock, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var got sql.NullInt64
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, int(timeout.Seconds())).Scan(&got); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !got.Valid || got.Int64 != 1 {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %s", ErrLocked, name)
+	}
+	return &lock{conn: conn, name: name}, nil
+}
+
+// release 释放锁并归还连接
+func (l *lock) release() error {
+	defer l.conn.Close()
+	_, err := l.conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", l.name)
+	return err
+}
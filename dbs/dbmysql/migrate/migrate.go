@@ -0,0 +1,322 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/caoyuewen/components/util"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	ErrLocked = errors.New("migrate: locked by another instance")
+	ErrDirty  = errors.New("migrate: dirty version, fix schema manually and call Force")
+)
+
+// Options 迁移配置
+type Options struct {
+	Table       string        // 版本表名，默认 schema_migrations
+	LockName    string        // 集群锁名，默认 <库名>.<版本表名>
+	LockTimeout time.Duration // 等待锁超时，默认 60s
+	DryRun      bool          // 只输出将要执行的迁移与 SQL，不实际执行
+	Out         io.Writer     // 执行过程输出，默认 os.Stdout
+}
+
+// Status 迁移状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool  // 执行中断，需人工修复
+	Missing   bool  // 已执行但代码中不存在
+	AppliedAt int64 // 执行时间 (毫秒)
+}
+
+// record 版本表记录
+type record struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"type:varchar(255);not null"`
+	Dirty     bool   `gorm:"not null"`
+	AppliedAt int64  `gorm:"type:BIGINT;not null"`
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	opts       Options
+	migrations []Migration
+}
+
+// New 创建迁移执行器，migrations 按版本排序，版本号必须唯一且大于 0
+func New(db *gorm.DB, opts Options, migrations ...Migration) (*Migrator, error) {
+	if opts.Table == "" {
+		opts.Table = "schema_migrations"
+	}
+	if opts.LockName == "" {
+		opts.LockName = db.Migrator().CurrentDatabase() + "." + opts.Table
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = 60 * time.Second
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+
+	list := append([]Migration(nil), migrations...)
+	sortMigrations(list)
+	for i, m := range list {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migrate: invalid version %d (%s)", m.Version, m.Name)
+		}
+		if i > 0 && list[i-1].Version == m.Version {
+			return nil, fmt.Errorf("migrate: duplicate version %d (%s, %s)", m.Version, list[i-1].Name, m.Name)
+		}
+		if !m.hasUp() {
+			return nil, fmt.Errorf("migrate: version %d (%s) has no up step", m.Version, m.Name)
+		}
+	}
+
+	return &Migrator{db: db, opts: opts, migrations: list}, nil
+}
+
+// Status 列出所有迁移及执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if r, ok := applied[mig.Version]; ok {
+			s.Applied, s.Dirty, s.AppliedAt = true, r.Dirty, r.AppliedAt
+			delete(applied, mig.Version)
+		}
+		res = append(res, s)
+	}
+	for _, r := range applied {
+		res = append(res, Status{Version: r.Version, Name: r.Name, Applied: true, Dirty: r.Dirty, Missing: true, AppliedAt: r.AppliedAt})
+	}
+	return res, nil
+}
+
+// Up 执行所有版本号 <= target 的未执行迁移，target <= 0 表示执行到最新
+func (m *Migrator) Up(ctx context.Context, target int64) error {
+	return m.locked(ctx, func(applied map[int64]record) error {
+		for _, mig := range m.migrations {
+			if target > 0 && mig.Version > target {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.run(ctx, mig, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down 回滚最近执行的 steps 个迁移，steps <= 0 时回滚 1 个
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		steps = 1
+	}
+	return m.locked(ctx, func(applied map[int64]record) error {
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if !mig.hasDown() {
+				return fmt.Errorf("migrate: version %d (%s) can not be rolled back", mig.Version, mig.Name)
+			}
+			if err := m.run(ctx, mig, false); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// Force 清除版本的 dirty 标记并视为已执行，用于人工修复中断的迁移后
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	l, err := acquireLock(ctx, m.db, m.opts.LockName, m.opts.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer l.release()
+
+	name := ""
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			name = mig.Name
+		}
+	}
+	return m.table(ctx).Save(&record{Version: version, Name: name, AppliedAt: time.Now().UnixMilli()}).Error
+}
+
+// locked 持集群锁执行，dry-run 时不加锁也不建表
+func (m *Migrator) locked(ctx context.Context, fn func(applied map[int64]record) error) error {
+	if !m.opts.DryRun {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		l, err := acquireLock(ctx, m.db, m.opts.LockName, m.opts.LockTimeout)
+		if err != nil {
+			return err
+		}
+		defer l.release()
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range applied {
+		if r.Dirty {
+			return fmt.Errorf("%w: %d (%s)", ErrDirty, r.Version, r.Name)
+		}
+	}
+	return fn(applied)
+}
+
+// run 执行单个迁移；执行前写入 dirty 记录，成功后清除 (up) 或删除 (down)
+func (m *Migrator) run(ctx context.Context, mig Migration, up bool) error {
+	direction, sqlText, fn := "up", mig.UpSQL, mig.Up
+	if !up {
+		direction, sqlText, fn = "down", mig.DownSQL, mig.Down
+	}
+	statements := splitStatements(sqlText)
+
+	fmt.Fprintf(m.opts.Out, "-- %s %d_%s\n", direction, mig.Version, mig.Name)
+	if m.opts.DryRun {
+		for _, stmt := range statements {
+			fmt.Fprintf(m.opts.Out, "%s;\n", stmt)
+		}
+		if fn != nil {
+			m.dryRunGo(ctx, fn)
+		}
+		return nil
+	}
+
+	start := time.Now()
+	rec := record{Version: mig.Version, Name: mig.Name, Dirty: true, AppliedAt: start.UnixMilli()}
+	if err := m.table(ctx).Save(&rec).Error; err != nil {
+		return err
+	}
+
+	// MySQL DDL 会隐式提交，事务仅保证 DML 原子性
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("exec %q: %w", stmt, err)
+			}
+		}
+		if fn != nil {
+			return fn(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[MYSQL] migrate %s %d_%s failed: %v", direction, mig.Version, mig.Name, err)
+		return fmt.Errorf("migrate: %s %d (%s): %w", direction, mig.Version, mig.Name, err)
+	}
+
+	if up {
+		err = m.table(ctx).Where("version = ?", mig.Version).Update("dirty", false).Error
+	} else {
+		err = m.table(ctx).Where("version = ?", mig.Version).Delete(&record{}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Infof("[MYSQL] migrate %s %d_%s done, cost: %s", direction, mig.Version, mig.Name, time.Since(start))
+	return nil
+}
+
+// dryRunGo 在 DryRun 会话中执行 Go 迁移并输出捕获的写语句
+// DryRun 不访问数据库，迁移中的查询返回空结果，依赖查询结果生成的语句可能与实际执行不同
+func (m *Migrator) dryRunGo(ctx context.Context, fn func(tx *gorm.DB) error) {
+	fmt.Fprintln(m.opts.Out, "-- (go migration, dry-run captured)")
+	tx := m.db.Session(&gorm.Session{
+		DryRun:                 true,
+		NewDB:                  true,
+		SkipDefaultTransaction: true,
+		Logger:                 &captureLogger{Interface: logger.Discard, out: m.opts.Out},
+	}).WithContext(ctx)
+
+	var err error
+	util.TryCatch(func() {
+		err = fn(tx)
+	}, func(r interface{}) {
+		err = fmt.Errorf("panic: %v", r)
+	})
+	if err != nil {
+		fmt.Fprintf(m.opts.Out, "-- go migration returned error in dry-run: %v\n", err)
+	}
+}
+
+// captureLogger 将 GORM 生成的非查询语句写入 out
+type captureLogger struct {
+	logger.Interface
+	out io.Writer
+}
+
+func (l *captureLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l *captureLogger) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	sql = strings.TrimSpace(sql)
+	if sql == "" || isQuery(sql) {
+		return
+	}
+	fmt.Fprintf(l.out, "%s;\n", sql)
+}
+
+// isQuery 是否只读查询 (包括 Migrator 内部检查表结构的查询)
+func isQuery(sql string) bool {
+	head := strings.ToUpper(strings.Fields(sql)[0])
+	return head == "SELECT" || head == "SHOW" || head == "DESCRIBE" || head == "EXPLAIN"
+}
+
+// applied 已执行的版本，版本表不存在时返回空
+func (m *Migrator) applied(ctx context.Context) (map[int64]record, error) {
+	res := map[int64]record{}
+	if !m.db.WithContext(ctx).Migrator().HasTable(m.opts.Table) {
+		return res, nil
+	}
+
+	var list []record
+	if err := m.table(ctx).Order("version").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range list {
+		res[r.Version] = r
+	}
+	return res, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	return m.db.WithContext(ctx).Table(m.opts.Table).AutoMigrate(&record{})
+}
+
+func (m *Migrator) table(ctx context.Context) *gorm.DB {
+	return m.db.WithContext(ctx).Table(m.opts.Table)
+}
@@ -0,0 +1,153 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Migration 单个版本迁移，SQL 与 Go 函数二选一 (同时设置时先执行 SQL)
+type Migration struct {
+	Version int64  // 版本号，递增且唯一
+	Name    string // 描述
+
+	UpSQL   string // 升级 SQL，支持多条语句
+	DownSQL string // 回滚 SQL
+
+	Up   func(tx *gorm.DB) error // Go 升级函数
+	Down func(tx *gorm.DB) error // Go 回滚函数
+}
+
+// hasUp 是否有升级步骤
+func (m Migration) hasUp() bool {
+	return m.UpSQL != "" || m.Up != nil
+}
+
+// hasDown 是否可以回滚
+func (m Migration) hasDown() bool {
+	return m.DownSQL != "" || m.Down != nil
+}
+
+// sqlFileRe 迁移文件名: 0001_create_goods_order.up.sql / 0001_create_goods_order.down.sql
+var sqlFileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// LoadFS 从目录读取 SQL 迁移文件，支持 embed.FS 与 os.DirFS
+func LoadFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := sqlFileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s, %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.UpSQL = string(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if !m.hasUp() {
+			return nil, fmt.Errorf("migration %d_%s missing up file", m.Version, m.Name)
+		}
+		res = append(res, *m)
+	}
+	sortMigrations(res)
+	return res, nil
+}
+
+func sortMigrations(list []Migration) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+}
+
+// splitStatements 按分号拆分多条 SQL，忽略引号与注释中的分号
+func splitStatements(sql string) []string {
+	var (
+		res   []string
+		buf   strings.Builder
+		quote rune
+	)
+
+	flush := func() {
+		if s := strings.TrimSpace(buf.String()); s != "" {
+			res = append(res, s)
+		}
+		buf.Reset()
+	}
+
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if quote != 0 {
+			buf.WriteRune(c)
+			if c == '\\' && quote != '`' && i+1 < len(runes) {
+				i++
+				buf.WriteRune(runes[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			buf.WriteRune(c)
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-', c == '#':
+			// 单行注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			buf.WriteRune('\n')
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// 块注释，/*! */ 为 MySQL 条件执行语句需保留
+			keep := i+2 < len(runes) && runes[i+2] == '!'
+			start := i
+			for i += 2; i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/'); i++ {
+			}
+			i++
+			if keep {
+				buf.WriteString(string(runes[start:min(i+1, len(runes))]))
+			} else {
+				buf.WriteRune(' ')
+			}
+		case c == ';':
+			flush()
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	flush()
+	return res
+}
@@ -0,0 +1,84 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestSplitStatements(t *testing.T) {
+	sql := `-- 建表; 注释中的分号
+CREATE TABLE a (id INT, note VARCHAR(10) DEFAULT 'x;y');
+/* 块注释; */ INSERT INTO a VALUES (1, "it\"s;");
+/*!40101 SET NAMES utf8mb4 */;
+# 结尾注释`
+
+	want := []string{
+		"CREATE TABLE a (id INT, note VARCHAR(10) DEFAULT 'x;y')",
+		`INSERT INTO a VALUES (1, "it\"s;")`,
+		"/*!40101 SET NAMES utf8mb4 */",
+	}
+	if got := splitStatements(sql); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_index.up.sql":  {Data: []byte("CREATE INDEX i ON a (id);")},
+		"m/0001_init.up.sql":       {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_init.down.sql":     {Data: []byte("DROP TABLE a;")},
+		"m/README.md":              {Data: []byte("ignored")},
+		"m/0003_broken.down.sql":   {Data: []byte("DROP INDEX i ON a;")},
+		"other/0004_skip.up.sql":   {Data: []byte("SELECT 1;")},
+		"m/sub/0005_nested.up.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	if _, err := LoadFS(fsys, "m"); err == nil {
+		t.Fatal("want error for migration without up file")
+	}
+
+	delete(fsys, "m/0003_broken.down.sql")
+	list, err := LoadFS(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Version != 1 || list[1].Version != 2 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if list[0].Name != "init" || !list[0].hasDown() || list[1].hasDown() {
+		t.Fatalf("unexpected migration: %+v", list)
+	}
+}
+
+func TestDryRunGoMigration(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "u:p@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	m := &Migrator{db: db, opts: Options{DryRun: true, Out: &out}}
+	mig := Migration{Version: 3, Name: "backfill", Up: func(tx *gorm.DB) error {
+		var n int64
+		tx.Table("a").Count(&n)
+		return tx.Exec("UPDATE a SET status = ? WHERE status = ?", 2, 1).Error
+	}}
+	if err := m.run(context.Background(), mig, true); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "UPDATE a SET status = 2 WHERE status = 1;") || strings.Contains(got, "SELECT") {
+		t.Fatalf("unexpected dry-run output:\n%s", got)
+	}
+}
@@ -0,0 +1,100 @@
+package dbmysql
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// registry 命名连接注册表 name -> *Instance，默认实例在 StartUp 后以 DefaultName 注册
+var registry sync.Map
+
+// Register 初始化并注册命名连接，checkInterval <= 0 时不做健康检查
+func Register(name string, msqlInfo MysqlInfo, checkInterval time.Duration) (*Instance, error) {
+	if name == "" || name == DefaultName {
+		return nil, fmt.Errorf("[MYSQL] invalid instance name: %q", name)
+	}
+	if _, ok := registry.Load(name); ok {
+		return nil, fmt.Errorf("[MYSQL] instance already registered: %s", name)
+	}
+
+	ins := &Instance{name: name}
+	if err := ins.start(msqlInfo, checkInterval); err != nil {
+		return nil, err
+	}
+	if _, loaded := registry.LoadOrStore(name, ins); loaded {
+		ins.Close()
+		return nil, fmt.Errorf("[MYSQL] instance already registered: %s", name)
+	}
+	return ins, nil
+}
+
+// Get 获取命名连接实例，name 为空时返回默认实例
+func Get(name string) (*Instance, bool) {
+	if name == "" {
+		name = DefaultName
+	}
+	v, ok := registry.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Instance), true
+}
+
+// MustGet 获取命名连接实例，不存在时 panic
+func MustGet(name string) *Instance {
+	ins, ok := Get(name)
+	if !ok {
+		panic(fmt.Sprintf("[MYSQL] instance not registered: %s", name))
+	}
+	return ins
+}
+
+// Use 获取命名连接的主库客户端，name 为空时返回默认实例
+func Use(name string) *gorm.DB {
+	if name == "" || name == DefaultName {
+		return Client()
+	}
+	return MustGet(name).DB()
+}
+
+// UseRead 获取命名连接的读库客户端，name 为空时返回默认实例
+func UseRead(name string) *gorm.DB {
+	if name == "" || name == DefaultName {
+		return ReadClient()
+	}
+	return MustGet(name).ReadDB()
+}
+
+// Unregister 关闭并移除命名连接，默认实例请使用 Close
+func Unregister(name string) error {
+	if name == DefaultName {
+		return fmt.Errorf("[MYSQL] can not unregister default instance")
+	}
+	v, ok := registry.LoadAndDelete(name)
+	if !ok {
+		return nil
+	}
+	return v.(*Instance).Close()
+}
+
+// Instances 所有已注册实例
+func Instances() map[string]*Instance {
+	res := map[string]*Instance{}
+	registry.Range(func(k, v any) bool {
+		res[k.(string)] = v.(*Instance)
+		return true
+	})
+	return res
+}
+
+// AllStats 所有实例的连接池统计信息
+func AllStats() map[string]map[string]interface{} {
+	res := map[string]map[string]interface{}{}
+	for name, ins := range Instances() {
+		res[name] = ins.Stats()
+	}
+	return res
+}
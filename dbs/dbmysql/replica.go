@@ -0,0 +1,262 @@
+package dbmysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ReplicaInfo 从库配置，库名与连接池参数沿用主库
+type ReplicaInfo struct {
+	Address  string // 从库地址 host:port
+	User     string // 用户名，为空时沿用主库
+	Password string // 密码，User 为空时沿用主库
+}
+
+// ReplicaPolicy 从库选择策略
+type ReplicaPolicy int
+
+const (
+	ReplicaRoundRobin ReplicaPolicy = iota // 轮询
+	ReplicaRandom                          // 随机
+)
+
+// replica 从库运行时状态
+type replica struct {
+	address  string
+	user     string
+	password string
+	db       atomic.Pointer[gorm.DB] // 启动时连接失败为 nil，由健康检查重连
+	healthy  atomic.Bool
+	lag      atomic.Int64 // 复制延迟 (秒)，-1 表示未知
+}
+
+var errReplicationStopped = errors.New("replication stopped")
+
+// errNoReplicationPrivilege 账号缺少 REPLICATION CLIENT 权限，无法查询复制延迟
+var errNoReplicationPrivilege = errors.New("missing REPLICATION CLIENT privilege")
+
+// mysqlErrSpecificAccessDenied ER_SPECIFIC_ACCESS_DENIED_ERROR
+const mysqlErrSpecificAccessDenied = 1227
+
+// primaryKeyType 强制主库上下文键类型
+type primaryKeyType struct{}
+
+var primaryKey = primaryKeyType{}
+
+// connReplicas 连接从库，连接失败的从库标记为不可用，由健康检查重连恢复
+func (i *Instance) connReplicas() {
+	for _, r := range i.info.Replicas {
+		user, password := r.User, r.Password
+		if user == "" {
+			user, password = i.info.User, i.info.Password
+		}
+
+		rep := &replica{address: r.Address, user: user, password: password}
+		rep.lag.Store(-1)
+		i.replicas = append(i.replicas, rep)
+
+		db, err := i.openDB(r.Address, user, password)
+		if err != nil {
+			log.Errorf("[MYSQL] [%s] Replica connection failed: %s, %v", i.name, r.Address, err)
+			continue
+		}
+		rep.db.Store(db)
+		log.Infof("[MYSQL] [%s] Replica connected: %s/%s", i.name, r.Address, i.info.DBName)
+	}
+	i.checkReplicas()
+}
+
+// checkReplicas 检查从库连通性与复制延迟，未连接的从库尝试重连
+// 查询延迟需要 REPLICATION CLIENT 权限，缺少权限时只检查连通性，延迟记为未知
+func (i *Instance) checkReplicas() {
+	for _, r := range i.replicas {
+		db := r.db.Load()
+		if db == nil {
+			var err error
+			if db, err = i.openDB(r.address, r.user, r.password); err != nil {
+				log.Debugf("[MYSQL] [%s] Replica reconnect failed: %s, %v", i.name, r.address, err)
+				continue
+			}
+			r.db.Store(db)
+			log.Infof("[MYSQL] [%s] Replica connected: %s/%s", i.name, r.address, i.info.DBName)
+		}
+
+		lag, err := replicaLag(db)
+		if errors.Is(err, errNoReplicationPrivilege) {
+			r.lag.Store(-1)
+			if !r.healthy.Swap(true) {
+				log.Warnf("[MYSQL] [%s] Replica %s: %v, lag check disabled", i.name, r.address, err)
+			}
+			continue
+		}
+		if err != nil {
+			if r.healthy.Swap(false) {
+				log.Errorf("[MYSQL] [%s] Replica unavailable: %s, %v", i.name, r.address, err)
+			}
+			r.lag.Store(-1)
+			continue
+		}
+
+		r.lag.Store(int64(lag / time.Second))
+		ok := lag <= i.info.MaxReplicaLag
+		if r.healthy.Swap(ok) != ok {
+			if ok {
+				log.Infof("[MYSQL] [%s] Replica recovered: %s", i.name, r.address)
+			} else {
+				log.Warnf("[MYSQL] [%s] Replica lag too high: %s, lag: %s", i.name, r.address, lag)
+			}
+		}
+	}
+}
+
+// replicaLag 查询从库复制延迟，复制线程停止时返回错误
+// 连通但缺少 REPLICATION CLIENT 权限时返回 errNoReplicationPrivilege
+func replicaLag(db *gorm.DB) (time.Duration, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err = sqlDB.PingContext(ctx); err != nil {
+		return 0, err
+	}
+
+	// MySQL 8.0.22+ 使用 SHOW REPLICA STATUS，旧版本回退 SHOW SLAVE STATUS
+	rows, err := sqlDB.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = sqlDB.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			var me *mysql.MySQLError
+			if errors.As(err, &me) && me.Number == mysqlErrSpecificAccessDenied {
+				return 0, errNoReplicationPrivilege
+			}
+			return 0, err
+		}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		// 非复制节点 (如云数据库只读代理)，视为无延迟
+		return 0, rows.Err()
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err = rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, col := range columns {
+		if col != "Seconds_Behind_Source" && col != "Seconds_Behind_Master" {
+			continue
+		}
+		if values[i] == nil {
+			return 0, errReplicationStopped
+		}
+		sec, err := strconv.ParseInt(string(values[i]), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(sec) * time.Second, nil
+	}
+	return 0, nil
+}
+
+// pickReplica 按策略选择可用从库，无可用从库时返回 nil
+func (i *Instance) pickReplica() *replica {
+	n := len(i.replicas)
+	if n == 0 {
+		return nil
+	}
+
+	start := 0
+	if i.info.ReplicaPolicy == ReplicaRandom {
+		start = rand.IntN(n)
+	} else {
+		start = int(i.replicaRR.Add(1) % uint64(n))
+	}
+
+	for k := 0; k < n; k++ {
+		if r := i.replicas[(start+k)%n]; r.healthy.Load() && r.db.Load() != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// ==================== 读写路由 ====================
+
+// ReadDB 获取读库客户端，无可用从库时回退主库
+func (i *Instance) ReadDB() *gorm.DB {
+	if r := i.pickReplica(); r != nil {
+		return r.db.Load()
+	}
+	return i.DB()
+}
+
+// ReplicaStats 获取从库状态
+func (i *Instance) ReplicaStats() []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(i.replicas))
+	for _, r := range i.replicas {
+		res = append(res, map[string]interface{}{
+			"address":     r.address,
+			"healthy":     r.healthy.Load(),
+			"lag_seconds": r.lag.Load(),
+		})
+	}
+	return res
+}
+
+// ReadClient 获取默认实例的读库客户端，无可用从库时回退主库
+func ReadClient() *gorm.DB {
+	if !std.initialized.Load() {
+		panic("[MYSQL] Client not initialized, call StartUp first")
+	}
+	return std.ReadDB()
+}
+
+// WithPrimary 标记上下文强制走主库，用于写后立即读等需要强一致的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey, true)
+}
+
+// IsPrimaryPinned 上下文是否强制走主库
+func IsPrimaryPinned(ctx context.Context) bool {
+	pinned, _ := ctx.Value(primaryKey).(bool)
+	return pinned
+}
+
+// GetReadDB 获取读连接：事务中使用事务，强制主库时使用主库，否则使用从库
+func GetReadDB(ctx context.Context) *gorm.DB {
+	if tx := GetTxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if IsPrimaryPinned(ctx) {
+		return Client().WithContext(ctx)
+	}
+	return ReadClient().WithContext(ctx)
+}
+
+// ReplicaStats 获取默认实例的从库状态
+func ReplicaStats() []map[string]interface{} {
+	return std.ReplicaStats()
+}
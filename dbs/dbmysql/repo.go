@@ -14,6 +14,9 @@ import (
 // BaseRepository 通用 Repository 基类，支持泛型
 type BaseRepository[T any] struct {
 	pkColumn string
+	conn     string // 命名连接，为空时使用默认实例
+	audit    bool   // 是否记录审计日志
+	hooks    []WriteHook
 }
 
 // NewBaseRepository 创建新的 BaseRepository 实例
@@ -21,16 +24,40 @@ func NewBaseRepository[T any](pkColumn string) BaseRepository[T] {
 	return BaseRepository[T]{pkColumn: pkColumn}
 }
 
+// NewBaseRepositoryOn 创建绑定命名连接的 BaseRepository 实例
+func NewBaseRepositoryOn[T any](conn, pkColumn string) BaseRepository[T] {
+	return BaseRepository[T]{pkColumn: pkColumn, conn: conn}
+}
+
+// On 返回绑定到命名连接的副本
+func (r BaseRepository[T]) On(conn string) BaseRepository[T] {
+	r.conn = conn
+	return r
+}
+
+// DB 绑定连接的主库客户端
+func (r *BaseRepository[T]) DB() *gorm.DB {
+	return Use(r.conn)
+}
+
+// ReadDB 绑定连接的读库客户端
+func (r *BaseRepository[T]) ReadDB() *gorm.DB {
+	return UseRead(r.conn)
+}
+
 // ==================== 创建操作 ====================
 
 // Insert 插入单条记录
 func (r *BaseRepository[T]) Insert(obj T) error {
-	return r.InsertWithDB(Client(), obj)
+	return r.InsertWithDB(r.DB(), obj)
 }
 
 // InsertWithDB 使用指定 DB 插入（支持事务）
 func (r *BaseRepository[T]) InsertWithDB(db *gorm.DB, obj T) error {
-	if err := db.Create(&obj).Error; err != nil {
+	objs := []T{obj}
+	if err := r.auditCreate(db, objs, func(tx *gorm.DB) error {
+		return tx.Create(&objs[0]).Error
+	}); err != nil {
 		log.Errorf("Insert err: %s", err.Error())
 		return err
 	}
@@ -39,7 +66,7 @@ func (r *BaseRepository[T]) InsertWithDB(db *gorm.DB, obj T) error {
 
 // InsertBatch 批量插入
 func (r *BaseRepository[T]) InsertBatch(objs []T) error {
-	return r.InsertBatchWithDB(Client(), objs)
+	return r.InsertBatchWithDB(r.DB(), objs)
 }
 
 // InsertBatchWithDB 使用指定 DB 批量插入
@@ -47,7 +74,9 @@ func (r *BaseRepository[T]) InsertBatchWithDB(db *gorm.DB, objs []T) error {
 	if len(objs) == 0 {
 		return nil
 	}
-	if err := db.CreateInBatches(objs, 100).Error; err != nil {
+	if err := r.auditCreate(db, objs, func(tx *gorm.DB) error {
+		return tx.CreateInBatches(objs, 100).Error
+	}); err != nil {
 		log.Errorf("InsertBatch err: %s", err.Error())
 		return err
 	}
@@ -56,15 +85,21 @@ func (r *BaseRepository[T]) InsertBatchWithDB(db *gorm.DB, objs []T) error {
 
 // InsertOrUpdate 插入或更新（Upsert）
 func (r *BaseRepository[T]) InsertOrUpdate(obj T, updateColumns []string) error {
-	return r.InsertOrUpdateWithDB(Client(), obj, updateColumns)
+	return r.InsertOrUpdateWithDB(r.DB(), obj, updateColumns)
 }
 
 // InsertOrUpdateWithDB 使用指定 DB 插入或更新
 func (r *BaseRepository[T]) InsertOrUpdateWithDB(db *gorm.DB, obj T, updateColumns []string) error {
-	if err := db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: r.pkColumn}},
-		DoUpdates: clause.AssignmentColumns(updateColumns),
-	}).Create(&obj).Error; err != nil {
+	ids, err := r.pkValues(db, []T{obj})
+	if err != nil {
+		return err
+	}
+	if err := r.auditWriteByPK(db, AuditUpdate, ids, r.objScope(db, obj), func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: r.pkColumn}},
+			DoUpdates: clause.AssignmentColumns(updateColumns),
+		}).Create(&obj).Error
+	}); err != nil {
 		log.Errorf("InsertOrUpdate err: %s", err.Error())
 		return err
 	}
@@ -73,23 +108,32 @@ func (r *BaseRepository[T]) InsertOrUpdateWithDB(db *gorm.DB, obj T, updateColum
 
 // ==================== 更新操作 ====================
 
-// Update 根据主键更新整个对象
+// Update 根据主键更新整个对象；模型声明版本字段时版本号同时 +1 (不校验版本，需要校验时使用 UpdateVersioned)
 func (r *BaseRepository[T]) Update(obj T) error {
-	return r.UpdateWithDB(Client(), obj)
+	return r.UpdateWithDB(r.DB(), obj)
 }
 
 // UpdateWithDB 使用指定 DB 更新
 func (r *BaseRepository[T]) UpdateWithDB(db *gorm.DB, obj T) error {
-	if err := db.Save(&obj).Error; err != nil {
+	ids, err := r.pkValues(db, []T{obj})
+	if err != nil {
+		return err
+	}
+	if err := r.auditWriteByPK(db, AuditUpdate, ids, r.objScope(db, obj), func(tx *gorm.DB) error {
+		if values, ok := versionedValues(tx, &obj); ok {
+			return tx.Model(&obj).Updates(values).Error
+		}
+		return tx.Save(&obj).Error
+	}); err != nil {
 		log.Errorf("Update err: %s", err.Error())
 		return err
 	}
 	return nil
 }
 
-// UpdateByID 根据 ID 更新指定字段
+// UpdateByID 根据 ID 更新指定字段，模型声明版本字段时版本号同时 +1
 func (r *BaseRepository[T]) UpdateByID(id any, updates map[string]interface{}) (int64, error) {
-	return r.UpdateByIDWithDB(Client(), id, updates)
+	return r.UpdateByIDWithDB(r.DB(), id, updates)
 }
 
 // UpdateByIDWithDB 使用指定 DB 根据 ID 更新
@@ -101,17 +145,24 @@ func (r *BaseRepository[T]) UpdateByIDWithDB(db *gorm.DB, id any, updates map[st
 		return 0, err
 	}
 
-	result := db.Model(&t).Where("id = ?", idI64).Updates(updates)
-	if result.Error != nil {
-		log.Errorf("UpdateByID err: %s", result.Error)
-		return 0, result.Error
+	var rows int64
+	err = r.auditWriteByPK(db, AuditUpdate, []interface{}{idI64}, func(q *gorm.DB) *gorm.DB {
+		return q.Where("id = ?", idI64)
+	}, func(tx *gorm.DB) error {
+		result := tx.Model(&t).Where("id = ?", idI64).Updates(withVersionBump[T](tx, updates))
+		rows = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Errorf("UpdateByID err: %s", err)
+		return 0, err
 	}
-	return result.RowsAffected, nil
+	return rows, nil
 }
 
-// UpdateByIDs 根据多个 ID 批量更新
+// UpdateByIDs 根据多个 ID 批量更新，模型声明版本字段时版本号同时 +1
 func (r *BaseRepository[T]) UpdateByIDs(ids []int64, updates map[string]interface{}) (int64, error) {
-	return r.UpdateByIDsWithDB(Client(), ids, updates)
+	return r.UpdateByIDsWithDB(r.DB(), ids, updates)
 }
 
 // UpdateByIDsWithDB 使用指定 DB 批量更新
@@ -121,32 +172,48 @@ func (r *BaseRepository[T]) UpdateByIDsWithDB(db *gorm.DB, ids []int64, updates
 		return 0, nil
 	}
 
-	result := db.Model(&t).Where("id IN ?", ids).Updates(updates)
-	if result.Error != nil {
-		log.Errorf("UpdateByIDs err: %s", result.Error)
-		return 0, result.Error
+	var rows int64
+	err := r.auditWriteByPK(db, AuditUpdate, int64Values(ids), func(q *gorm.DB) *gorm.DB {
+		return q.Where("id IN ?", ids)
+	}, func(tx *gorm.DB) error {
+		result := tx.Model(&t).Where("id IN ?", ids).Updates(withVersionBump[T](tx, updates))
+		rows = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Errorf("UpdateByIDs err: %s", err)
+		return 0, err
 	}
-	return result.RowsAffected, nil
+	return rows, nil
 }
 
-// UpdateWhere 根据条件更新
+// UpdateWhere 根据条件更新，模型声明版本字段时版本号同时 +1
 func (r *BaseRepository[T]) UpdateWhere(updates map[string]interface{}, conds ...interface{}) (int64, error) {
-	return r.UpdateWhereWithDB(Client(), updates, conds...)
+	return r.UpdateWhereWithDB(r.DB(), updates, conds...)
 }
 
 // UpdateWhereWithDB 使用指定 DB 根据条件更新
 func (r *BaseRepository[T]) UpdateWhereWithDB(db *gorm.DB, updates map[string]interface{}, conds ...interface{}) (int64, error) {
-	var t T
-	query := db.Model(&t)
-	for _, cond := range conds {
-		query = query.Where(cond)
+	var (
+		t    T
+		rows int64
+	)
+	scope := func(q *gorm.DB) *gorm.DB {
+		for _, cond := range conds {
+			q = q.Where(cond)
+		}
+		return q
 	}
-	result := query.Updates(updates)
-	if result.Error != nil {
-		log.Errorf("UpdateWhere err: %s", result.Error)
-		return 0, result.Error
+	err := r.auditWrite(db, AuditUpdate, scope, func(tx *gorm.DB) error {
+		result := scope(tx.Model(&t)).Updates(withVersionBump[T](tx, updates))
+		rows = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Errorf("UpdateWhere err: %s", err)
+		return 0, err
 	}
-	return result.RowsAffected, nil
+	return rows, nil
 }
 
 // UpdateMany 根据多个条件更新（旧方法保持兼容）
@@ -156,25 +223,34 @@ func (r *BaseRepository[T]) UpdateMany(conds []interface{}, updates map[string]i
 
 // UpdateWhereRaw 使用原始 SQL 条件更新
 func (r *BaseRepository[T]) UpdateWhereRaw(whereSQL string, args []any, updates map[string]interface{}) (int64, error) {
-	return r.UpdateWhereRawWithDB(Client(), whereSQL, args, updates)
+	return r.UpdateWhereRawWithDB(r.DB(), whereSQL, args, updates)
 }
 
 // UpdateWhereRawWithDB 使用指定 DB 原始 SQL 更新
 func (r *BaseRepository[T]) UpdateWhereRawWithDB(db *gorm.DB, whereSQL string, args []any, updates map[string]interface{}) (int64, error) {
-	var t T
-	result := db.Model(&t).Where(whereSQL, args...).Updates(updates)
-	if result.Error != nil {
-		log.Errorf("UpdateWhereRaw err: %s", result.Error)
-		return 0, result.Error
+	var (
+		t    T
+		rows int64
+	)
+	err := r.auditWrite(db, AuditUpdate, func(q *gorm.DB) *gorm.DB {
+		return q.Where(whereSQL, args...)
+	}, func(tx *gorm.DB) error {
+		result := tx.Model(&t).Where(whereSQL, args...).Updates(updates)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Errorf("UpdateWhereRaw err: %s", err)
+		return 0, err
 	}
-	return result.RowsAffected, nil
+	return rows, nil
 }
 
 // ==================== 删除操作 ====================
 
 // Delete 根据 ID 删除
 func (r *BaseRepository[T]) Delete(id any) error {
-	return r.DeleteWithDB(Client(), id)
+	return r.DeleteWithDB(r.DB(), id)
 }
 
 // DeleteWithDB 使用指定 DB 删除
@@ -185,7 +261,11 @@ func (r *BaseRepository[T]) DeleteWithDB(db *gorm.DB, id any) error {
 		log.Errorf("Delete err, invalid id: %s", err.Error())
 		return err
 	}
-	if err := db.Delete(&t, fmt.Sprintf("%s = ?", r.pkColumn), idI64).Error; err != nil {
+	if err := r.auditWriteByPK(db, AuditDelete, []interface{}{idI64}, func(q *gorm.DB) *gorm.DB {
+		return q.Where(fmt.Sprintf("%s = ?", r.pkColumn), idI64)
+	}, func(tx *gorm.DB) error {
+		return tx.Delete(&t, fmt.Sprintf("%s = ?", r.pkColumn), idI64).Error
+	}); err != nil {
 		log.Errorf("Delete err: %s", err.Error())
 		return err
 	}
@@ -194,7 +274,7 @@ func (r *BaseRepository[T]) DeleteWithDB(db *gorm.DB, id any) error {
 
 // DeleteByIDs 根据多个 ID 批量删除
 func (r *BaseRepository[T]) DeleteByIDs(ids []int64) error {
-	return r.DeleteByIDsWithDB(Client(), ids)
+	return r.DeleteByIDsWithDB(r.DB(), ids)
 }
 
 // DeleteByIDsWithDB 使用指定 DB 批量删除
@@ -203,7 +283,11 @@ func (r *BaseRepository[T]) DeleteByIDsWithDB(db *gorm.DB, ids []int64) error {
 	if len(ids) == 0 {
 		return nil
 	}
-	if err := db.Where("id IN ?", ids).Delete(&t).Error; err != nil {
+	if err := r.auditWriteByPK(db, AuditDelete, int64Values(ids), func(q *gorm.DB) *gorm.DB {
+		return q.Where("id IN ?", ids)
+	}, func(tx *gorm.DB) error {
+		return tx.Where("id IN ?", ids).Delete(&t).Error
+	}); err != nil {
 		log.Errorf("DeleteByIDs err: %s", err.Error())
 		return err
 	}
@@ -212,17 +296,21 @@ func (r *BaseRepository[T]) DeleteByIDsWithDB(db *gorm.DB, ids []int64) error {
 
 // DeleteWhere 根据条件删除
 func (r *BaseRepository[T]) DeleteWhere(conds ...interface{}) error {
-	return r.DeleteWhereWithDB(Client(), conds...)
+	return r.DeleteWhereWithDB(r.DB(), conds...)
 }
 
 // DeleteWhereWithDB 使用指定 DB 条件删除
 func (r *BaseRepository[T]) DeleteWhereWithDB(db *gorm.DB, conds ...interface{}) error {
 	var t T
-	query := db.Model(&t)
-	for _, cond := range conds {
-		query = query.Where(cond)
+	scope := func(q *gorm.DB) *gorm.DB {
+		for _, cond := range conds {
+			q = q.Where(cond)
+		}
+		return q
 	}
-	if err := query.Delete(&t).Error; err != nil {
+	if err := r.auditWrite(db, AuditDelete, scope, func(tx *gorm.DB) error {
+		return scope(tx.Model(&t)).Delete(&t).Error
+	}); err != nil {
 		log.Errorf("DeleteWhere err: %s", err.Error())
 		return err
 	}
@@ -230,10 +318,11 @@ func (r *BaseRepository[T]) DeleteWhereWithDB(db *gorm.DB, conds ...interface{})
 }
 
 // ==================== 查询操作 ====================
+// 不带 DB 参数的查询方法走主库；读从库需显式选择：使用 Ctx 变体，或 WithDB 变体传入 ReadDB()
 
 // FindByID 根据 ID 查询单条记录
 func (r *BaseRepository[T]) FindByID(id any) (T, error) {
-	return r.FindByIDWithDB(Client(), id)
+	return r.FindByIDWithDB(r.DB(), id)
 }
 
 // FindByIDWithDB 使用指定 DB 查询
@@ -255,7 +344,7 @@ func (r *BaseRepository[T]) FindByIDWithDB(db *gorm.DB, id any) (T, error) {
 
 // FindByIDs 根据多个 ID 查询
 func (r *BaseRepository[T]) FindByIDs(ids []int64) ([]T, error) {
-	return r.FindByIDsWithDB(Client(), ids)
+	return r.FindByIDsWithDB(r.DB(), ids)
 }
 
 // FindByIDsWithDB 使用指定 DB 批量查询
@@ -273,7 +362,7 @@ func (r *BaseRepository[T]) FindByIDsWithDB(db *gorm.DB, ids []int64) ([]T, erro
 
 // FindOne 根据条件查询单条记录
 func (r *BaseRepository[T]) FindOne(conds ...interface{}) (T, error) {
-	return r.FindOneWithDB(Client(), conds...)
+	return r.FindOneWithDB(r.DB(), conds...)
 }
 
 // FindOneWithDB 使用指定 DB 查询单条
@@ -321,7 +410,7 @@ func (r *BaseRepository[T]) FindOneWithDB(db *gorm.DB, conds ...interface{}) (T,
 
 // FindAll 查询所有记录
 func (r *BaseRepository[T]) FindAll() ([]T, error) {
-	return r.FindAllWithDB(Client())
+	return r.FindAllWithDB(r.DB())
 }
 
 // FindAllWithDB 使用指定 DB 查询所有
@@ -336,7 +425,7 @@ func (r *BaseRepository[T]) FindAllWithDB(db *gorm.DB) ([]T, error) {
 
 // Find 根据条件查询多条记录
 func (r *BaseRepository[T]) Find(order string, conds ...interface{}) ([]T, error) {
-	return r.FindWithDB(Client(), order, conds...)
+	return r.FindWithDB(r.DB(), order, conds...)
 }
 
 // FindWithDB 使用指定 DB 条件查询
@@ -360,7 +449,7 @@ func (r *BaseRepository[T]) FindWithDB(db *gorm.DB, order string, conds ...inter
 
 // Count 统计符合条件的记录数
 func (r *BaseRepository[T]) Count(conds ...interface{}) (int64, error) {
-	return r.CountWithDB(Client(), conds...)
+	return r.CountWithDB(r.DB(), conds...)
 }
 
 // CountWithDB 使用指定 DB 统计
@@ -393,7 +482,7 @@ func (r *BaseRepository[T]) ExistsWithDB(db *gorm.DB, conds ...interface{}) (boo
 
 // FindPage 分页查询
 func (r *BaseRepository[T]) FindPage(offset, limit int, order string, conds ...interface{}) ([]T, int64, error) {
-	return r.FindPageWithDB(Client(), offset, limit, order, conds...)
+	return r.FindPageWithDB(r.DB(), offset, limit, order, conds...)
 }
 
 // FindPageWithDB 使用指定 DB 分页查询
@@ -468,12 +557,12 @@ func (r *BaseRepository[T]) FindPageResult(page, pageSize int, order string, con
 
 // Transaction 在事务中执行操作
 func (r *BaseRepository[T]) Transaction(fn func(tx *gorm.DB) error) error {
-	return Client().Transaction(fn)
+	return r.DB().Transaction(fn)
 }
 
 // TransactionWithContext 带上下文的事务
 func (r *BaseRepository[T]) TransactionWithContext(ctx context.Context, fn func(tx *gorm.DB) error) error {
-	return Client().WithContext(ctx).Transaction(fn)
+	return r.DB().WithContext(ctx).Transaction(fn)
 }
 
 // ==================== 原始 SQL ====================
@@ -481,7 +570,7 @@ func (r *BaseRepository[T]) TransactionWithContext(ctx context.Context, fn func(
 // Raw 执行原始 SQL 查询
 func (r *BaseRepository[T]) Raw(sql string, args ...interface{}) ([]T, error) {
 	var list []T
-	if err := Client().Raw(sql, args...).Scan(&list).Error; err != nil {
+	if err := r.DB().Raw(sql, args...).Scan(&list).Error; err != nil {
 		log.Errorf("Raw err: %s", err.Error())
 		return nil, err
 	}
@@ -490,7 +579,7 @@ func (r *BaseRepository[T]) Raw(sql string, args ...interface{}) ([]T, error) {
 
 // Exec 执行原始 SQL（INSERT/UPDATE/DELETE）
 func (r *BaseRepository[T]) Exec(sql string, args ...interface{}) (int64, error) {
-	result := Client().Exec(sql, args...)
+	result := r.DB().Exec(sql, args...)
 	if result.Error != nil {
 		log.Errorf("Exec err: %s", result.Error)
 		return 0, result.Error
@@ -0,0 +1,170 @@
+package dbmysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// ==================== 上下文 API ====================
+// Ctx 后缀的方法从 ctx 中获取连接：ctx 中有本连接的事务 (WithTx/WithTxOn) 时自动加入，
+// 否则写操作走主库、读操作走读库 (WithPrimary 时走主库)；ctx 的超时与取消会传递给 gorm
+
+// CtxDB 写操作使用的连接
+func (r *BaseRepository[T]) CtxDB(ctx context.Context) *gorm.DB {
+	return GetDBOrTxOn(ctx, r.conn)
+}
+
+// CtxReadDB 读操作使用的连接
+func (r *BaseRepository[T]) CtxReadDB(ctx context.Context) *gorm.DB {
+	if tx := GetTxFromContextOn(ctx, r.conn); tx != nil {
+		return tx.WithContext(ctx)
+	}
+	if IsPrimaryPinned(ctx) {
+		return r.DB().WithContext(ctx)
+	}
+	return r.ReadDB().WithContext(ctx)
+}
+
+// ==================== 创建操作 ====================
+
+// InsertCtx 插入单条记录
+func (r *BaseRepository[T]) InsertCtx(ctx context.Context, obj T) error {
+	return r.InsertWithDB(r.CtxDB(ctx), obj)
+}
+
+// InsertBatchCtx 批量插入
+func (r *BaseRepository[T]) InsertBatchCtx(ctx context.Context, objs []T) error {
+	return r.InsertBatchWithDB(r.CtxDB(ctx), objs)
+}
+
+// InsertOrUpdateCtx 插入或更新
+func (r *BaseRepository[T]) InsertOrUpdateCtx(ctx context.Context, obj T, updateColumns []string) error {
+	return r.InsertOrUpdateWithDB(r.CtxDB(ctx), obj, updateColumns)
+}
+
+// BatchInsertCtx 分批插入
+func (r *BaseRepository[T]) BatchInsertCtx(ctx context.Context, objs []T, opts BatchOptions) (BatchResult, error) {
+	return r.BatchInsertWithDB(r.CtxDB(ctx), objs, opts)
+}
+
+// BatchUpsertCtx 分批 upsert
+func (r *BaseRepository[T]) BatchUpsertCtx(ctx context.Context, objs []T, opts BatchOptions) (BatchResult, error) {
+	return r.BatchUpsertWithDB(r.CtxDB(ctx), objs, opts)
+}
+
+// ==================== 更新操作 ====================
+
+// UpdateCtx 更新整个对象
+func (r *BaseRepository[T]) UpdateCtx(ctx context.Context, obj T) error {
+	return r.UpdateWithDB(r.CtxDB(ctx), obj)
+}
+
+// UpdateByIDCtx 根据 ID 更新指定字段
+func (r *BaseRepository[T]) UpdateByIDCtx(ctx context.Context, id any, updates map[string]interface{}) (int64, error) {
+	return r.UpdateByIDWithDB(r.CtxDB(ctx), id, updates)
+}
+
+// UpdateByIDsCtx 根据多个 ID 批量更新
+func (r *BaseRepository[T]) UpdateByIDsCtx(ctx context.Context, ids []int64, updates map[string]interface{}) (int64, error) {
+	return r.UpdateByIDsWithDB(r.CtxDB(ctx), ids, updates)
+}
+
+// UpdateWhereCtx 根据条件更新
+func (r *BaseRepository[T]) UpdateWhereCtx(ctx context.Context, updates map[string]interface{}, conds ...interface{}) (int64, error) {
+	return r.UpdateWhereWithDB(r.CtxDB(ctx), updates, conds...)
+}
+
+// UpdateByIDVersionCtx 根据 ID 与版本号更新
+func (r *BaseRepository[T]) UpdateByIDVersionCtx(ctx context.Context, id any, version Version, updates map[string]interface{}) error {
+	return r.UpdateByIDVersionWithDB(r.CtxDB(ctx), id, version, updates)
+}
+
+// UpdateWhereVersionCtx 根据条件与版本号更新
+func (r *BaseRepository[T]) UpdateWhereVersionCtx(ctx context.Context, version Version, updates map[string]interface{}, conds ...interface{}) error {
+	return r.UpdateWhereVersionWithDB(r.CtxDB(ctx), version, updates, conds...)
+}
+
+// UpdateVersionedCtx 按对象当前版本号更新整个对象
+func (r *BaseRepository[T]) UpdateVersionedCtx(ctx context.Context, obj *T) error {
+	return r.UpdateVersionedWithDB(r.CtxDB(ctx), obj)
+}
+
+// BatchUpdateCtx 按主键分批更新指定列
+func (r *BaseRepository[T]) BatchUpdateCtx(ctx context.Context, objs []T, columns []string, opts BatchOptions) (BatchResult, error) {
+	return r.BatchUpdateWithDB(r.CtxDB(ctx), objs, columns, opts)
+}
+
+// ==================== 删除操作 ====================
+
+// DeleteCtx 根据 ID 删除
+func (r *BaseRepository[T]) DeleteCtx(ctx context.Context, id any) error {
+	return r.DeleteWithDB(r.CtxDB(ctx), id)
+}
+
+// DeleteByIDsCtx 批量删除
+func (r *BaseRepository[T]) DeleteByIDsCtx(ctx context.Context, ids []int64) error {
+	return r.DeleteByIDsWithDB(r.CtxDB(ctx), ids)
+}
+
+// DeleteWhereCtx 条件删除
+func (r *BaseRepository[T]) DeleteWhereCtx(ctx context.Context, conds ...interface{}) error {
+	return r.DeleteWhereWithDB(r.CtxDB(ctx), conds...)
+}
+
+// RestoreCtx 恢复已软删除记录
+func (r *BaseRepository[T]) RestoreCtx(ctx context.Context, conds ...interface{}) (int64, error) {
+	return r.RestoreWithDB(r.CtxDB(ctx), conds...)
+}
+
+// ForceDeleteCtx 物理删除
+func (r *BaseRepository[T]) ForceDeleteCtx(ctx context.Context, conds ...interface{}) error {
+	return r.ForceDeleteWithDB(r.CtxDB(ctx), conds...)
+}
+
+// ==================== 查询操作 ====================
+
+// FindByIDCtx 根据 ID 查询单条记录
+func (r *BaseRepository[T]) FindByIDCtx(ctx context.Context, id any) (T, error) {
+	return r.FindByIDWithDB(r.CtxReadDB(ctx), id)
+}
+
+// FindByIDsCtx 根据多个 ID 查询
+func (r *BaseRepository[T]) FindByIDsCtx(ctx context.Context, ids []int64) ([]T, error) {
+	return r.FindByIDsWithDB(r.CtxReadDB(ctx), ids)
+}
+
+// FindOneCtx 根据条件查询单条记录
+func (r *BaseRepository[T]) FindOneCtx(ctx context.Context, conds ...interface{}) (T, error) {
+	return r.FindOneWithDB(r.CtxReadDB(ctx), conds...)
+}
+
+// FindAllCtx 查询所有记录
+func (r *BaseRepository[T]) FindAllCtx(ctx context.Context) ([]T, error) {
+	return r.FindAllWithDB(r.CtxReadDB(ctx))
+}
+
+// FindCtx 根据条件查询多条记录
+func (r *BaseRepository[T]) FindCtx(ctx context.Context, order string, conds ...interface{}) ([]T, error) {
+	return r.FindWithDB(r.CtxReadDB(ctx), order, conds...)
+}
+
+// CountCtx 统计数量
+func (r *BaseRepository[T]) CountCtx(ctx context.Context, conds ...interface{}) (int64, error) {
+	return r.CountWithDB(r.CtxReadDB(ctx), conds...)
+}
+
+// ExistsCtx 检查记录是否存在
+func (r *BaseRepository[T]) ExistsCtx(ctx context.Context, conds ...interface{}) (bool, error) {
+	return r.ExistsWithDB(r.CtxReadDB(ctx), conds...)
+}
+
+// FindPageCtx 分页查询
+func (r *BaseRepository[T]) FindPageCtx(ctx context.Context, offset, limit int, order string, conds ...interface{}) ([]T, int64, error) {
+	return r.FindPageWithDB(r.CtxReadDB(ctx), offset, limit, order, conds...)
+}
+
+// FindCursorCtx 游标分页查询
+func (r *BaseRepository[T]) FindCursorCtx(ctx context.Context, cursor string, limit int, sort []SortField, conds ...interface{}) (*CursorPage[T], error) {
+	return r.FindCursorWithDB(r.CtxReadDB(ctx), cursor, limit, sort, conds...)
+}
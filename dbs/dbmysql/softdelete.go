@@ -0,0 +1,203 @@
+package dbmysql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DeletedAt 软删除时间 (毫秒)，0 表示未删除；模型中声明该类型字段即启用软删除
+//
+//	DeletedAt dbmysql.DeletedAt `gorm:"type:BIGINT;not null;default:0;index" json:"deleted_at"`
+//
+// 启用后 Find/Count 等查询自动过滤已删除记录，Delete 改为写入删除时间，Unscoped 可查询或物理删除
+type DeletedAt int64
+
+// ErrNoSoftDelete 模型未声明 DeletedAt 字段
+var ErrNoSoftDelete = errors.New("dbmysql: model has no DeletedAt field")
+
+// QueryClauses 查询时追加 deleted_at = 0
+func (DeletedAt) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{softDeleteQueryClause{field: f}}
+}
+
+// UpdateClauses 更新时追加 deleted_at = 0
+func (DeletedAt) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{softDeleteUpdateClause{field: f}}
+}
+
+// DeleteClauses 删除改为更新 deleted_at
+func (DeletedAt) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{softDeleteDeleteClause{field: f}}
+}
+
+type softDeleteQueryClause struct {
+	field *schema.Field
+}
+
+func (sd softDeleteQueryClause) Name() string               { return "" }
+func (sd softDeleteQueryClause) Build(clause.Builder)       {}
+func (sd softDeleteQueryClause) MergeClause(*clause.Clause) {}
+
+func (sd softDeleteQueryClause) ModifyStatement(stmt *gorm.Statement) {
+	if _, ok := stmt.Clauses["soft_delete_enabled"]; ok || stmt.Unscoped {
+		return
+	}
+
+	// 已有 OR 条件时整体加括号，避免与软删除条件优先级混淆
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) >= 1 {
+			for _, expr := range where.Exprs {
+				if orCond, ok := expr.(clause.OrConditions); ok && len(orCond.Exprs) == 1 {
+					where.Exprs = []clause.Expression{clause.And(where.Exprs...)}
+					c.Expression = where
+					stmt.Clauses["WHERE"] = c
+					break
+				}
+			}
+		}
+	}
+
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.field.DBName}, Value: 0},
+	}})
+	stmt.Clauses["soft_delete_enabled"] = clause.Clause{}
+}
+
+type softDeleteUpdateClause struct {
+	field *schema.Field
+}
+
+func (sd softDeleteUpdateClause) Name() string               { return "" }
+func (sd softDeleteUpdateClause) Build(clause.Builder)       {}
+func (sd softDeleteUpdateClause) MergeClause(*clause.Clause) {}
+
+func (sd softDeleteUpdateClause) ModifyStatement(stmt *gorm.Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Unscoped {
+		softDeleteQueryClause(sd).ModifyStatement(stmt)
+	}
+}
+
+type softDeleteDeleteClause struct {
+	field *schema.Field
+}
+
+func (sd softDeleteDeleteClause) Name() string               { return "" }
+func (sd softDeleteDeleteClause) Build(clause.Builder)       {}
+func (sd softDeleteDeleteClause) MergeClause(*clause.Clause) {}
+
+func (sd softDeleteDeleteClause) ModifyStatement(stmt *gorm.Statement) {
+	if stmt.SQL.Len() > 0 || stmt.Unscoped {
+		return
+	}
+
+	deletedAt := DeletedAt(time.Now().UnixMilli())
+	stmt.AddClause(clause.Set{{Column: clause.Column{Name: sd.field.DBName}, Value: deletedAt}})
+	stmt.SetColumn(sd.field.DBName, deletedAt, true)
+
+	// 按传入对象的主键限定范围
+	if stmt.Schema != nil {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+
+		if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+			_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+			column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+			if len(values) > 0 {
+				stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+			}
+		}
+	}
+
+	softDeleteQueryClause(sd).ModifyStatement(stmt)
+	stmt.AddClauseIfNotExists(clause.Update{})
+	stmt.Build(stmt.DB.Callback().Update().Clauses...)
+}
+
+// ==================== 恢复与物理删除 ====================
+
+// deletedAtField 查找模型的软删除字段
+func deletedAtField[T any](db *gorm.DB) (*schema.Field, error) {
+	var t T
+	s, err := schema.Parse(&t, &schemaCache, db.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	deletedAtType := reflect.TypeOf(DeletedAt(0))
+	for _, f := range s.Fields {
+		if f.FieldType == deletedAtType && f.DBName != "" {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoSoftDelete, s.Table)
+}
+
+// Restore 恢复符合条件的已软删除记录
+func (r *BaseRepository[T]) Restore(conds ...interface{}) (int64, error) {
+	return r.RestoreWithDB(r.DB(), conds...)
+}
+
+// RestoreWithDB 使用指定 DB 恢复已软删除记录
+func (r *BaseRepository[T]) RestoreWithDB(db *gorm.DB, conds ...interface{}) (int64, error) {
+	field, err := deletedAtField[T](db)
+	if err != nil {
+		return 0, err
+	}
+
+	scope := func(q *gorm.DB) *gorm.DB {
+		q = q.Unscoped().Where(fmt.Sprintf("%s <> 0", field.DBName))
+		for _, cond := range conds {
+			q = q.Where(cond)
+		}
+		return q
+	}
+
+	var rows int64
+	err = r.auditWrite(db, AuditRestore, scope, func(tx *gorm.DB) error {
+		result := scope(tx.Model((*T)(nil))).Update(field.DBName, 0)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Errorf("Restore err: %s", err)
+		return 0, err
+	}
+	return rows, nil
+}
+
+// ForceDelete 物理删除符合条件的记录 (包括已软删除的)
+func (r *BaseRepository[T]) ForceDelete(conds ...interface{}) error {
+	return r.ForceDeleteWithDB(r.DB(), conds...)
+}
+
+// ForceDeleteWithDB 使用指定 DB 物理删除
+func (r *BaseRepository[T]) ForceDeleteWithDB(db *gorm.DB, conds ...interface{}) error {
+	if len(conds) == 0 {
+		return gorm.ErrMissingWhereClause
+	}
+
+	var t T
+	scope := func(q *gorm.DB) *gorm.DB {
+		q = q.Unscoped()
+		for _, cond := range conds {
+			q = q.Where(cond)
+		}
+		return q
+	}
+	if err := r.auditWrite(db, AuditDelete, scope, func(tx *gorm.DB) error {
+		return scope(tx.Model(&t)).Delete(&t).Error
+	}); err != nil {
+		log.Errorf("ForceDelete err: %s", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package dbmysql
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type softModel struct {
+	ID        int64 `gorm:"primaryKey"`
+	Name      string
+	DeletedAt DeletedAt
+}
+
+func TestSoftDeleteQuery(t *testing.T) {
+	db := dryRunDB(t)
+
+	stmt := db.Where("name = ?", "a").Find(&[]softModel{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "`soft_models`.`deleted_at` = ?") {
+		t.Fatalf("query sql: %s", sql)
+	}
+
+	stmt = db.Unscoped().Find(&[]softModel{}).Statement
+	if sql := stmt.SQL.String(); strings.Contains(sql, "deleted_at") {
+		t.Fatalf("unscoped sql: %s", sql)
+	}
+
+	stmt = db.Session(&gorm.Session{SkipDefaultTransaction: true}).Where("id = ?", 1).Delete(&softModel{}).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "UPDATE `soft_models` SET `deleted_at`=?") {
+		t.Fatalf("delete sql: %s", sql)
+	}
+
+	if _, err := deletedAtField[plainModel](db); err == nil {
+		t.Fatal("expected ErrNoSoftDelete")
+	}
+}
+
+func TestAuditDiff(t *testing.T) {
+	b, a := auditDiff(
+		map[string]interface{}{"id": 1, "status": 0, "remark": "x"},
+		map[string]interface{}{"id": 1, "status": 1, "remark": "x"},
+	)
+	if len(b) != 1 || b["status"] != 0 || a["status"] != 1 {
+		t.Fatalf("before=%v after=%v", b, a)
+	}
+
+	if b, a = auditDiff(map[string]interface{}{"id": 1}, map[string]interface{}{"id": 1}); b != nil || a != nil {
+		t.Fatalf("unchanged row should be skipped: %v %v", b, a)
+	}
+
+	if b, a = auditDiff(nil, map[string]interface{}{"id": 1}); b != nil || a["id"] != 1 {
+		t.Fatalf("create: %v %v", b, a)
+	}
+}
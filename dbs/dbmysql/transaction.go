@@ -8,10 +8,20 @@ import (
 	"gorm.io/gorm"
 )
 
-// txKeyType 事务上下文键类型
-type txKeyType struct{}
+// txKeyType 事务上下文键类型，按实例名区分不同连接的事务
+type txKeyType struct {
+	name string
+}
+
+var txKey = txKeyType{name: DefaultName}
 
-var txKey = txKeyType{}
+// instanceTxKey 命名连接的事务上下文键，name 为空时为默认实例
+func instanceTxKey(name string) txKeyType {
+	if name == "" {
+		return txKey
+	}
+	return txKeyType{name: name}
+}
 
 // ==================== 基础事务操作 ====================
 
@@ -22,10 +32,15 @@ func Transaction(fn func(tx *gorm.DB) error) error {
 
 // TransactionWithContext 带上下文的事务执行
 func TransactionWithContext(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
-	return Client().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	ctx, queue := newAfterCommit(ctx, DefaultName)
+	err := Client().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		txCtx := context.WithValue(ctx, txKey, tx)
 		return fn(txCtx, tx)
 	})
+	if err == nil {
+		queue.run()
+	}
+	return err
 }
 
 // ==================== 手动事务管理 ====================
@@ -78,7 +93,12 @@ func RollbackTx(tx *gorm.DB) error {
 
 // GetTxFromContext 从上下文获取事务
 func GetTxFromContext(ctx context.Context) *gorm.DB {
-	if tx, ok := ctx.Value(txKey).(*gorm.DB); ok {
+	return GetTxFromContextOn(ctx, DefaultName)
+}
+
+// GetTxFromContextOn 从上下文获取命名连接的事务
+func GetTxFromContextOn(ctx context.Context, name string) *gorm.DB {
+	if tx, ok := ctx.Value(instanceTxKey(name)).(*gorm.DB); ok {
 		return tx
 	}
 	return nil
@@ -92,17 +112,32 @@ func GetDBOrTx(ctx context.Context) *gorm.DB {
 	return Client()
 }
 
+// GetDBOrTxOn 获取命名连接的数据库连接或事务，并传递上下文的超时与取消
+func GetDBOrTxOn(ctx context.Context, name string) *gorm.DB {
+	if tx := GetTxFromContextOn(ctx, name); tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return Use(name).WithContext(ctx)
+}
+
 // WithTx 在事务中执行操作，支持嵌套调用
+// 已在事务中时内层使用保存点，内层返回错误只回滚到保存点，由外层决定是否继续
 func WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
-	// 检查是否已在事务中
-	if tx := GetTxFromContext(ctx); tx != nil {
-		return fn(ctx)
-	}
+	return WithTxOn(ctx, DefaultName, fn)
+}
 
-	// 开启新事务
-	return TransactionWithContext(ctx, func(txCtx context.Context, tx *gorm.DB) error {
-		return fn(txCtx)
+// WithTxOn 在命名连接的事务中执行操作，嵌套规则同 WithTx
+// 仓库写入回调在最外层事务提交后执行
+func WithTxOn(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	key := instanceTxKey(name)
+	ctx, queue, outer := withAfterCommit(ctx, name)
+	err := GetDBOrTxOn(ctx, name).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, key, tx))
 	})
+	if err == nil && outer {
+		queue.run()
+	}
+	return err
 }
 
 // ==================== 安全事务操作 ====================
@@ -137,6 +172,7 @@ func SafeTransactionWithContext(ctx context.Context, fn func(ctx context.Context
 		return fmt.Errorf("failed to begin transaction")
 	}
 
+	ctx, queue := newAfterCommit(ctx, DefaultName)
 	txCtx := context.WithValue(ctx, txKey, tx)
 
 	defer func() {
@@ -152,7 +188,10 @@ func SafeTransactionWithContext(ctx context.Context, fn func(ctx context.Context
 		return err
 	}
 
-	return CommitTx(tx)
+	if err = CommitTx(tx); err == nil {
+		queue.run()
+	}
+	return err
 }
 
 // ==================== 事务辅助函数 ====================
@@ -0,0 +1,23 @@
+package dbmysql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTxFromContextOn(t *testing.T) {
+	db := dryRunDB(t)
+
+	ctx := context.WithValue(context.Background(), instanceTxKey("orders"), db)
+	if GetTxFromContext(ctx) != nil {
+		t.Fatal("named tx leaked to default instance")
+	}
+	if GetTxFromContextOn(ctx, "orders") != db {
+		t.Fatal("named tx not found")
+	}
+
+	ctx = context.WithValue(ctx, instanceTxKey(""), db)
+	if GetTxFromContext(ctx) != db || GetTxFromContextOn(ctx, DefaultName) != db {
+		t.Fatal("default tx not found")
+	}
+}
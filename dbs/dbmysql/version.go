@@ -0,0 +1,271 @@
+package dbmysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/caoyuewen/components/util"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Version 乐观锁版本号，模型中声明该类型字段即启用乐观锁
+//
+//	Version dbmysql.Version `gorm:"not null;default:0" json:"version"`
+type Version int64
+
+var (
+	ErrVersionConflict = errors.New("dbmysql: version conflict")
+	ErrNoVersionColumn = errors.New("dbmysql: model has no version column")
+)
+
+// ConflictError 乐观锁冲突：条件与版本号匹配的行数为 0 (记录已被并发修改或不存在)
+type ConflictError struct {
+	Table   string
+	ID      any
+	Version Version
+}
+
+func (e *ConflictError) Error() string {
+	if e.ID != nil {
+		return fmt.Sprintf("dbmysql: version conflict on %s id=%v version=%d", e.Table, e.ID, e.Version)
+	}
+	return fmt.Sprintf("dbmysql: version conflict on %s version=%d", e.Table, e.Version)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ==================== 版本字段解析 ====================
+
+var (
+	versionType   = reflect.TypeOf(Version(0))
+	schemaCache   sync.Map
+	versionFields sync.Map // reflect.Type -> *schema.Field (nil 表示无版本字段)
+)
+
+// versionField 查找模型的版本字段
+func versionField[T any](db *gorm.DB) (*schema.Schema, *schema.Field, error) {
+	var t T
+	s, err := schema.Parse(&t, &schemaCache, db.NamingStrategy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if f, ok := versionFields.Load(s.ModelType); ok {
+		if f == (*schema.Field)(nil) {
+			return s, nil, fmt.Errorf("%w: %s", ErrNoVersionColumn, s.Table)
+		}
+		return s, f.(*schema.Field), nil
+	}
+
+	var field *schema.Field
+	for _, f := range s.Fields {
+		if f.FieldType == versionType && f.DBName != "" {
+			field = f
+			break
+		}
+	}
+	versionFields.Store(s.ModelType, field)
+	if field == nil {
+		return s, nil, fmt.Errorf("%w: %s", ErrNoVersionColumn, s.Table)
+	}
+	return s, field, nil
+}
+
+// withVersionBump 模型声明版本字段时，为普通更新追加 version = version + 1，
+// 保证不走乐观锁的写入也会使持有旧版本号的乐观锁更新失败；updates 已显式设置版本字段时原样返回
+func withVersionBump[T any](db *gorm.DB, updates map[string]interface{}) map[string]interface{} {
+	_, field, err := versionField[T](db)
+	if err != nil {
+		return updates
+	}
+	if _, ok := updates[field.DBName]; ok {
+		return updates
+	}
+	if _, ok := updates[field.Name]; ok {
+		return updates
+	}
+
+	values := make(map[string]interface{}, len(updates)+1)
+	for k, v := range updates {
+		values[k] = v
+	}
+	values[field.DBName] = gorm.Expr(fmt.Sprintf("%s + 1", field.DBName))
+	return values
+}
+
+// versionedValues 模型声明版本字段时，将整个对象转为按列更新的内容并追加版本号 +1，供 Update 替代 Save
+// 主键与自动更新时间列不在其中 (更新时间由 gorm 自动填充)；无版本字段时返回 false
+func versionedValues[T any](db *gorm.DB, obj *T) (map[string]interface{}, bool) {
+	s, field, err := versionField[T](db)
+	if err != nil {
+		return nil, false
+	}
+
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rv := reflect.ValueOf(obj).Elem()
+	values := make(map[string]interface{}, len(s.Fields))
+	for _, f := range s.Fields {
+		if f.DBName == "" || f.PrimaryKey || !f.Updatable || f.AutoUpdateTime > 0 || f == field {
+			continue
+		}
+		values[f.DBName], _ = f.ValueOf(ctx, rv)
+	}
+	values[field.DBName] = gorm.Expr(fmt.Sprintf("%s + 1", field.DBName))
+	return values, true
+}
+
+// ==================== 乐观锁更新 ====================
+
+// UpdateByIDVersion 根据 ID 与版本号更新指定字段，版本号自动 +1，无匹配行时返回 ConflictError
+func (r *BaseRepository[T]) UpdateByIDVersion(id any, version Version, updates map[string]interface{}) error {
+	return r.UpdateByIDVersionWithDB(r.DB(), id, version, updates)
+}
+
+// UpdateByIDVersionWithDB 使用指定 DB 乐观锁更新
+func (r *BaseRepository[T]) UpdateByIDVersionWithDB(db *gorm.DB, id any, version Version, updates map[string]interface{}) error {
+	idI64, err := util.ToInt64E(id)
+	if err != nil {
+		log.Errorf("UpdateByIDVersion err, invalid id: %s", err.Error())
+		return err
+	}
+	return r.updateVersion(db, idI64, version, updates, clause.Eq{Column: r.pkColumn, Value: idI64})
+}
+
+// UpdateWhereVersion 根据条件与版本号更新，版本号自动 +1，无匹配行时返回 ConflictError
+func (r *BaseRepository[T]) UpdateWhereVersion(version Version, updates map[string]interface{}, conds ...interface{}) error {
+	return r.UpdateWhereVersionWithDB(r.DB(), version, updates, conds...)
+}
+
+// UpdateWhereVersionWithDB 使用指定 DB 根据条件乐观锁更新
+func (r *BaseRepository[T]) UpdateWhereVersionWithDB(db *gorm.DB, version Version, updates map[string]interface{}, conds ...interface{}) error {
+	return r.updateVersion(db, nil, version, updates, conds...)
+}
+
+// UpdateVersioned 根据主键与对象当前版本号更新整个对象，成功后对象版本号 +1
+func (r *BaseRepository[T]) UpdateVersioned(obj *T) error {
+	return r.UpdateVersionedWithDB(r.DB(), obj)
+}
+
+// UpdateVersionedWithDB 使用指定 DB 乐观锁更新整个对象
+func (r *BaseRepository[T]) UpdateVersionedWithDB(db *gorm.DB, obj *T) error {
+	s, field, err := versionField[T](db)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(obj).Elem()
+	fv, _ := field.ValueOf(context.Background(), rv)
+	version := fv.(Version)
+
+	if err = field.Set(context.Background(), rv, version+1); err != nil {
+		return err
+	}
+
+	ids, err := r.pkValues(db, []T{*obj})
+	if err != nil {
+		return err
+	}
+
+	result := &gorm.DB{}
+	result.Error = r.auditWriteByPK(db, AuditUpdate, ids, func(q *gorm.DB) *gorm.DB {
+		return r.objScope(db, *obj)(q).Where(fmt.Sprintf("%s = ?", field.DBName), version)
+	}, func(tx *gorm.DB) error {
+		res := tx.Model(obj).Where(fmt.Sprintf("%s = ?", field.DBName), version).Select("*").Updates(obj)
+		result.RowsAffected = res.RowsAffected
+		return res.Error
+	})
+	if result.Error == nil && result.RowsAffected == 0 {
+		var id any
+		if s.PrioritizedPrimaryField != nil {
+			id, _ = s.PrioritizedPrimaryField.ValueOf(context.Background(), rv)
+		}
+		result.Error = &ConflictError{Table: s.Table, ID: id, Version: version}
+	}
+	if result.Error != nil {
+		field.Set(context.Background(), rv, version)
+		if !errors.Is(result.Error, ErrVersionConflict) {
+			log.Errorf("UpdateVersioned err: %s", result.Error)
+		}
+		return result.Error
+	}
+	return nil
+}
+
+func (r *BaseRepository[T]) updateVersion(db *gorm.DB, id any, version Version, updates map[string]interface{}, conds ...interface{}) error {
+	s, field, err := versionField[T](db)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{}, len(updates)+1)
+	for k, v := range updates {
+		values[k] = v
+	}
+	values[field.DBName] = gorm.Expr(fmt.Sprintf("%s + 1", field.DBName))
+
+	scope := func(q *gorm.DB) *gorm.DB {
+		q = q.Where(fmt.Sprintf("%s = ?", field.DBName), version)
+		for _, cond := range conds {
+			q = q.Where(cond)
+		}
+		return q
+	}
+
+	var rows int64
+	err = r.auditWrite(db, AuditUpdate, scope, func(tx *gorm.DB) error {
+		result := scope(tx.Model((*T)(nil))).Updates(values)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.Errorf("UpdateVersion err: %s", err)
+		return err
+	}
+	if rows == 0 {
+		return &ConflictError{Table: s.Table, ID: id, Version: version}
+	}
+	return nil
+}
+
+// ==================== 冲突重试 ====================
+
+// RetryOnConflict 乐观锁冲突时重新执行 fn (fn 内需重新读取最新数据)，最多执行 attempts 次
+// 其他错误直接返回；重试间隔随机 10~50ms 以错开并发写入
+func RetryOnConflict(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	if attempts <= 0 {
+		attempts = 3
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(ctx); !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+		if i == attempts-1 {
+			break
+		}
+
+		wait := time.Duration(10+rand.IntN(40)) * time.Millisecond
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	log.Warnf("RetryOnConflict give up after %d attempts: %v", attempts, err)
+	return err
+}
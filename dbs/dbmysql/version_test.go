@@ -0,0 +1,100 @@
+package dbmysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type versionedModel struct {
+	ID      int64 `gorm:"primaryKey"`
+	Rev     Version
+	Payload string
+}
+
+type plainModel struct {
+	ID int64 `gorm:"primaryKey"`
+}
+
+func TestVersionField(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}}
+
+	_, f, err := versionField[versionedModel](db)
+	if err != nil || f.DBName != "rev" {
+		t.Fatalf("field=%v err=%v", f, err)
+	}
+
+	if _, _, err = versionField[plainModel](db); !errors.Is(err, ErrNoVersionColumn) {
+		t.Fatalf("want ErrNoVersionColumn, got %v", err)
+	}
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	calls := 0
+	err := RetryOnConflict(context.Background(), 3, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("wrap: %w", &ConflictError{Table: "t", ID: 1, Version: 1})
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	other := errors.New("other")
+	err = RetryOnConflict(context.Background(), 3, func(ctx context.Context) error {
+		calls++
+		return other
+	})
+	if err != other || calls != 1 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = RetryOnConflict(context.Background(), 2, func(ctx context.Context) error {
+		calls++
+		return &ConflictError{Table: "t"}
+	})
+	if !errors.Is(err, ErrVersionConflict) || calls != 2 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	db := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true})
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&versionedModel{}).Where("id = ?", 1).Updates(withVersionBump[versionedModel](tx, map[string]interface{}{"payload": "x"}))
+	})
+	if !strings.Contains(sql, "`rev`=rev + 1") {
+		t.Fatalf("version not bumped: %s", sql)
+	}
+
+	sql = db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&versionedModel{}).Where("id = ?", 1).Updates(withVersionBump[versionedModel](tx, map[string]interface{}{"rev": 5}))
+	})
+	if strings.Contains(sql, "rev + 1") {
+		t.Fatalf("explicit version overridden: %s", sql)
+	}
+
+	if got := withVersionBump[plainModel](db, map[string]interface{}{"id": 1}); len(got) != 1 {
+		t.Fatalf("plain model bumped: %v", got)
+	}
+
+	obj := versionedModel{ID: 7, Rev: 3, Payload: "p"}
+	values, ok := versionedValues(db, &obj)
+	if !ok {
+		t.Fatal("versioned model not detected")
+	}
+	sql = db.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.Model(&obj).Updates(values) })
+	if !strings.Contains(sql, "`rev`=rev + 1") || !strings.Contains(sql, "`payload`='p'") || !strings.Contains(sql, "`id` = 7") {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+}
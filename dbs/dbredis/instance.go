@@ -2,6 +2,9 @@ package dbredis
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,119 +16,301 @@ import (
 var (
 	client        atomic.Pointer[redis.Client]
 	clusterClient atomic.Pointer[redis.ClusterClient]
-	once          sync.Once
+	startMu       sync.Mutex
 	isCluster     atomic.Bool
 	initialized   atomic.Bool
+	stop          chan struct{}
+	cfg           RedisInfo
 )
 
-// StartUp initializes the global Redis client or cluster client with the provided options and starts the connection checker
+// Mode 部署模式
+type Mode int
+
+const (
+	ModeAuto       Mode = iota // 按地址数量判断：1 个为单机，多个为集群
+	ModeStandalone             // 单机
+	ModeCluster                // 集群
+	ModeSentinel               // Sentinel 主从故障转移，Addrs 为 Sentinel 地址
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeStandalone:
+		return "standalone"
+	case ModeCluster:
+		return "cluster"
+	case ModeSentinel:
+		return "sentinel"
+	}
+	return "auto"
+}
+
+// RedisInfo Redis 配置信息
+type RedisInfo struct {
+	Mode     Mode     // 部署模式，默认 ModeAuto
+	Addrs    []string // 地址 host:port；Sentinel 模式为 Sentinel 节点地址
+	Username string   // ACL 用户名 (Redis 6+)
+	Password string   // 密码
+	DB       int      // 库序号，仅单机与 Sentinel 模式有效
+
+	// Sentinel 配置
+	MasterName       string // 主节点名称，Sentinel 模式必填
+	SentinelUsername string // Sentinel 用户名
+	SentinelPassword string // Sentinel 密码
+
+	ReadOnly bool // 集群模式下只读命令走从节点
+
+	TLS       bool        // 是否启用 TLS
+	TLSConfig *tls.Config // 自定义 TLS 配置，设置后 TLS 视为启用
+
+	// 连接池与超时配置（可选）
+	PoolSize        int           // 每个节点的最大连接数，默认 10 * CPU 数
+	MinIdleConns    int           // 最小空闲连接数，默认 0
+	ConnMaxIdleTime time.Duration // 空闲连接最大生命周期，默认 30m
+	PoolTimeout     time.Duration // 等待连接超时，默认 ReadTimeout + 1s
+	DialTimeout     time.Duration // 建立连接超时，默认 5s
+	ReadTimeout     time.Duration // 读超时，默认 3s
+	WriteTimeout    time.Duration // 写超时，默认 ReadTimeout
+	MaxRetries      int           // 命令最大重试次数，默认 3，-1 不重试
+}
+
+// mode 解析实际部署模式
+func (info *RedisInfo) mode() Mode {
+	if info.Mode != ModeAuto {
+		return info.Mode
+	}
+	if len(info.Addrs) > 1 {
+		return ModeCluster
+	}
+	return ModeStandalone
+}
+
+// validate 校验配置
+func (info *RedisInfo) validate() error {
+	if len(info.Addrs) == 0 {
+		return errors.New("dbredis: Addrs is required")
+	}
+	if info.mode() == ModeSentinel && info.MasterName == "" {
+		return errors.New("dbredis: MasterName is required for sentinel mode")
+	}
+	if info.mode() == ModeCluster && info.DB != 0 {
+		return errors.New("dbredis: DB is not supported in cluster mode")
+	}
+	return nil
+}
+
+func (info *RedisInfo) tlsConfig() *tls.Config {
+	if info.TLSConfig != nil {
+		return info.TLSConfig
+	}
+	if info.TLS {
+		return &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return nil
+}
+
+// newClient 按模式创建客户端，Sentinel 模式返回的也是 *redis.Client
+func (info *RedisInfo) newClient() (*redis.Client, *redis.ClusterClient) {
+	switch info.mode() {
+	case ModeCluster:
+		return nil, redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           info.Addrs,
+			Username:        info.Username,
+			Password:        info.Password,
+			ReadOnly:        info.ReadOnly,
+			TLSConfig:       info.tlsConfig(),
+			PoolSize:        info.PoolSize,
+			MinIdleConns:    info.MinIdleConns,
+			ConnMaxIdleTime: info.ConnMaxIdleTime,
+			PoolTimeout:     info.PoolTimeout,
+			DialTimeout:     info.DialTimeout,
+			ReadTimeout:     info.ReadTimeout,
+			WriteTimeout:    info.WriteTimeout,
+			MaxRetries:      info.MaxRetries,
+		})
+	case ModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       info.MasterName,
+			SentinelAddrs:    info.Addrs,
+			SentinelUsername: info.SentinelUsername,
+			SentinelPassword: info.SentinelPassword,
+			Username:         info.Username,
+			Password:         info.Password,
+			DB:               info.DB,
+			TLSConfig:        info.tlsConfig(),
+			PoolSize:         info.PoolSize,
+			MinIdleConns:     info.MinIdleConns,
+			ConnMaxIdleTime:  info.ConnMaxIdleTime,
+			PoolTimeout:      info.PoolTimeout,
+			DialTimeout:      info.DialTimeout,
+			ReadTimeout:      info.ReadTimeout,
+			WriteTimeout:     info.WriteTimeout,
+			MaxRetries:       info.MaxRetries,
+		}), nil
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:            info.Addrs[0],
+			Username:        info.Username,
+			Password:        info.Password,
+			DB:              info.DB,
+			TLSConfig:       info.tlsConfig(),
+			PoolSize:        info.PoolSize,
+			MinIdleConns:    info.MinIdleConns,
+			ConnMaxIdleTime: info.ConnMaxIdleTime,
+			PoolTimeout:     info.PoolTimeout,
+			DialTimeout:     info.DialTimeout,
+			ReadTimeout:     info.ReadTimeout,
+			WriteTimeout:    info.WriteTimeout,
+			MaxRetries:      info.MaxRetries,
+		}), nil
+	}
+}
+
+// ==================== 启动与关闭 ====================
+
+// StartUp 按地址初始化 (1 个地址为单机，多个为集群)，失败时 panic，保留用于兼容旧调用
 func StartUp(addrs []string, checkInterval time.Duration) {
-	once.Do(func() {
-		if len(addrs) > 1 {
-			isCluster.Store(true)
-			cc := redis.NewClusterClient(&redis.ClusterOptions{
-				Addrs: addrs,
-			})
-
-			ctx := context.Background()
-			_, err := cc.Ping(ctx).Result()
-			if err != nil {
-				log.Errorf("[REDIS] Failed to connected redis cluster:%v err:%s ", addrs, err.Error())
-				panic(err.Error())
-			}
+	if err := StartUpWithInfo(RedisInfo{Addrs: addrs}, checkInterval); err != nil {
+		panic(err.Error())
+	}
+}
 
-			clusterClient.Store(cc)
-			initialized.Store(true)
-			log.Info("[REDIS] Redis cluster connected successfully")
-			go connectionCheckerCluster(&redis.ClusterOptions{Addrs: addrs}, checkInterval)
-		} else {
-			isCluster.Store(false)
-			c := redis.NewClient(&redis.Options{
-				Addr: addrs[0],
-			})
-
-			ctx := context.Background()
-			_, err := c.Ping(ctx).Result()
-			if err != nil {
-				log.Error("[REDIS] Failed to connect redis: " + err.Error())
-				panic(err.Error())
-			}
+// StartUpWithInfo 按配置初始化全局 Redis 客户端并启动健康检查，失败时返回错误
+// checkInterval <= 0 时不做健康检查；已初始化时直接返回 nil
+func StartUpWithInfo(info RedisInfo, checkInterval time.Duration) error {
+	startMu.Lock()
+	defer startMu.Unlock()
 
-			client.Store(c)
-			initialized.Store(true)
-			log.Info("[REDIS] Redis connected successfully:", addrs)
-			go connectionChecker(&redis.Options{Addr: addrs[0]}, checkInterval)
-		}
-	})
+	if initialized.Load() {
+		log.Warn("[REDIS] StartUp called more than once, ignored")
+		return nil
+	}
+	if err := info.validate(); err != nil {
+		return err
+	}
+
+	c, cc, err := connect(info)
+	if err != nil {
+		log.Errorf("[REDIS] Failed to connect redis %s %v: %v", info.mode(), info.Addrs, err)
+		return err
+	}
+
+	cfg = info
+	isCluster.Store(cc != nil)
+	client.Store(c)
+	clusterClient.Store(cc)
+	initialized.Store(true)
+	log.Infof("[REDIS] Redis %s connected successfully: %v", info.mode(), info.Addrs)
+
+	stop = make(chan struct{})
+	if checkInterval > 0 {
+		go connectionChecker(checkInterval, stop)
+	}
+	return nil
 }
 
-func connectionChecker(options *redis.Options, checkInterval time.Duration) {
-	ticker := time.NewTicker(checkInterval)
-	defer ticker.Stop()
+// connect 创建客户端并 Ping
+func connect(info RedisInfo) (*redis.Client, *redis.ClusterClient, error) {
+	c, cc := info.newClient()
 
-	for range ticker.C {
-		ctx := context.Background()
-		c := client.Load()
-		if c == nil {
-			continue
-		}
-		_, err := c.Ping(ctx).Result()
-		if err != nil {
-			log.Warn("[REDIS] Redis connection lost, attempting to reconnect...")
-			reconnect(options)
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var rdb redis.UniversalClient = c
+	if cc != nil {
+		rdb = cc
+	}
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
+		return nil, nil, fmt.Errorf("ping: %w", err)
 	}
+	return c, cc, nil
 }
 
-func connectionCheckerCluster(options *redis.ClusterOptions, checkInterval time.Duration) {
-	ticker := time.NewTicker(checkInterval)
-	defer ticker.Stop()
+// Close 停止健康检查并关闭连接
+func Close() error {
+	startMu.Lock()
+	defer startMu.Unlock()
 
-	for range ticker.C {
-		ctx := context.Background()
-		cc := clusterClient.Load()
-		if cc == nil {
-			continue
-		}
-		_, err := cc.Ping(ctx).Result()
-		if err != nil {
-			log.Warn("[REDIS] Redis cluster connection lost, attempting to reconnect...")
-			reconnectCluster(options)
-		}
+	if !initialized.Load() {
+		return nil
 	}
+	close(stop)
+	initialized.Store(false)
+
+	if cc := clusterClient.Swap(nil); cc != nil {
+		return cc.Close()
+	}
+	if c := client.Swap(nil); c != nil {
+		return c.Close()
+	}
+	return nil
 }
 
-func reconnect(options *redis.Options) {
+// ==================== 健康检查 ====================
+
+// connectionChecker 定期检查连接，Ping 失败时重建客户端
+// go-redis 连接池本身会重连，这里处理客户端整体失效的情况；每个周期最多重建一次
+func connectionChecker(checkInterval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
 	for {
-		ctx := context.Background()
-		newClient := redis.NewClient(options)
-		_, err := newClient.Ping(ctx).Result()
-		if err == nil {
-			client.Store(newClient)
-			log.Info("[REDIS] Successfully reconnected to redis")
-			break
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !IsConnected() {
+				reconnect()
+			}
 		}
-		log.Error("[REDIS] Reconnect failed, retrying... " + err.Error())
-		time.Sleep(5 * time.Second)
 	}
 }
 
-func reconnectCluster(options *redis.ClusterOptions) {
-	for {
-		ctx := context.Background()
-		newClient := redis.NewClusterClient(options)
-		_, err := newClient.Ping(ctx).Result()
-		if err == nil {
-			clusterClient.Store(newClient)
-			log.Info("[REDIS] Successfully reconnected to redis cluster")
-			break
+// reconnect 新建客户端并替换，旧客户端延迟关闭以便进行中的命令完成
+func reconnect() {
+	log.Warn("[REDIS] Redis connection lost, attempting to reconnect...")
+
+	startMu.Lock()
+	info := cfg
+	startMu.Unlock()
+
+	c, cc, err := connect(info)
+	if err != nil {
+		log.Errorf("[REDIS] Reconnect failed: %v", err)
+		return
+	}
+
+	startMu.Lock()
+	defer startMu.Unlock()
+
+	var old interface{ Close() error }
+	switch {
+	case !initialized.Load():
+		// 期间已 Close
+		old = c
+		if cc != nil {
+			old = cc
 		}
-		log.Error("[REDIS] Reconnect failed, retrying... " + err.Error())
-		time.Sleep(5 * time.Second)
+		_ = old.Close()
+		return
+	case cc != nil:
+		old = clusterClient.Swap(cc)
+	default:
+		old = client.Swap(c)
+	}
+	log.Info("[REDIS] Successfully reconnected to redis")
+
+	if old != nil {
+		time.AfterFunc(time.Minute, func() {
+			_ = old.Close()
+		})
 	}
 }
 
-// Client 返回统一的 Redis 客户端接口（自动判断单机/集群）
+// ==================== 客户端访问 ====================
+
+// Client 返回统一的 Redis 客户端接口（自动判断单机/集群/Sentinel）
 // 如果未初始化会 panic
 func Client() redis.UniversalClient {
 	if !initialized.Load() {
@@ -137,7 +322,7 @@ func Client() redis.UniversalClient {
 	return client.Load()
 }
 
-// RawClient 返回单机模式的原始客户端
+// RawClient 返回单机或 Sentinel 模式的原始客户端
 func RawClient() *redis.Client {
 	return client.Load()
 }
@@ -156,3 +341,40 @@ func IsCluster() bool {
 func IsInitialized() bool {
 	return initialized.Load()
 }
+
+// IsConnected 检测连接是否正常
+func IsConnected() bool {
+	if !initialized.Load() {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := Client().Ping(ctx).Err(); err != nil {
+		log.Errorf("[REDIS] Connection lost: %v", err)
+		return false
+	}
+	return true
+}
+
+// Stats 获取连接池统计信息，集群模式为各节点合计
+func Stats() map[string]interface{} {
+	if !initialized.Load() {
+		return nil
+	}
+
+	var s *redis.PoolStats
+	if isCluster.Load() {
+		s = clusterClient.Load().PoolStats()
+	} else {
+		s = client.Load().PoolStats()
+	}
+	return map[string]interface{}{
+		"hits":        s.Hits,
+		"misses":      s.Misses,
+		"timeouts":    s.Timeouts,
+		"total_conns": s.TotalConns,
+		"idle_conns":  s.IdleConns,
+		"stale_conns": s.StaleConns,
+	}
+}
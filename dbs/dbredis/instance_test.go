@@ -0,0 +1,43 @@
+package dbredis
+
+import "testing"
+
+func TestRedisInfoMode(t *testing.T) {
+	cases := []struct {
+		info    RedisInfo
+		mode    Mode
+		invalid bool
+	}{
+		{info: RedisInfo{Addrs: []string{"a:6379"}}, mode: ModeStandalone},
+		{info: RedisInfo{Addrs: []string{"a:6379", "b:6379"}}, mode: ModeCluster},
+		{info: RedisInfo{Mode: ModeCluster, Addrs: []string{"a:6379"}}, mode: ModeCluster},
+		{info: RedisInfo{Mode: ModeSentinel, Addrs: []string{"s:26379"}, MasterName: "mymaster", DB: 2}, mode: ModeSentinel},
+		{info: RedisInfo{Mode: ModeSentinel, Addrs: []string{"s:26379"}}, mode: ModeSentinel, invalid: true},
+		{info: RedisInfo{Addrs: []string{"a:6379", "b:6379"}, DB: 1}, mode: ModeCluster, invalid: true},
+		{info: RedisInfo{}, mode: ModeStandalone, invalid: true},
+	}
+	for i, c := range cases {
+		if got := c.info.mode(); got != c.mode {
+			t.Errorf("case %d: mode %s, want %s", i, got, c.mode)
+		}
+		if err := c.info.validate(); (err != nil) != c.invalid {
+			t.Errorf("case %d: validate err %v", i, err)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	info := RedisInfo{Mode: ModeSentinel, Addrs: []string{"s:26379"}, MasterName: "mymaster", Password: "p", DB: 3}
+	c, cc := info.newClient()
+	defer c.Close()
+	if cc != nil || c.Options().DB != 3 || c.Options().Password != "p" {
+		t.Fatalf("sentinel client options: %+v", c.Options())
+	}
+
+	info = RedisInfo{Mode: ModeCluster, Addrs: []string{"a:6379"}, TLS: true}
+	c, cc = info.newClient()
+	defer cc.Close()
+	if c != nil || cc.Options().TLSConfig == nil {
+		t.Fatal("expected cluster client with TLS")
+	}
+}
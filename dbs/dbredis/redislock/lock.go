@@ -0,0 +1,342 @@
+package redislock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/caoyuewen/components/dbs/dbredis"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNotObtained = errors.New("redislock: lock not obtained") // 锁被其他实例持有
+	ErrLockLost    = errors.New("redislock: lock lost")         // 续期失败或锁已被他人获取
+	ErrNotHeld     = errors.New("redislock: lock not held")     // 释放或续期时锁已不属于自己
+)
+
+// 仅持有者可删除/续期，每个脚本只操作一个 key，集群模式下可用
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`)
+
+	refreshScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+// Options 锁配置
+type Options struct {
+	TTL           time.Duration // 锁租期，默认 30s
+	RenewInterval time.Duration // 自动续期间隔，默认 TTL/3；< 0 不自动续期
+	WaitTimeout   time.Duration // 获取锁的最长等待时间，默认 0 只尝试一次
+	RetryInterval time.Duration // 等待期间的重试间隔，默认 100ms
+	KeyPrefix     string        // key 前缀，默认 "lock:"
+}
+
+func (o *Options) setDefault() {
+	if o.TTL <= 0 {
+		o.TTL = 30 * time.Second
+	}
+	if o.RenewInterval == 0 {
+		o.RenewInterval = o.TTL / 3
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = 100 * time.Millisecond
+	}
+	if o.KeyPrefix == "" {
+		o.KeyPrefix = "lock:"
+	}
+}
+
+// Locker 锁管理器；单节点时直接使用该节点，多个独立节点时按 Redlock 算法取多数派
+type Locker struct {
+	clients []redis.UniversalClient // 为空时使用 dbredis.Client()
+	opts    Options
+}
+
+// New 基于 dbredis 全局客户端 (单机/集群/Sentinel) 的锁
+func New(opts Options) *Locker {
+	opts.setDefault()
+	return &Locker{opts: opts}
+}
+
+// NewRedlock 基于多个相互独立的 Redis 节点的 Redlock，需超过半数节点加锁成功
+func NewRedlock(clients []redis.UniversalClient, opts Options) *Locker {
+	opts.setDefault()
+	return &Locker{clients: clients, opts: opts}
+}
+
+func (l *Locker) nodes() []redis.UniversalClient {
+	if len(l.clients) > 0 {
+		return l.clients
+	}
+	return []redis.UniversalClient{dbredis.Client()}
+}
+
+func (l *Locker) quorum() int {
+	return len(l.nodes())/2 + 1
+}
+
+// ==================== 加锁 ====================
+
+// Obtain 获取锁，WaitTimeout 内拿不到时返回 ErrNotObtained
+// 返回的锁自动续期，续期失败时 Lock.Context() 被取消
+func (l *Locker) Obtain(ctx context.Context, name string) (*Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.opts.KeyPrefix + name
+	deadline := time.Now().Add(l.opts.WaitTimeout)
+	for {
+		ok, err := l.tryAcquire(ctx, key, token)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return l.newLock(ctx, name, key, token), nil
+		}
+		if !time.Now().Add(l.opts.RetryInterval).Before(deadline) {
+			return nil, ErrNotObtained
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.opts.RetryInterval):
+		}
+	}
+}
+
+// tryAcquire 在各节点上 SET NX，未达多数派时回滚已加的锁
+func (l *Locker) tryAcquire(ctx context.Context, key, token string) (bool, error) {
+	start := time.Now()
+	nodes := l.nodes()
+
+	var lastErr error
+	ok := l.each(nodes, func(c redis.UniversalClient) bool {
+		acquired, err := c.SetNX(ctx, key, token, l.opts.TTL).Result()
+		if err != nil {
+			lastErr = err
+		}
+		return acquired
+	})
+
+	// 扣除加锁耗时与时钟漂移后仍有有效期才算成功
+	drift := l.opts.TTL/100 + 2*time.Millisecond
+	if ok >= l.quorum() && time.Since(start)+drift < l.opts.TTL {
+		return true, nil
+	}
+
+	l.each(nodes, func(c redis.UniversalClient) bool {
+		_ = releaseScript.Run(context.Background(), c, []string{key}, token).Err()
+		return true
+	})
+	if ok == 0 && lastErr != nil && len(nodes) == 1 {
+		return false, lastErr
+	}
+	return false, nil
+}
+
+// each 并发在各节点执行 fn，返回成功的节点数
+func (l *Locker) each(nodes []redis.UniversalClient, fn func(c redis.UniversalClient) bool) int {
+	if len(nodes) == 1 {
+		if fn(nodes[0]) {
+			return 1
+		}
+		return 0
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		n  int
+	)
+	for _, c := range nodes {
+		wg.Add(1)
+		go func(c redis.UniversalClient) {
+			defer wg.Done()
+			if fn(c) {
+				mu.Lock()
+				n++
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+	return n
+}
+
+// ==================== 锁 ====================
+
+// Lock 已获取的锁
+type Lock struct {
+	locker *Locker
+	name   string
+	key    string
+	token  string
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (l *Locker) newLock(parent context.Context, name, key, token string) *Lock {
+	ctx, cancel := context.WithCancelCause(parent)
+	lk := &Lock{locker: l, name: name, key: key, token: token, ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	if l.opts.RenewInterval > 0 {
+		go lk.renew()
+	} else {
+		close(lk.done)
+	}
+	return lk
+}
+
+// Context 持有锁期间有效的上下文，锁丢失时被取消，context.Cause 为 ErrLockLost
+func (lk *Lock) Context() context.Context {
+	return lk.ctx
+}
+
+// Name 锁名
+func (lk *Lock) Name() string {
+	return lk.name
+}
+
+// Refresh 手动续期；锁已被他人持有 (token 不匹配) 时返回 ErrNotHeld，节点不可达等临时失败返回其他错误
+func (lk *Lock) Refresh(ctx context.Context) error {
+	ttl := lk.locker.opts.TTL.Milliseconds()
+	nodes := lk.locker.nodes()
+
+	var (
+		mu       sync.Mutex
+		mismatch int
+		lastErr  error
+	)
+	ok := lk.locker.each(nodes, func(c redis.UniversalClient) bool {
+		n, err := refreshScript.Run(ctx, c, []string{lk.key}, lk.token, ttl).Int64()
+		if err == nil && n == 1 {
+			return true
+		}
+		mu.Lock()
+		if err != nil {
+			lastErr = err
+		} else {
+			mismatch++
+		}
+		mu.Unlock()
+		return false
+	})
+	if ok >= lk.locker.quorum() {
+		return nil
+	}
+	// token 不匹配的节点过多，即使其余节点恢复也达不到多数派
+	if len(nodes)-mismatch < lk.locker.quorum() {
+		return ErrNotHeld
+	}
+	return fmt.Errorf("redislock: refresh %s: %w", lk.name, lastErr)
+}
+
+// Release 释放锁并停止续期；锁已不属于自己时返回 ErrNotHeld
+func (lk *Lock) Release(ctx context.Context) error {
+	lk.once.Do(func() { lk.cancel(context.Canceled) })
+	<-lk.done
+
+	ok := lk.locker.each(lk.locker.nodes(), func(c redis.UniversalClient) bool {
+		n, err := releaseScript.Run(ctx, c, []string{lk.key}, lk.token).Int64()
+		return err == nil && n == 1
+	})
+	if ok < lk.locker.quorum() {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// renew 定期续期，锁被他人持有时立即取消上下文；临时失败则重试，超过租期仍未续期成功时取消
+func (lk *Lock) renew() {
+	defer close(lk.done)
+
+	opts := lk.locker.opts
+	ticker := time.NewTicker(opts.RenewInterval)
+	defer ticker.Stop()
+
+	validUntil := time.Now().Add(opts.TTL)
+	for {
+		select {
+		case <-lk.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(lk.ctx, opts.RenewInterval)
+		err := lk.Refresh(ctx)
+		cancel()
+		if err == nil {
+			validUntil = time.Now().Add(opts.TTL)
+			continue
+		}
+		if lk.ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, ErrNotHeld) || time.Now().After(validUntil) {
+			log.Warnf("[REDISLOCK] lock %s lost: %v", lk.name, err)
+			lk.once.Do(func() { lk.cancel(ErrLockLost) })
+			return
+		}
+		log.Warnf("[REDISLOCK] lock %s refresh failed, retry: %v", lk.name, err)
+	}
+}
+
+// ==================== 互斥执行 ====================
+
+// RunExclusive 获取锁后执行 fn，完成后释放；锁被其他实例持有时返回 ErrNotObtained
+// fn 收到的 ctx 在锁丢失时被取消，此时返回 ErrLockLost
+func (l *Locker) RunExclusive(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	lk, err := l.Obtain(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lk.Release(context.Background()); err != nil && !errors.Is(err, ErrNotHeld) {
+			log.Warnf("[REDISLOCK] release %s err: %v", name, err)
+		}
+	}()
+
+	err = fn(lk.Context())
+	if cause := context.Cause(lk.Context()); errors.Is(cause, ErrLockLost) {
+		return errors.Join(ErrLockLost, err)
+	}
+	return err
+}
+
+var std = New(Options{})
+
+// RunExclusive 使用 dbredis 全局客户端与默认配置互斥执行
+func RunExclusive(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return std.RunExclusive(ctx, name, fn)
+}
+
+// Obtain 使用 dbredis 全局客户端与默认配置获取锁
+func Obtain(ctx context.Context, name string) (*Lock, error) {
+	return std.Obtain(ctx, name)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
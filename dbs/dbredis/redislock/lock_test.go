@@ -0,0 +1,110 @@
+package redislock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestOptionsDefault(t *testing.T) {
+	o := Options{TTL: 9 * time.Second}
+	o.setDefault()
+	if o.RenewInterval != 3*time.Second || o.RetryInterval != 100*time.Millisecond || o.KeyPrefix != "lock:" {
+		t.Fatalf("unexpected defaults: %+v", o)
+	}
+
+	o = Options{RenewInterval: -1}
+	o.setDefault()
+	if o.TTL != 30*time.Second || o.RenewInterval != -1 {
+		t.Fatalf("unexpected defaults: %+v", o)
+	}
+}
+
+func TestQuorum(t *testing.T) {
+	for n, want := range map[int]int{1: 1, 2: 2, 3: 2, 4: 3, 5: 3} {
+		l := NewRedlock(make([]redis.UniversalClient, n), Options{})
+		if got := l.quorum(); got != want {
+			t.Fatalf("quorum(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	a, _ := newToken()
+	b, _ := newToken()
+	if len(a) != 32 || a == b {
+		t.Fatalf("bad tokens %q %q", a, b)
+	}
+}
+
+// TestLockRedis 需要本地 Redis：REDIS_ADDR=127.0.0.1:6379 go test ./dbs/dbredis/redislock
+func TestLockRedis(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	defer rdb.Close()
+
+	ctx := context.Background()
+	l := NewRedlock([]redis.UniversalClient{rdb}, Options{
+		TTL:           300 * time.Millisecond,
+		RenewInterval: 100 * time.Millisecond,
+		KeyPrefix:     "test-lock-" + time.Now().Format("150405.000") + ":",
+	})
+
+	lk, err := l.Obtain(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 竞争
+	if _, err = l.Obtain(ctx, "a"); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("want ErrNotObtained, got %v", err)
+	}
+
+	// 非持有者释放
+	other := &Lock{locker: l, name: "a", key: lk.key, token: "other", done: make(chan struct{})}
+	other.cancel = func(error) {}
+	close(other.done)
+	if err = other.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("want ErrNotHeld, got %v", err)
+	}
+
+	// 自动续期超过 TTL 仍持有
+	time.Sleep(700 * time.Millisecond)
+	if lk.Context().Err() != nil {
+		t.Fatalf("lock lost during renewal: %v", context.Cause(lk.Context()))
+	}
+	if v, _ := rdb.Get(ctx, lk.key).Result(); v != lk.token {
+		t.Fatalf("key holder = %q, want %q", v, lk.token)
+	}
+	if err = lk.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 锁被他人获取后立即丢失，而不是等到租期结束
+	lk, err = l.Obtain(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = rdb.Set(ctx, lk.key, "other", time.Minute).Err(); err != nil {
+		t.Fatal(err)
+	}
+	defer rdb.Del(ctx, lk.key)
+	if err = lk.Refresh(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Fatalf("want ErrNotHeld, got %v", err)
+	}
+	select {
+	case <-lk.Context().Done():
+		if !errors.Is(context.Cause(lk.Context()), ErrLockLost) {
+			t.Fatalf("cause = %v", context.Cause(lk.Context()))
+		}
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("lock loss not signalled within one renew interval")
+	}
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caoyuewen/components/common/caches"
+	"github.com/caoyuewen/components/dbs/dbmongo"
+	"github.com/caoyuewen/components/dbs/dbmysql"
+	"github.com/caoyuewen/components/dbs/dbredis"
+	"github.com/caoyuewen/components/third/tictok"
+	"gorm.io/gorm"
+)
+
+// ==================== 基础设施 ====================
+
+// MySQL 命名 MySQL 连接的 Ping 与连接池统计，name 为空时检查默认实例
+func MySQL(name string) Check {
+	checkName := "mysql"
+	if name != "" && name != dbmysql.DefaultName {
+		checkName += ":" + name
+	}
+	return Check{
+		Name:     checkName,
+		Critical: true,
+		Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			var (
+				db    *gorm.DB
+				stats map[string]interface{}
+			)
+			if name == "" || name == dbmysql.DefaultName {
+				if !dbmysql.IsInitialized() {
+					return nil, errors.New("not initialized")
+				}
+				db, stats = dbmysql.Client(), dbmysql.Stats()
+			} else {
+				ins, ok := dbmysql.Get(name)
+				if !ok || !ins.IsInitialized() {
+					return nil, errors.New("not initialized")
+				}
+				db, stats = ins.DB(), ins.Stats()
+			}
+
+			sqlDB, err := db.DB()
+			if err != nil {
+				return stats, err
+			}
+			return stats, sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// Redis dbredis 全局客户端的 Ping 与连接池统计
+func Redis() Check {
+	return Check{
+		Name:     "redis",
+		Critical: true,
+		Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			if !dbredis.IsInitialized() {
+				return nil, errors.New("not initialized")
+			}
+			return dbredis.Stats(), dbredis.Client().Ping(ctx).Err()
+		},
+	}
+}
+
+// Mongo dbmongo 全局客户端按配置的读偏好 Ping 与连接池统计
+func Mongo() Check {
+	return Check{
+		Name:     "mongo",
+		Critical: true,
+		Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			c, ok := dbmongo.TryClient()
+			if !ok {
+				return nil, errors.New("not initialized")
+			}
+			return dbmongo.Stats(), c.Ping(ctx, nil)
+		},
+	}
+}
+
+// ==================== 业务 ====================
+
+// UsdtAddressPool USDT 地址池剩余地址数，少于 min 时失败；非关键检查
+func UsdtAddressPool(min int64) Check {
+	return Check{
+		Name: "usdt_address_pool",
+		Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			if !dbredis.IsInitialized() {
+				return nil, errors.New("redis not initialized")
+			}
+			count, err := caches.UsdtAddress.PoolCount()
+			if err != nil {
+				return nil, err
+			}
+			details := map[string]interface{}{"count": count, "min": min}
+			if count < min {
+				return details, fmt.Errorf("address pool has %d addresses, want >= %d", count, min)
+			}
+			return details, nil
+		},
+	}
+}
+
+// TictokAccessToken 抖音 access token 剩余有效期，不存在或少于 minTTL 时失败；非关键检查
+func TictokAccessToken(minTTL time.Duration) Check {
+	return Check{
+		Name: "tictok_access_token",
+		Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			if !dbredis.IsInitialized() {
+				return nil, errors.New("redis not initialized")
+			}
+			ttl, err := dbredis.Client().TTL(ctx, tictok.RedisAccessTokenKey).Result()
+			if err != nil {
+				return nil, err
+			}
+			details := map[string]interface{}{"ttl_sec": int64(ttl.Seconds())}
+			switch {
+			case ttl == -2: // key 不存在
+				return details, errors.New("access token not found")
+			case ttl >= 0 && ttl < minTTL:
+				return details, fmt.Errorf("access token expires in %s", ttl)
+			}
+			return details, nil
+		},
+	}
+}
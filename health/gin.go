@@ -0,0 +1,38 @@
+package health
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var startedAt = time.Now()
+
+// LivenessHandler 存活探针，只要进程能响应即为 up，不检查外部依赖
+func LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":     StatusUp,
+			"uptime_sec": int64(time.Since(startedAt).Seconds()),
+		})
+	}
+}
+
+// ReadinessHandler 就绪探针，执行注册表中的检查，关键检查失败或停机中返回 503
+func (r *Registry) ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := r.Run(c.Request.Context())
+		code := http.StatusOK
+		if report.Status != StatusUp {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, report)
+	}
+}
+
+// RegisterRoutes 注册 GET /healthz (存活) 与 GET /readyz (就绪)，使用默认注册表
+func RegisterRoutes(g gin.IRoutes) {
+	g.GET("/healthz", LivenessHandler())
+	g.GET("/readyz", std.ReadinessHandler())
+}
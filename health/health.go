@@ -0,0 +1,208 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caoyuewen/components/util"
+)
+
+// Status 检查状态
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// ErrTimeout 检查超时
+var ErrTimeout = errors.New("health: check timeout")
+
+// CheckFunc 检查函数，返回的 details 会原样输出 (如连接池统计)
+type CheckFunc func(ctx context.Context) (details map[string]interface{}, err error)
+
+// Check 健康检查项
+type Check struct {
+	Name     string        // 名称，唯一
+	Fn       CheckFunc     // 检查函数
+	Timeout  time.Duration // 单次检查超时，默认 3s
+	CacheTTL time.Duration // 结果缓存时长，默认 5s，< 0 不缓存
+	Critical bool          // 关键依赖，失败时 readiness 返回 down；非关键只展示
+}
+
+func (c *Check) setDefault() {
+	if c.Timeout <= 0 {
+		c.Timeout = 3 * time.Second
+	}
+	if c.CacheTTL == 0 {
+		c.CacheTTL = 5 * time.Second
+	}
+}
+
+// Result 单项检查结果
+type Result struct {
+	Name       string                 `json:"name"`
+	Status     Status                 `json:"status"`
+	Critical   bool                   `json:"critical"`
+	Error      string                 `json:"error,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+	CheckedAt  time.Time              `json:"checked_at"`
+}
+
+// Report 汇总结果，任一关键检查失败时为 down
+type Report struct {
+	Status    Status    `json:"status"`
+	Checks    []Result  `json:"checks"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Registry 检查项注册表，并发执行所有检查并按 CacheTTL 缓存结果
+type Registry struct {
+	mu           sync.RWMutex
+	checks       map[string]*entry
+	shuttingDown atomic.Bool
+}
+
+type entry struct {
+	check Check
+
+	mu      sync.Mutex // 同一检查同时只执行一次，并发请求等待同一结果
+	last    Result
+	expires time.Time
+}
+
+// NewRegistry 创建注册表
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]*entry)}
+}
+
+var std = NewRegistry()
+
+// Default 默认注册表
+func Default() *Registry {
+	return std
+}
+
+// Register 在默认注册表注册检查项
+func Register(checks ...Check) {
+	std.Register(checks...)
+}
+
+// ==================== 注册 ====================
+
+// Register 注册检查项，同名覆盖
+func (r *Registry) Register(checks ...Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range checks {
+		if c.Name == "" || c.Fn == nil {
+			panic("health: check requires Name and Fn")
+		}
+		c.setDefault()
+		r.checks[c.Name] = &entry{check: c}
+	}
+}
+
+// Unregister 移除检查项
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// SetShuttingDown 标记进入停机流程，readiness 立即返回 down，便于负载均衡摘除流量
+func (r *Registry) SetShuttingDown(v bool) {
+	r.shuttingDown.Store(v)
+}
+
+// ==================== 执行 ====================
+
+// Run 并发执行所有检查 (命中缓存的直接返回)，结果按名称排序
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.checks))
+	for _, e := range r.checks {
+		entries = append(entries, e)
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.result(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := Report{Status: StatusUp, Checks: results, CheckedAt: time.Now()}
+	for _, res := range results {
+		if res.Critical && res.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	if r.shuttingDown.Load() {
+		report.Status = StatusDown
+	}
+	return report
+}
+
+// result 返回缓存结果，过期时执行检查
+func (e *entry) result(ctx context.Context) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if time.Now().Before(e.expires) {
+		return e.last
+	}
+	e.last = e.run(ctx)
+	if e.check.CacheTTL > 0 {
+		e.expires = time.Now().Add(e.check.CacheTTL)
+	}
+	return e.last
+}
+
+// run 执行单次检查，超时或 panic 均视为失败
+func (e *entry) run(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, e.check.Timeout)
+	defer cancel()
+
+	type outcome struct {
+		details map[string]interface{}
+		err     error
+	}
+	ch := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		var o outcome
+		util.TryCatch(func() {
+			o.details, o.err = e.check.Fn(ctx)
+		}, func(p interface{}) {
+			o.err = fmt.Errorf("panic: %v", p)
+		})
+		ch <- o
+	}()
+
+	res := Result{Name: e.check.Name, Status: StatusUp, Critical: e.check.Critical}
+	select {
+	case o := <-ch:
+		res.Details = o.details
+		if o.err != nil {
+			res.Status, res.Error = StatusDown, o.err.Error()
+		}
+	case <-ctx.Done():
+		res.Status, res.Error = StatusDown, ErrTimeout.Error()
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	res.CheckedAt = time.Now()
+	return res
+}
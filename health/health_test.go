@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegistryRun(t *testing.T) {
+	r := NewRegistry()
+	var calls atomic.Int64
+	r.Register(
+		Check{Name: "db", Critical: true, Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			calls.Add(1)
+			return map[string]interface{}{"open": 1}, nil
+		}},
+		Check{Name: "pool", Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, errors.New("empty")
+		}},
+		Check{Name: "slow", Timeout: 20 * time.Millisecond, Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			return nil, nil
+		}},
+		Check{Name: "panic", Fn: func(ctx context.Context) (map[string]interface{}, error) {
+			panic("boom")
+		}},
+	)
+
+	rep := r.Run(context.Background())
+	if rep.Status != StatusUp {
+		t.Fatalf("non-critical failures should not fail readiness: %+v", rep)
+	}
+	want := map[string]Status{"db": StatusUp, "panic": StatusDown, "pool": StatusDown, "slow": StatusDown}
+	for i, res := range rep.Checks {
+		if res.Status != want[res.Name] {
+			t.Errorf("%s: %s (%s)", res.Name, res.Status, res.Error)
+		}
+		if i > 0 && rep.Checks[i-1].Name > res.Name {
+			t.Errorf("results not sorted")
+		}
+		if res.Name == "slow" && res.Error != ErrTimeout.Error() {
+			t.Errorf("slow error = %q", res.Error)
+		}
+	}
+
+	r.Run(context.Background())
+	if calls.Load() != 1 {
+		t.Fatalf("cached result expected, calls = %d", calls.Load())
+	}
+
+	r.Register(Check{Name: "db", Critical: true, Fn: func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, errors.New("down")
+	}})
+	if rep = r.Run(context.Background()); rep.Status != StatusDown {
+		t.Fatalf("critical failure should fail readiness: %+v", rep)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRegistry()
+	r.Register(Check{Name: "ok", Critical: true, Fn: func(ctx context.Context) (map[string]interface{}, error) { return nil, nil }})
+
+	e := gin.New()
+	e.GET("/healthz", LivenessHandler())
+	e.GET("/readyz", r.ReadinessHandler())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get("/healthz"); w.Code != http.StatusOK {
+		t.Fatalf("healthz = %d", w.Code)
+	}
+	w := get("/readyz")
+	var rep Report
+	if err := json.Unmarshal(w.Body.Bytes(), &rep); err != nil || w.Code != http.StatusOK || rep.Status != StatusUp || len(rep.Checks) != 1 {
+		t.Fatalf("readyz = %d %s", w.Code, w.Body.String())
+	}
+
+	r.SetShuttingDown(true)
+	if w := get("/readyz"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz while shutting down = %d", w.Code)
+	}
+	if w := get("/healthz"); w.Code != http.StatusOK {
+		t.Fatalf("healthz while shutting down = %d", w.Code)
+	}
+}
+
+func TestBuiltinChecksNotInitialized(t *testing.T) {
+	r := NewRegistry()
+	r.Register(MySQL(""), Redis(), Mongo(), UsdtAddressPool(1), TictokAccessToken(time.Minute))
+	rep := r.Run(context.Background())
+	if rep.Status != StatusDown || len(rep.Checks) != 5 {
+		t.Fatalf("report = %+v", rep)
+	}
+	for _, res := range rep.Checks {
+		if res.Status != StatusDown || res.Error == "" {
+			t.Errorf("%s: %+v", res.Name, res)
+		}
+	}
+}
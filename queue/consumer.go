@@ -0,0 +1,296 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caoyuewen/components/util"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrVisibilityTimeout 消息超过 VisibilityTimeout 未确认，被回收重投
+var ErrVisibilityTimeout = errors.New("queue: visibility timeout")
+
+// Handler 消息处理函数，返回 nil 确认消息，返回错误按退避重试，超过最大次数进入死信
+type Handler func(ctx context.Context, msg *Message) error
+
+// promoteScript 将到期的延迟消息转入主队列
+// KEYS: delayed, stream  ARGV: now_ms, limit, maxlen
+var promoteScript = redis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, tonumber(ARGV[2]))
+local maxlen = tonumber(ARGV[3])
+for _, m in ipairs(due) do
+	local p1 = string.find(m, "\n", 1, true)
+	local p2 = p1 and string.find(m, "\n", p1 + 1, true)
+	local p3 = p2 and string.find(m, "\n", p2 + 1, true)
+	if p3 then
+		local fields = {"body", string.sub(m, p3 + 1), "attempts", string.sub(m, p1 + 1, p2 - 1), "enqueued_at", string.sub(m, p2 + 1, p3 - 1)}
+		if maxlen > 0 then
+			redis.call("XADD", KEYS[2], "MAXLEN", "~", maxlen, "*", unpack(fields))
+		else
+			redis.call("XADD", KEYS[2], "*", unpack(fields))
+		end
+	end
+	redis.call("ZREM", KEYS[1], m)
+end
+return #due`)
+
+// settleScript 确认消息并按 mode 处理：ack 删除 / retry 立即重投 / delay 延迟重投 / dead 转入死信
+// 只有 XACK 成功 (消息仍归属本消费组未确认) 时才执行，避免超时回收后原消费者重复重投
+// KEYS: stream, delayed, dead
+// ARGV: group, id, mode, body, attempts, enqueued_at, due_ms, delayed_member, error, failed_at, dead_maxlen
+var settleScript = redis.NewScript(`
+if redis.call("XACK", KEYS[1], ARGV[1], ARGV[2]) == 0 then
+	return 0
+end
+redis.call("XDEL", KEYS[1], ARGV[2])
+local mode = ARGV[3]
+if mode == "retry" then
+	redis.call("XADD", KEYS[1], "*", "body", ARGV[4], "attempts", ARGV[5], "enqueued_at", ARGV[6])
+elseif mode == "delay" then
+	redis.call("ZADD", KEYS[2], ARGV[7], ARGV[8])
+elseif mode == "dead" then
+	redis.call("XADD", KEYS[3], "MAXLEN", "~", ARGV[11], "*", "body", ARGV[4], "attempts", ARGV[5],
+		"enqueued_at", ARGV[6], "error", ARGV[9], "failed_at", ARGV[10], "source_id", ARGV[2])
+end
+return 1`)
+
+const (
+	settleAck   = "ack"
+	settleRetry = "retry"
+	settleDelay = "delay"
+	settleDead  = "dead"
+
+	promoteBatch = 100
+	reclaimBatch = 100
+)
+
+// ==================== 消费 ====================
+
+// Run 启动 Concurrency 个消费者处理消息，阻塞直到 ctx 结束
+// ctx 结束后不再拉取新消息，等待处理中的消息完成后返回
+func (q *Queue) Run(ctx context.Context, h Handler) error {
+	if err := q.ensureGroup(ctx); err != nil {
+		return err
+	}
+	log.Infof("[QUEUE] %s consumer %s started, concurrency %d", q.name, q.opts.Consumer, q.opts.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.worker(ctx, h)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.maintain(ctx)
+	}()
+	wg.Wait()
+
+	log.Infof("[QUEUE] %s consumer %s stopped", q.name, q.opts.Consumer)
+	return nil
+}
+
+// ensureGroup 创建消费组 (从头消费已有消息)，已存在时忽略
+func (q *Queue) ensureGroup(ctx context.Context) error {
+	err := q.client().XGroupCreateMkStream(ctx, q.streamKey(), q.opts.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("queue: create group %s on %s: %w", q.opts.Group, q.name, err)
+	}
+	return nil
+}
+
+// worker 阻塞拉取并处理消息
+func (q *Queue) worker(ctx context.Context, h Handler) {
+	for ctx.Err() == nil {
+		streams, err := q.client().XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.opts.Group,
+			Consumer: q.opts.Consumer,
+			Streams:  []string{q.streamKey(), ">"},
+			Count:    1,
+			Block:    q.opts.BlockTimeout,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			if isNoGroup(err) {
+				_ = q.ensureGroup(ctx)
+			} else {
+				log.Errorf("[QUEUE] %s read err: %v", q.name, err)
+			}
+			sleep(ctx, time.Second)
+			continue
+		}
+
+		for _, s := range streams {
+			for _, x := range s.Messages {
+				msg := q.toMessage(x)
+				q.handle(ctx, h, &msg)
+			}
+		}
+	}
+}
+
+// handle 处理单条消息，ctx 取消后仍允许在 VisibilityTimeout 内处理完成
+func (q *Queue) handle(ctx context.Context, h Handler, msg *Message) {
+	q.metrics.inFlight.Add(1)
+	defer q.metrics.inFlight.Add(-1)
+
+	hctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.opts.VisibilityTimeout)
+	defer cancel()
+
+	var err error
+	util.TryCatch(func() {
+		err = h(hctx, msg)
+	}, func(r interface{}) {
+		err = fmt.Errorf("panic: %v", r)
+	})
+
+	if err == nil {
+		q.metrics.processed.Add(1)
+		if _, serr := q.settle(msg, settleAck, ""); serr != nil {
+			log.Errorf("[QUEUE] %s ack %s err: %v", q.name, msg.ID, serr)
+		}
+		return
+	}
+	q.metrics.failed.Add(1)
+	log.Warnf("[QUEUE] %s message %s attempt %d failed: %v", q.name, msg.ID, msg.Attempts, err)
+	q.fail(msg, err)
+}
+
+// fail 失败处理：未超过最大次数时按退避重投，否则转入死信
+func (q *Queue) fail(msg *Message, cause error) {
+	mode := settleDead
+	if msg.Attempts < q.opts.MaxAttempts {
+		mode = settleDelay
+		if q.opts.Backoff(msg.Attempts) <= 0 {
+			mode = settleRetry
+		}
+	}
+
+	ok, err := q.settle(msg, mode, cause.Error())
+	if err != nil {
+		log.Errorf("[QUEUE] %s settle %s (%s) err: %v", q.name, msg.ID, mode, err)
+		return
+	}
+	if !ok {
+		return // 已被其他消费者回收处理
+	}
+	if mode == settleDead {
+		q.metrics.deadLettered.Add(1)
+		log.Errorf("[QUEUE] %s message %s dead-lettered after %d attempts: %v", q.name, msg.ID, msg.Attempts, cause)
+	} else {
+		q.metrics.retried.Add(1)
+	}
+}
+
+// settle 执行 settleScript，返回消息是否仍由本消费组持有
+func (q *Queue) settle(msg *Message, mode, errMsg string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 重投时为下一次的投递次数，死信时为已投递次数
+	attempts := msg.Attempts + 1
+	if mode == settleDead {
+		attempts = msg.Attempts
+	}
+
+	var dueMs int64
+	var member string
+	if mode == settleDelay {
+		var err error
+		if member, err = encodeDelayed(attempts, msg.EnqueuedAt, msg.Body); err != nil {
+			return false, err
+		}
+		dueMs = time.Now().Add(q.opts.Backoff(msg.Attempts)).UnixMilli()
+	}
+
+	n, err := settleScript.Run(ctx, q.client(),
+		[]string{q.streamKey(), q.delayedKey(), q.deadKey()},
+		q.opts.Group, msg.ID, mode, msg.Body, attempts, msg.EnqueuedAt.UnixMilli(),
+		dueMs, member, errMsg, time.Now().UnixMilli(), q.opts.DeadMaxLen,
+	).Int()
+	return n == 1, err
+}
+
+// ==================== 延迟转移与超时回收 ====================
+
+// maintain 定期转移到期的延迟消息，并回收超过 VisibilityTimeout 未确认的消息
+func (q *Queue) maintain(ctx context.Context) {
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := q.promote(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("[QUEUE] %s promote delayed err: %v", q.name, err)
+		}
+		if err := q.reclaim(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("[QUEUE] %s reclaim err: %v", q.name, err)
+		}
+	}
+}
+
+// promote 转移到期的延迟消息，每批 promoteBatch 条直到没有到期消息
+func (q *Queue) promote(ctx context.Context) error {
+	for {
+		n, err := promoteScript.Run(ctx, q.client(), []string{q.delayedKey(), q.streamKey()},
+			time.Now().UnixMilli(), promoteBatch, q.opts.MaxLen).Int()
+		if err != nil || n < promoteBatch {
+			return err
+		}
+	}
+}
+
+// reclaim 用 XAUTOCLAIM 认领超时消息，按一次失败处理 (重投或死信)
+// 原消费者之后再确认时 XACK 失败，不会重复重投
+func (q *Queue) reclaim(ctx context.Context) error {
+	start := "0-0"
+	for {
+		msgs, next, err := q.client().XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   q.streamKey(),
+			Group:    q.opts.Group,
+			MinIdle:  q.opts.VisibilityTimeout,
+			Start:    start,
+			Count:    reclaimBatch,
+			Consumer: q.opts.Consumer,
+		}).Result()
+		if err != nil {
+			if isNoGroup(err) {
+				return nil
+			}
+			return err
+		}
+
+		for _, x := range msgs {
+			msg := q.toMessage(x)
+			q.metrics.reclaimed.Add(1)
+			log.Warnf("[QUEUE] %s reclaimed message %s (attempt %d)", q.name, msg.ID, msg.Attempts)
+			q.fail(&msg, ErrVisibilityTimeout)
+		}
+		if next == "0-0" || next == "" || next == start {
+			return nil
+		}
+		start = next
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
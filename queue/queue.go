@@ -0,0 +1,319 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/caoyuewen/components/dbs/dbredis"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidDelayed 延迟队列中的成员格式错误
+var ErrInvalidDelayed = errors.New("queue: invalid delayed member")
+
+// Options 队列配置
+type Options struct {
+	Client            redis.UniversalClient           // Redis 客户端，默认 dbredis.Client()
+	Group             string                          // 消费组，默认 "default"
+	Consumer          string                          // 消费者名，默认 hostname-pid
+	Concurrency       int                             // 并发处理数，默认 1
+	VisibilityTimeout time.Duration                   // 消息处理超时，超时未确认的消息会被重新投递，默认 5m
+	MaxAttempts       int                             // 最大投递次数，超过后进入死信队列，默认 5
+	Backoff           func(attempt int) time.Duration // 第 attempt 次失败后的重试延迟，默认 1s 起指数增长，最长 10m
+	BlockTimeout      time.Duration                   // XREADGROUP 阻塞时长，默认 5s
+	PollInterval      time.Duration                   // 延迟消息转移与超时回收的间隔，默认 1s
+	MaxLen            int64                           // 主队列近似最大长度，0 不限制
+	DeadMaxLen        int64                           // 死信队列近似最大长度，默认 10000
+}
+
+func (o *Options) setDefault() {
+	if o.Group == "" {
+		o.Group = "default"
+	}
+	if o.Consumer == "" {
+		host, _ := os.Hostname()
+		o.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 1
+	}
+	if o.VisibilityTimeout <= 0 {
+		o.VisibilityTimeout = 5 * time.Minute
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.Backoff == nil {
+		o.Backoff = ExponentialBackoff(time.Second, 10*time.Minute)
+	}
+	if o.BlockTimeout <= 0 {
+		o.BlockTimeout = 5 * time.Second
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.DeadMaxLen <= 0 {
+		o.DeadMaxLen = 10000
+	}
+}
+
+// ExponentialBackoff 指数退避：base * 2^(attempt-1)，不超过 max
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
+
+// Message 队列消息
+type Message struct {
+	ID         string    // Stream 消息 ID
+	Queue      string    // 队列名
+	Body       []byte    // 消息体
+	Attempts   int       // 本次是第几次投递，从 1 开始
+	EnqueuedAt time.Time // 首次入队时间
+	Error      string    // 死信消息的最后一次错误
+}
+
+// Decode 按 JSON 解码消息体
+func (m *Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Body, v)
+}
+
+// Queue 基于 Redis Streams 消费组的可靠队列，至少投递一次，处理函数需幂等
+// 同一队列的 key 使用相同的 hash tag，集群模式下可在脚本与事务中同时操作
+type Queue struct {
+	name    string
+	opts    Options
+	metrics metrics
+}
+
+// New 创建队列
+func New(name string, opts Options) *Queue {
+	opts.setDefault()
+	return &Queue{name: name, opts: opts}
+}
+
+// Name 队列名
+func (q *Queue) Name() string {
+	return q.name
+}
+
+func (q *Queue) client() redis.UniversalClient {
+	if q.opts.Client != nil {
+		return q.opts.Client
+	}
+	return dbredis.Client()
+}
+
+func (q *Queue) streamKey() string  { return "queue:{" + q.name + "}:stream" }
+func (q *Queue) delayedKey() string { return "queue:{" + q.name + "}:delayed" }
+func (q *Queue) deadKey() string    { return "queue:{" + q.name + "}:dead" }
+
+// ==================== 入队 ====================
+
+// Enqueue 入队，delay > 0 时延迟投递；返回 Stream 消息 ID，延迟消息返回空串
+func (q *Queue) Enqueue(ctx context.Context, body []byte, delay time.Duration) (string, error) {
+	now := time.Now()
+	if delay > 0 {
+		member, err := encodeDelayed(1, now, body)
+		if err != nil {
+			return "", err
+		}
+		err = q.client().ZAdd(ctx, q.delayedKey(), redis.Z{Score: float64(now.Add(delay).UnixMilli()), Member: member}).Err()
+		if err != nil {
+			return "", err
+		}
+		q.metrics.enqueued.Add(1)
+		return "", nil
+	}
+
+	args := &redis.XAddArgs{
+		Stream: q.streamKey(),
+		Values: []interface{}{"body", body, "attempts", 1, "enqueued_at", now.UnixMilli()},
+	}
+	if q.opts.MaxLen > 0 {
+		args.MaxLen = q.opts.MaxLen
+		args.Approx = true
+	}
+	id, err := q.client().XAdd(ctx, args).Result()
+	if err != nil {
+		return "", err
+	}
+	q.metrics.enqueued.Add(1)
+	return id, nil
+}
+
+// EnqueueJSON 将 v 编码为 JSON 后入队
+func (q *Queue) EnqueueJSON(ctx context.Context, v interface{}, delay time.Duration) (string, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return q.Enqueue(ctx, body, delay)
+}
+
+// ==================== 死信 ====================
+
+// DeadLetters 查询死信消息，按时间正序
+func (q *Queue) DeadLetters(ctx context.Context, count int64) ([]Message, error) {
+	list, err := q.client().XRangeN(ctx, q.deadKey(), "-", "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]Message, 0, len(list))
+	for _, x := range list {
+		msgs = append(msgs, q.toMessage(x))
+	}
+	return msgs, nil
+}
+
+// RequeueDead 将死信消息重新入队，投递次数清零
+func (q *Queue) RequeueDead(ctx context.Context, id string) error {
+	list, err := q.client().XRangeN(ctx, q.deadKey(), id, id, 1).Result()
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("queue: dead letter %s not found", id)
+	}
+	msg := q.toMessage(list[0])
+
+	_, err = q.client().TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.streamKey(),
+			Values: []interface{}{"body", msg.Body, "attempts", 1, "enqueued_at", msg.EnqueuedAt.UnixMilli()},
+		})
+		p.XDel(ctx, q.deadKey(), id)
+		return nil
+	})
+	return err
+}
+
+// ==================== 统计 ====================
+
+// Stats 队列统计，Redis 部分为全局数据，计数器为本实例累计
+type Stats struct {
+	Queue        string `json:"queue"`
+	Ready        int64  `json:"ready"`         // 主队列中尚未确认的消息 (含处理中)
+	Pending      int64  `json:"pending"`       // 已投递未确认
+	Delayed      int64  `json:"delayed"`       // 等待延迟投递
+	Dead         int64  `json:"dead"`          // 死信
+	Enqueued     int64  `json:"enqueued"`      // 本实例入队数
+	Processed    int64  `json:"processed"`     // 本实例处理成功数
+	Failed       int64  `json:"failed"`        // 本实例处理失败数
+	Retried      int64  `json:"retried"`       // 本实例安排重试数
+	DeadLettered int64  `json:"dead_lettered"` // 本实例转入死信数
+	Reclaimed    int64  `json:"reclaimed"`     // 本实例回收的超时消息数
+	InFlight     int64  `json:"in_flight"`     // 本实例正在处理数
+}
+
+type metrics struct {
+	enqueued, processed, failed, retried, deadLettered, reclaimed, inFlight atomic.Int64
+}
+
+// Stats 获取统计
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	s := Stats{
+		Queue:        q.name,
+		Enqueued:     q.metrics.enqueued.Load(),
+		Processed:    q.metrics.processed.Load(),
+		Failed:       q.metrics.failed.Load(),
+		Retried:      q.metrics.retried.Load(),
+		DeadLettered: q.metrics.deadLettered.Load(),
+		Reclaimed:    q.metrics.reclaimed.Load(),
+		InFlight:     q.metrics.inFlight.Load(),
+	}
+
+	rdb := q.client()
+	var (
+		ready   *redis.IntCmd
+		delayed *redis.IntCmd
+		dead    *redis.IntCmd
+		pending *redis.XPendingCmd
+	)
+	_, _ = rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		ready = p.XLen(ctx, q.streamKey())
+		delayed = p.ZCard(ctx, q.delayedKey())
+		dead = p.XLen(ctx, q.deadKey())
+		pending = p.XPending(ctx, q.streamKey(), q.opts.Group)
+		return nil
+	})
+	for _, cmd := range []*redis.IntCmd{ready, delayed, dead} {
+		if err := cmd.Err(); err != nil {
+			return s, err
+		}
+	}
+	s.Ready, s.Delayed, s.Dead = ready.Val(), delayed.Val(), dead.Val()
+	// 尚未创建消费组时 XPENDING 报错，视为 0
+	if p, err := pending.Result(); err == nil {
+		s.Pending = p.Count
+	}
+	return s, nil
+}
+
+// ==================== 辅助函数 ====================
+
+// toMessage 解析 Stream 消息字段
+func (q *Queue) toMessage(x redis.XMessage) Message {
+	msg := Message{ID: x.ID, Queue: q.name, Attempts: 1}
+	if v, ok := x.Values["body"].(string); ok {
+		msg.Body = []byte(v)
+	}
+	if v, ok := x.Values["attempts"].(string); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			msg.Attempts = n
+		}
+	}
+	if v, ok := x.Values["enqueued_at"].(string); ok {
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			msg.EnqueuedAt = time.UnixMilli(ms)
+		}
+	}
+	if v, ok := x.Values["error"].(string); ok {
+		msg.Error = v
+	}
+	return msg
+}
+
+// encodeDelayed 延迟消息编码为 "nonce\nattempts\nenqueued_at\nbody"，nonce 保证相同内容的消息不会合并
+func encodeDelayed(attempts int, enqueuedAt time.Time, body []byte) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b) + "\n" + strconv.Itoa(attempts) + "\n" +
+		strconv.FormatInt(enqueuedAt.UnixMilli(), 10) + "\n" + string(body), nil
+}
+
+// decodeDelayed encodeDelayed 的逆过程，与 promoteScript 的解析一致
+func decodeDelayed(member string) (attempts int, enqueuedAt time.Time, body []byte, err error) {
+	parts := strings.SplitN(member, "\n", 4)
+	if len(parts) != 4 {
+		return 0, time.Time{}, nil, ErrInvalidDelayed
+	}
+	if attempts, err = strconv.Atoi(parts[1]); err != nil {
+		return 0, time.Time{}, nil, ErrInvalidDelayed
+	}
+	ms, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, nil, ErrInvalidDelayed
+	}
+	return attempts, time.UnixMilli(ms), []byte(parts[3]), nil
+}
+
+func isNoGroup(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "NOGROUP")
+}
@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestDelayedCodec(t *testing.T) {
+	at := time.UnixMilli(1700000000123)
+	body := []byte("line1\nline2\x00")
+	member, err := encodeDelayed(3, at, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attempts, enq, got, err := decodeDelayed(member)
+	if err != nil || attempts != 3 || !enq.Equal(at) || string(got) != string(body) {
+		t.Fatalf("decode = %d %v %q %v", attempts, enq, got, err)
+	}
+
+	other, _ := encodeDelayed(3, at, body)
+	if other == member {
+		t.Fatal("same message should get a different nonce")
+	}
+	if _, _, _, err = decodeDelayed("bad"); !errors.Is(err, ErrInvalidDelayed) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff(time.Second, 5*time.Second)
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := b(attempt); got != want {
+			t.Fatalf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+// TestQueueRedis 需要本地 Redis：REDIS_ADDR=127.0.0.1:6379 go test ./queue
+func TestQueueRedis(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	defer rdb.Close()
+
+	q := New("test-"+time.Now().Format("150405.000"), Options{
+		Client:            rdb,
+		Concurrency:       2,
+		MaxAttempts:       2,
+		VisibilityTimeout: time.Second,
+		BlockTimeout:      100 * time.Millisecond,
+		PollInterval:      50 * time.Millisecond,
+		Backoff:           func(int) time.Duration { return 50 * time.Millisecond },
+	})
+	ctx := context.Background()
+	defer rdb.Del(ctx, q.streamKey(), q.delayedKey(), q.deadKey())
+
+	for _, body := range []string{"ok", "fail"} {
+		if _, err := q.Enqueue(ctx, []byte(body), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := q.Enqueue(ctx, []byte("delayed"), 200*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	var ok, delayed atomic.Int64
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		_ = q.Run(runCtx, func(ctx context.Context, m *Message) error {
+			switch string(m.Body) {
+			case "ok":
+				ok.Add(1)
+			case "delayed":
+				delayed.Add(1)
+			default:
+				return errors.New("boom")
+			}
+			return nil
+		})
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s, err := q.Stats(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Dead == 1 && s.Ready == 0 && s.Delayed == 0 && ok.Load() == 1 && delayed.Load() == 1 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	dead, err := q.DeadLetters(ctx, 10)
+	if err != nil || len(dead) != 1 || string(dead[0].Body) != "fail" || dead[0].Attempts != 2 || dead[0].Error != "boom" {
+		t.Fatalf("dead letters = %+v, %v", dead, err)
+	}
+	s, _ := q.Stats(ctx)
+	if s.Processed != 2 || s.Failed != 2 || s.Retried != 1 || s.DeadLettered != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
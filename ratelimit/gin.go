@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/caoyuewen/components/tokenmgr"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// TokenInfoKey 鉴权中间件把 tokenmgr.TokenInfo 存入 gin.Context 时使用的 key
+const TokenInfoKey = "token_info"
+
+// KeyFunc 从请求中取限流 key，返回空串时不限流
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP 按客户端 IP 限流
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByRoute 按路由整体限流 (所有调用方共享额度)，用于回调等接口
+func KeyByRoute(c *gin.Context) string {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	return "route:" + c.Request.Method + ":" + route
+}
+
+// KeyByRouteIP 按路由 + IP 限流，如登录接口
+func KeyByRouteIP(c *gin.Context) string {
+	return KeyByRoute(c) + ":" + c.ClientIP()
+}
+
+// KeyByToken 按登录用户 (TokenInfo.Id) 限流，需在鉴权中间件之后使用
+// 从 gin.Context 的 TokenInfoKey 读取 tokenmgr.TokenInfo 或 *tokenmgr.TokenInfo，未登录时退化为按 IP
+func KeyByToken(c *gin.Context) string {
+	v, ok := c.Get(TokenInfoKey)
+	if ok {
+		switch info := v.(type) {
+		case tokenmgr.TokenInfo:
+			if info.Id != "" {
+				return "token:" + info.Id
+			}
+		case *tokenmgr.TokenInfo:
+			if info != nil && info.Id != "" {
+				return "token:" + info.Id
+			}
+		}
+	}
+	return KeyByIP(c)
+}
+
+// WithRoute 在 key 前加上路由，使同一用户在不同接口上的额度相互独立
+func WithRoute(key KeyFunc) KeyFunc {
+	return func(c *gin.Context) string {
+		k := key(c)
+		if k == "" {
+			return ""
+		}
+		return KeyByRoute(c) + ":" + k
+	}
+}
+
+// Config 中间件配置
+type Config struct {
+	Limiter    Limiter                        // 限流器，必填
+	Key        KeyFunc                        // 限流 key，默认 KeyByIP
+	Prefix     string                         // key 业务前缀，如 "login"，区分不同中间件实例
+	FailClosed bool                           // Redis 出错时拒绝请求，默认放行
+	OnLimited  func(c *gin.Context, r Result) // 被限流时的响应，默认 429 + JSON
+}
+
+// Middleware 限流中间件，输出 X-RateLimit-* 与 RateLimit-* 响应头，被拒绝时带 Retry-After
+func Middleware(cfg Config) gin.HandlerFunc {
+	if cfg.Key == nil {
+		cfg.Key = KeyByIP
+	}
+	if cfg.OnLimited == nil {
+		cfg.OnLimited = func(c *gin.Context, r Result) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"code": http.StatusTooManyRequests, "msg": "too many requests"})
+		}
+	}
+
+	return func(c *gin.Context) {
+		key := cfg.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if cfg.Prefix != "" {
+			key = cfg.Prefix + ":" + key
+		}
+
+		r, err := cfg.Limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			log.Errorf("[RATELIMIT] %s err: %v", key, err)
+			if cfg.FailClosed {
+				c.AbortWithStatus(http.StatusServiceUnavailable)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		setHeaders(c, r)
+		if !r.Allowed {
+			cfg.OnLimited(c, r)
+			return
+		}
+		c.Next()
+	}
+}
+
+// setHeaders 写入限流响应头，时间单位为秒 (向上取整)
+func setHeaders(c *gin.Context, r Result) {
+	limit := strconv.FormatInt(r.Limit, 10)
+	remaining := strconv.FormatInt(r.Remaining, 10)
+	reset := strconv.FormatInt(ceilSeconds(r.ResetAfter), 10)
+
+	h := c.Writer.Header()
+	h.Set("X-RateLimit-Limit", limit)
+	h.Set("X-RateLimit-Remaining", remaining)
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(r.ResetAfter).Unix(), 10))
+	h.Set("RateLimit-Limit", limit)
+	h.Set("RateLimit-Remaining", remaining)
+	h.Set("RateLimit-Reset", reset)
+	if !r.Allowed {
+		h.Set("Retry-After", strconv.FormatInt(max(ceilSeconds(r.RetryAfter), 1), 10))
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
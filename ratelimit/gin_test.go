@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/caoyuewen/components/tokenmgr"
+	"github.com/gin-gonic/gin"
+)
+
+// countLimiter 内存计数，仅用于测试中间件
+type countLimiter struct {
+	limit int64
+	seen  map[string]int64
+}
+
+func (l *countLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *countLimiter) AllowN(_ context.Context, key string, n int64) (Result, error) {
+	used := l.seen[key]
+	if used+n > l.limit {
+		return Result{Limit: l.limit, RetryAfter: 1500 * time.Millisecond, ResetAfter: 2 * time.Second}, nil
+	}
+	l.seen[key] = used + n
+	return Result{Allowed: true, Limit: l.limit, Remaining: l.limit - used - n, ResetAfter: 2 * time.Second}, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lim := &countLimiter{limit: 2, seen: map[string]int64{}}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if uid := c.GetHeader("X-Uid"); uid != "" {
+			c.Set(TokenInfoKey, tokenmgr.TokenInfo{Id: uid})
+		}
+	})
+	r.GET("/deposit", Middleware(Config{Limiter: lim, Key: KeyByToken, Prefix: "deposit"}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(uid string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/deposit", nil)
+		req.Header.Set("X-Uid", uid)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, want := range []string{"1", "0"} {
+		w := do("u1")
+		if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Remaining") != want {
+			t.Fatalf("request %d: code %d remaining %q", i, w.Code, w.Header().Get("X-RateLimit-Remaining"))
+		}
+	}
+
+	w := do("u1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" || w.Header().Get("RateLimit-Reset") != "2" {
+		t.Fatalf("limited: code %d headers %v", w.Code, w.Header())
+	}
+	if w := do("u2"); w.Code != http.StatusOK {
+		t.Fatalf("other uid limited: %d", w.Code)
+	}
+	if _, ok := lim.seen["deposit:token:u1"]; !ok {
+		t.Fatalf("unexpected keys %v", lim.seen)
+	}
+}
+
+func TestToResult(t *testing.T) {
+	r := toResult(10, []int64{0, 3, 250, 1000})
+	if r.Allowed || r.Remaining != 3 || r.RetryAfter != 250*time.Millisecond || r.ResetAfter != time.Second {
+		t.Fatalf("unexpected %+v", r)
+	}
+}
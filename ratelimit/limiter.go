@@ -0,0 +1,190 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/caoyuewen/components/dbs/dbredis"
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyPrefix Redis key 前缀
+const KeyPrefix = "ratelimit:"
+
+// Result 单次限流判定结果
+type Result struct {
+	Allowed    bool          // 是否放行
+	Limit      int64         // 窗口内上限 / 桶容量
+	Remaining  int64         // 剩余可用次数
+	RetryAfter time.Duration // 被拒绝时距离下次可用的时间，放行时为 0
+	ResetAfter time.Duration // 距离额度完全恢复的时间
+}
+
+// Limiter 限流器，key 为任意字符串 (如 "deposit:" + uid、"login:" + ip)
+// 每个脚本只操作一个 key，dbredis 集群模式下可用
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+	AllowN(ctx context.Context, key string, n int64) (Result, error)
+}
+
+// ==================== 滑动窗口 ====================
+
+// 滑动窗口：ZSET 记录每次请求的时间戳 (毫秒)，先清理窗口外的记录再计数
+// 时间取 Redis 服务器时间，多实例间无时钟偏差
+// 返回 {allowed, remaining, retry_after_ms, reset_after_ms}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local member = ARGV[4]
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - window)
+local count = redis.call("ZCARD", key)
+
+if count + n <= limit then
+	for i = 1, n do
+		redis.call("ZADD", key, now, member .. ":" .. i)
+	end
+	redis.call("PEXPIRE", key, window)
+	return {1, limit - count - n, 0, window}
+end
+
+local retry = window
+local oldest = redis.call("ZRANGE", key, count + n - limit - 1, count + n - limit - 1, "WITHSCORES")
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+local reset = window
+local newest = redis.call("ZRANGE", key, -1, -1, "WITHSCORES")
+if newest[2] then
+	reset = tonumber(newest[2]) + window - now
+end
+return {0, math.max(limit - count, 0), retry, reset}`)
+
+// SlidingWindow 滑动窗口限流：任意 Window 时长内最多 Limit 次
+type SlidingWindow struct {
+	Limit  int64
+	Window time.Duration
+}
+
+// NewSlidingWindow 创建滑动窗口限流器，如 NewSlidingWindow(5, time.Minute) 每分钟 5 次
+func NewSlidingWindow(limit int64, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{Limit: limit, Window: window}
+}
+
+// Allow 请求 1 次
+func (s *SlidingWindow) Allow(ctx context.Context, key string) (Result, error) {
+	return s.AllowN(ctx, key, 1)
+}
+
+// AllowN 请求 n 次，额度不足时不扣减
+func (s *SlidingWindow) AllowN(ctx context.Context, key string, n int64) (Result, error) {
+	member, err := newMember()
+	if err != nil {
+		return Result{}, err
+	}
+	vals, err := slidingWindowScript.Run(ctx, dbredis.Client(), []string{KeyPrefix + "sw:" + key},
+		s.Limit, s.Window.Milliseconds(), n, member).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: sliding window %s: %w", key, err)
+	}
+	return toResult(s.Limit, vals), nil
+}
+
+// ==================== 令牌桶 ====================
+
+// 令牌桶：HASH 保存剩余令牌数与上次补充时间 (毫秒)，按经过时间补充令牌
+// 返回 {allowed, remaining, retry_after_ms, reset_after_ms}
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", key, "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(now - ts, 0) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+else
+	retry = math.ceil((n - tokens) * 1000 / rate)
+end
+
+local reset = math.ceil((burst - tokens) * 1000 / rate)
+redis.call("HSET", key, "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", key, math.max(reset, 1000))
+return {allowed, math.floor(tokens), retry, reset}`)
+
+// TokenBucket 令牌桶限流：每秒补充 Rate 个令牌，最多积累 Burst 个，允许突发
+type TokenBucket struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst int64   // 桶容量
+}
+
+// NewTokenBucket 创建令牌桶限流器，如 NewTokenBucket(10, 20) 平均每秒 10 次，最多突发 20 次
+func NewTokenBucket(rate float64, burst int64) *TokenBucket {
+	return &TokenBucket{Rate: rate, Burst: burst}
+}
+
+// Allow 请求 1 个令牌
+func (b *TokenBucket) Allow(ctx context.Context, key string) (Result, error) {
+	return b.AllowN(ctx, key, 1)
+}
+
+// AllowN 请求 n 个令牌，不足时不扣减
+func (b *TokenBucket) AllowN(ctx context.Context, key string, n int64) (Result, error) {
+	if b.Rate <= 0 {
+		return Result{}, fmt.Errorf("ratelimit: token bucket rate must be positive")
+	}
+	vals, err := tokenBucketScript.Run(ctx, dbredis.Client(), []string{KeyPrefix + "tb:" + key},
+		b.Rate, b.Burst, n).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: token bucket %s: %w", key, err)
+	}
+	return toResult(b.Burst, vals), nil
+}
+
+// ==================== 辅助函数 ====================
+
+// toResult 解析脚本返回的 {allowed, remaining, retry_after_ms, reset_after_ms}
+func toResult(limit int64, vals []int64) Result {
+	if len(vals) < 4 {
+		return Result{Limit: limit}
+	}
+	return Result{
+		Allowed:    vals[0] == 1,
+		Limit:      limit,
+		Remaining:  max(vals[1], 0),
+		RetryAfter: time.Duration(max(vals[2], 0)) * time.Millisecond,
+		ResetAfter: time.Duration(max(vals[3], 0)) * time.Millisecond,
+	}
+}
+
+// newMember 滑动窗口记录的唯一成员前缀，避免同一毫秒内的请求互相覆盖
+func newMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 触发规则，返回 t 之后的下一次触发时间，零值表示不再触发
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// ==================== 固定间隔 ====================
+
+// intervalSchedule 固定间隔触发
+type intervalSchedule struct {
+	every time.Duration
+}
+
+// Every 固定间隔触发，间隔小于 1 秒时按 1 秒处理
+func Every(d time.Duration) Schedule {
+	if d < time.Second {
+		d = time.Second
+	}
+	return intervalSchedule{every: d}
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.every)
+}
+
+// ==================== Cron 表达式 ====================
+
+// cronSchedule 标准 5 段 cron：分 时 日 月 周，每段为允许值的位图
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	loc                           *time.Location
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// 通配符标记，用于区分 "*" 与显式列出全部值 (日与周同时限定时按"或"匹配)
+const starBit = 1 << 63
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron 解析 cron 表达式，按 loc 时区计算 (nil 为本地时区)
+// 支持 5 段格式 "分 时 日 月 周"，每段可用 * , - / 及月份/星期英文缩写；
+// 另支持 @yearly @monthly @weekly @daily @hourly 与 "@every 5m"
+func ParseCron(spec string, loc *time.Location) (Schedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	spec = strings.TrimSpace(spec)
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		dur, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid @every %q: %w", spec, err)
+		}
+		return Every(dur), nil
+	}
+	if v, ok := descriptors[spec]; ok {
+		spec = v
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("scheduler: cron %q must have 5 fields", spec)
+	}
+
+	s := &cronSchedule{loc: loc}
+	fields := []struct {
+		bits *uint64
+		def  cronField
+	}{
+		{&s.minute, minuteField},
+		{&s.hour, hourField},
+		{&s.dom, domField},
+		{&s.month, monthField},
+		{&s.dow, dowField},
+	}
+	for i, f := range fields {
+		bits, err := parseField(parts[i], f.def)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: cron %q: %w", spec, err)
+		}
+		*f.bits = bits
+	}
+	// 周日可写作 0 或 7
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// MustParseCron 解析失败时 panic，用于包级变量
+func MustParseCron(spec string) Schedule {
+	s, err := ParseCron(spec, nil)
+	if err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
+// parseField 解析单段，如 "*/5"、"1-10/2"、"mon-fri"、"0,30"
+func parseField(expr string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", item)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			if !hasStep {
+				bits |= starBit
+			}
+		default:
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = f.value(from); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(to); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q", item)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %q out of range [%d,%d]", s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next 逐级推进 月→日→时→分 找到下一个匹配时间，5 年内无匹配 (如 2 月 30 日) 返回零值
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	origLoc := t.Location()
+	t = t.In(s.loc).Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+	for !s.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Truncate(time.Minute).Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+	return t.In(origLoc)
+}
+
+// dayMatches 日与周均被限定时满足其一即可，否则两者同时满足
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.dom&starBit != 0 || s.dow&starBit != 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/caoyuewen/components/dbs/dbredis/redislock"
+	log "github.com/sirupsen/logrus"
+)
+
+// Elector 选主，集群级任务只在 leader 实例上运行
+type Elector interface {
+	// Leader 当前为 leader 时返回持有期间有效的上下文，失去 leader 时该上下文被取消；否则返回 nil
+	Leader() context.Context
+	// Run 参与选举直到 ctx 结束，结束时放弃 leader
+	Run(ctx context.Context)
+}
+
+// RedisElector 基于 redislock 的选主：抢到锁即为 leader，锁自动续期，续期失败即失去 leader
+type RedisElector struct {
+	Name          string        // 选举锁名，同一组实例需一致
+	RetryInterval time.Duration // 非 leader 时重新竞选的间隔，默认 5s
+
+	locker *redislock.Locker
+	mu     sync.RWMutex
+	ctx    context.Context
+}
+
+// NewRedisElector 创建基于 dbredis 全局客户端的选主，ttl 为 leader 租期，默认 30s
+func NewRedisElector(name string, ttl time.Duration) *RedisElector {
+	return &RedisElector{
+		Name:   name,
+		locker: redislock.New(redislock.Options{TTL: ttl, KeyPrefix: "leader:"}),
+	}
+}
+
+// Leader 当前为 leader 时返回 leader 上下文
+func (e *RedisElector) Leader() context.Context {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.ctx == nil || e.ctx.Err() != nil {
+		return nil
+	}
+	return e.ctx
+}
+
+// IsLeader 当前是否为 leader
+func (e *RedisElector) IsLeader() bool {
+	return e.Leader() != nil
+}
+
+// Run 循环竞选，直到 ctx 结束
+func (e *RedisElector) Run(ctx context.Context) {
+	retry := e.RetryInterval
+	if retry <= 0 {
+		retry = 5 * time.Second
+	}
+
+	for {
+		lock, err := e.locker.Obtain(ctx, e.Name)
+		if err == nil {
+			log.Infof("[SCHEDULER] Became leader of %s", e.Name)
+			e.setCtx(lock.Context())
+
+			<-lock.Context().Done()
+			e.setCtx(nil)
+			if ctx.Err() == nil {
+				log.Warnf("[SCHEDULER] Lost leadership of %s", e.Name)
+			}
+
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			_ = lock.Release(releaseCtx)
+			cancel()
+		} else if !errors.Is(err, redislock.ErrNotObtained) && ctx.Err() == nil {
+			log.Errorf("[SCHEDULER] Leader election %s err: %v", e.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+func (e *RedisElector) setCtx(ctx context.Context) {
+	e.mu.Lock()
+	e.ctx = ctx
+	e.mu.Unlock()
+}
@@ -0,0 +1,322 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caoyuewen/components/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrDuplicateJob = errors.New("scheduler: duplicate job name")
+	ErrStarted      = errors.New("scheduler: already started")
+	ErrNoElector    = errors.New("scheduler: singleton job requires an Elector")
+)
+
+// Job 定时任务
+type Job struct {
+	Name       string                          // 任务名，唯一
+	Schedule   Schedule                        // 触发规则，ParseCron 或 Every
+	Fn         func(ctx context.Context) error // 任务函数，ctx 在 Stop、超时或失去 leader 时取消
+	Singleton  bool                            // 集群级任务，只在 leader 实例上运行
+	Jitter     time.Duration                   // 每次触发前随机延迟 [0, Jitter)，避免多实例同时打到下游
+	Timeout    time.Duration                   // 单次执行超时，默认不限制
+	RunOnStart bool                            // Start 时立即执行一次
+}
+
+// JobStatus 任务运行状态
+type JobStatus struct {
+	Name         string        `json:"name"`
+	Singleton    bool          `json:"singleton"`
+	Running      bool          `json:"running"`
+	NextRun      time.Time     `json:"next_run"`
+	LastRun      time.Time     `json:"last_run"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error"`
+	Runs         int64         `json:"runs"`
+	Failures     int64         `json:"failures"`
+	Skipped      int64         `json:"skipped"` // 上次未结束或非 leader 跳过的次数
+}
+
+// Options 调度器配置
+type Options struct {
+	Elector Elector        // 选主实现，有 Singleton 任务时必填，一般为 NewRedisElector
+	Logger  *log.Entry     // 日志，默认 logrus 标准输出
+	Loc     *time.Location // AddCron 解析时区，默认本地时区
+}
+
+// Scheduler 任务调度器
+// 任务不会重叠执行：到点时上一次仍在运行则跳过本次；任务 panic 会被恢复并记为失败
+type Scheduler struct {
+	opts Options
+
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type entry struct {
+	job     Job
+	running atomic.Bool
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// New 创建调度器
+func New(opts Options) *Scheduler {
+	if opts.Logger == nil {
+		opts.Logger = log.NewEntry(log.StandardLogger())
+	}
+	if opts.Loc == nil {
+		opts.Loc = time.Local
+	}
+	return &Scheduler{opts: opts, jobs: make(map[string]*entry)}
+}
+
+// ==================== 注册任务 ====================
+
+// Add 注册任务，需在 Start 前调用
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Fn == nil {
+		return fmt.Errorf("scheduler: job %q requires Name, Schedule and Fn", job.Name)
+	}
+	if job.Singleton && s.opts.Elector == nil {
+		return fmt.Errorf("%w: %s", ErrNoElector, job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrStarted
+	}
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+	}
+	s.jobs[job.Name] = &entry{job: job, status: JobStatus{Name: job.Name, Singleton: job.Singleton}}
+	return nil
+}
+
+// AddCron 按 cron 表达式注册本实例任务
+func (s *Scheduler) AddCron(name, spec string, fn func(ctx context.Context) error) error {
+	sch, err := ParseCron(spec, s.opts.Loc)
+	if err != nil {
+		return err
+	}
+	return s.Add(Job{Name: name, Schedule: sch, Fn: fn})
+}
+
+// AddInterval 按固定间隔注册本实例任务
+func (s *Scheduler) AddInterval(name string, every time.Duration, fn func(ctx context.Context) error) error {
+	return s.Add(Job{Name: name, Schedule: Every(every), Fn: fn})
+}
+
+// ==================== 启动与停止 ====================
+
+// Start 启动调度，有 Singleton 任务时同时参与选主
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrStarted
+	}
+	s.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	needLeader := false
+	for _, e := range s.jobs {
+		needLeader = needLeader || e.job.Singleton
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+	if needLeader {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.opts.Elector.Run(ctx)
+		}()
+	}
+	s.opts.Logger.Infof("[SCHEDULER] Started with %d jobs", len(s.jobs))
+	return nil
+}
+
+// Stop 停止调度并等待运行中的任务结束，ctx 到期时返回 ctx.Err()
+// 任务的 ctx 会立即取消，任务应及时响应
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started || s.cancel == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.cancel = nil
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.opts.Logger.Info("[SCHEDULER] Stopped")
+		return nil
+	case <-ctx.Done():
+		s.opts.Logger.Warn("[SCHEDULER] Stop timeout, some jobs are still running")
+		return ctx.Err()
+	}
+}
+
+// ==================== 状态 ====================
+
+// Status 所有任务状态，按名称排序
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	list := make([]JobStatus, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		list = append(list, e.snapshot())
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// JobStatus 单个任务状态
+func (s *Scheduler) JobStatus(name string) (JobStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.jobs[name]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return e.snapshot(), true
+}
+
+func (e *entry) snapshot() JobStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st := e.status
+	st.Running = e.running.Load()
+	return st
+}
+
+// ==================== 内部实现 ====================
+
+// loop 单个任务的调度循环
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer s.wg.Done()
+
+	if e.job.RunOnStart {
+		s.trigger(ctx, e)
+	}
+
+	for {
+		next := e.job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			s.opts.Logger.Warnf("[SCHEDULER] Job %s has no next run, stopped", e.job.Name)
+			return
+		}
+		if e.job.Jitter > 0 {
+			next = next.Add(rand.N(e.job.Jitter))
+		}
+		e.mu.Lock()
+		e.status.NextRun = next
+		e.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.trigger(ctx, e)
+	}
+}
+
+// trigger 检查 leader 与重叠后异步执行一次
+func (s *Scheduler) trigger(ctx context.Context, e *entry) {
+	var leader context.Context
+	if e.job.Singleton {
+		if leader = s.opts.Elector.Leader(); leader == nil {
+			e.skip()
+			return
+		}
+	}
+	if !e.running.CompareAndSwap(false, true) {
+		s.opts.Logger.Warnf("[SCHEDULER] Job %s is still running, skipped", e.job.Name)
+		e.skip()
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer e.running.Store(false)
+
+		runCtx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		if leader != nil {
+			// 失去 leader 时取消正在运行的集群级任务
+			stop := context.AfterFunc(leader, func() { cancel(context.Cause(leader)) })
+			defer stop()
+		}
+		s.run(runCtx, e)
+	}()
+}
+
+// run 执行任务并记录状态，panic 转为错误
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	var cancel context.CancelFunc
+	if e.job.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	util.TryCatch(func() {
+		err = e.job.Fn(ctx)
+	}, func(r interface{}) {
+		err = fmt.Errorf("panic: %v", r)
+	})
+	elapsed := time.Since(start)
+
+	e.mu.Lock()
+	e.status.LastRun = start
+	e.status.LastDuration = elapsed
+	e.status.Runs++
+	e.status.LastError = ""
+	if err != nil {
+		e.status.Failures++
+		e.status.LastError = err.Error()
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		s.opts.Logger.Errorf("[SCHEDULER] Job %s failed after %s: %v", e.job.Name, elapsed, err)
+	} else {
+		s.opts.Logger.Debugf("[SCHEDULER] Job %s done in %s", e.job.Name, elapsed)
+	}
+}
+
+func (e *entry) skip() {
+	e.mu.Lock()
+	e.status.Skipped++
+	e.mu.Unlock()
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2026, 1, 30, 10, 17, 30, 0, time.UTC) // 周五
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/5 * * * *", time.Date(2026, 1, 30, 10, 20, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 1, 30, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, 1, 31, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 31 2,3 *", time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 7", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}, // 1 日或周日
+		{"@monthly", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.spec, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", c.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(c.want) {
+			t.Errorf("%s: next = %v, want %v", c.spec, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every x"} {
+		if _, err := ParseCron(bad, time.UTC); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestSchedulerOverlapAndPanic(t *testing.T) {
+	s := New(Options{})
+	release := make(chan struct{})
+	if err := s.Add(Job{Name: "slow", Schedule: Every(time.Second), RunOnStart: true, Fn: func(ctx context.Context) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Job{Name: "panic", Schedule: Every(time.Hour), RunOnStart: true, Fn: func(ctx context.Context) error {
+		panic("boom")
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Job{Name: "leader", Schedule: Every(time.Hour), Singleton: true, Fn: func(ctx context.Context) error { return nil }}); err == nil {
+		t.Fatal("singleton job without elector should fail")
+	}
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	slow, _ := s.JobStatus("slow")
+	if slow.Runs != 1 || slow.Skipped < 1 {
+		t.Errorf("slow: %+v", slow)
+	}
+	p, _ := s.JobStatus("panic")
+	if p.Runs != 1 || p.Failures != 1 || p.LastError == "" {
+		t.Errorf("panic: %+v", p)
+	}
+}
@@ -2,7 +2,10 @@ package tictok
 
 import (
 	"context"
+	"errors"
 	"github.com/caoyuewen/components/dbs/dbredis"
+	"github.com/caoyuewen/components/dbs/dbredis/redislock"
+	"github.com/caoyuewen/components/scheduler"
 	"github.com/caoyuewen/components/third/tictok/tictokapi"
 	"github.com/caoyuewen/components/util"
 	log "github.com/sirupsen/logrus"
@@ -12,6 +15,7 @@ import (
 const (
 	CheckAccessTokenInterval = 1                   // 单位分钟 每分钟检测一次 如果过期时间在5分钟以内就要更新token
 	RedisAccessTokenKey      = "TictokAccessToken" // Redis 中存储 access token 的 key
+	AccessTokenLockName      = "TictokAccessToken" // 多实例部署时同一时刻只有一个实例刷新 token
 )
 
 var AccessTokenMgr accessToken
@@ -28,6 +32,23 @@ func InitAccessToken(appid, secret string) {
 	go flushAccessToken(appid, secret)
 }
 
+// InitAccessTokenWithScheduler 初始化 token 并把定时刷新注册为集群级任务，由 leader 实例执行
+// s 需配置 Elector，且在 Start 前调用
+func InitAccessTokenWithScheduler(appid, secret string, s *scheduler.Scheduler) error {
+	AccessTokenMgr.appId = appid
+	AccessTokenMgr.secret = secret
+	return s.Add(scheduler.Job{
+		Name:       AccessTokenLockName,
+		Schedule:   scheduler.Every(CheckAccessTokenInterval * time.Minute),
+		Singleton:  true,
+		RunOnStart: true,
+		Fn: func(ctx context.Context) error {
+			checkAccessToken(ctx, appid, secret)
+			return nil
+		},
+	})
+}
+
 func (a *accessToken) GetAccessToken() (string, error) {
 	// 从 Redis 中获取 access token
 	token, err := dbredis.Client().Get(context.TODO(), RedisAccessTokenKey).Result()
@@ -64,21 +85,32 @@ func flushAccessToken(appid, secret string) {
 	for {
 		select {
 		case <-ticker.C:
-			//log.Info("Access token checking...")
-			ttl, err := dbredis.Client().TTL(context.TODO(), RedisAccessTokenKey).Result()
-			if err != nil {
-				log.Error("check access token err:", err)
-				continue
-			}
-			// 检查剩余过期时间是否在 5 分钟以内
-			if ttl > 0 && ttl <= 5*time.Minute {
-				log.Info("Access token will expire soon, refreshing...")
-				getAccessToken(appid, secret)
-			} else if ttl < 0 {
-				// 当 ttl < 0 时表示 key 不存在，需要立即获取新的 token
-				log.Info("Access token does not exist, fetching a new one...")
-				getAccessToken(appid, secret)
+			err := redislock.RunExclusive(context.Background(), AccessTokenLockName, func(ctx context.Context) error {
+				checkAccessToken(ctx, appid, secret)
+				return nil
+			})
+			if err != nil && !errors.Is(err, redislock.ErrNotObtained) {
+				log.Error("check access token lock err:", err)
 			}
 		}
 	}
 }
+
+// checkAccessToken 剩余过期时间在 5 分钟以内或不存在时刷新
+func checkAccessToken(ctx context.Context, appid, secret string) {
+	//log.Info("Access token checking...")
+	ttl, err := dbredis.Client().TTL(ctx, RedisAccessTokenKey).Result()
+	if err != nil {
+		log.Error("check access token err:", err)
+		return
+	}
+	// 检查剩余过期时间是否在 5 分钟以内
+	if ttl > 0 && ttl <= 5*time.Minute {
+		log.Info("Access token will expire soon, refreshing...")
+		getAccessToken(appid, secret)
+	} else if ttl < 0 {
+		// 当 ttl < 0 时表示 key 不存在，需要立即获取新的 token
+		log.Info("Access token does not exist, fetching a new one...")
+		getAccessToken(appid, secret)
+	}
+}
@@ -1,54 +1,41 @@
 package tictokapi
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/caoyuewen/components/util"
-	log "github.com/sirupsen/logrus"
-	"io"
 	"net/http"
+	"time"
+
+	"github.com/caoyuewen/components/util/httpclient"
 )
 
 const (
 	ApiCodeSuccess = 0
 )
 
+func init() {
+	httpclient.Configure("developer.toutiao.com", httpclient.HostConfig{Timeout: 10 * time.Second, LogBody: true})
+	// 数据开放接口单个 app_id 调用上限为 10 次/秒
+	httpclient.Configure("webcast.bytedance.com", httpclient.HostConfig{Timeout: 10 * time.Second, RateLimit: 10, LogBody: true})
+}
+
 func Post(url string, body interface{}, header map[string]string) ([]byte, error) {
 	// 构造请求体
-	log.Debug("-------------------------- tictok post --------------------------")
-	log.Debug("Post url:", url)
-	log.Debug("Post header:", util.ToJson(header))
-	log.Debug("Post body:", util.ToJson(body))
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %v", err)
 	}
 
-	// 创建HTTP请求
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// 设置请求头
-	for k, v := range header {
-		req.Header.Set(k, v)
-	}
-
-	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应内容
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// 发送请求 (日志由 httpclient 输出并脱敏 access-token 等字段)
+	resp, err := httpclient.Do(context.Background(), &httpclient.Request{
+		Method: http.MethodPost,
+		URL:    url,
+		Header: header,
+		Body:   jsonBody,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	log.Debugf("Post resp:%s", string(bodyBytes))
-	return bodyBytes, nil
+	return resp.Body, nil
 }
@@ -2,12 +2,13 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"io"
-	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+
+	"github.com/caoyuewen/components/util/httpclient"
 )
 
 func Post(uri string, params map[string]string) ([]byte, error) {
@@ -17,94 +18,57 @@ func Post(uri string, params map[string]string) ([]byte, error) {
 		values.Add(k, v)
 	}
 
-	resp, err := http.PostForm(uri, values)
+	resp, err := httpclient.Do(context.Background(), &httpclient.Request{
+		Method: http.MethodPost,
+		URL:    uri,
+		Header: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Body:   []byte(values.Encode()),
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	return body, err
+	return resp.Body, nil
 }
 
 func PostMultipartFormData(uri string, params map[string]string) ([]byte, error) {
 	formBuf := new(bytes.Buffer)
 	writer := multipart.NewWriter(formBuf)
-	defer writer.Close()
 	// 写入请求参数
 	for k, v := range params {
 		writer.WriteField(k, v)
 	}
-	// 创建请求
-	req, err := http.NewRequest("POST", uri, formBuf)
-	if err != nil {
-		return nil, err
-	}
-	// 设置 Content-Type
-	contentType := writer.FormDataContentType()
-	req.Header.Set("Content-Type", contentType)
+	writer.Close()
 
 	// 同步执行请求
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpclient.Do(context.Background(), &httpclient.Request{
+		Method: http.MethodPost,
+		URL:    uri,
+		Header: map[string]string{"Content-Type": writer.FormDataContentType()},
+		Body:   formBuf.Bytes(),
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	defer resp.Body.Close()
-	// 读取应答报文
-	body, err := io.ReadAll(resp.Body)
-	return body, err
+	return resp.Body, nil
 }
 
 func Get(url string) ([]byte, error) {
-	client := &http.Client{}
-
-	//提交请求
-	request, err := http.NewRequest("GET", url, nil)
-	//异常捕捉
-	if err != nil {
-		panic(err)
-	}
-
-	//处理返回结果
-	response, err := client.Do(request)
+	resp, err := httpclient.Get(context.Background(), url, nil)
 	if err != nil {
 		return nil, err
 	}
-	//关闭流
-	defer response.Body.Close()
-	//检出结果集
-	resp, err := io.ReadAll(response.Body)
-
-	return resp, err
+	return resp.Body, nil
 }
 
 func PostTpl[T any](url string, req any) (*T, error) {
 
-	data, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-
-	reqInst, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
-	}
-
-	reqInst.Header.Set("Content-Type", "application/json")
-	respInst, err := http.DefaultClient.Do(reqInst)
-	if err != nil {
-		return nil, err
-	}
-
-	defer respInst.Body.Close()
-	body, err := ioutil.ReadAll(respInst.Body)
+	resp, err := httpclient.PostJSON(context.Background(), url, req, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	res := new(T)
-	if err = json.Unmarshal(body, res); err != nil {
+	if err = json.Unmarshal(resp.Body, res); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,311 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrCircuitOpen = errors.New("httpclient: circuit breaker open")
+)
+
+// Request 请求参数
+type Request struct {
+	Method     string
+	URL        string
+	Header     map[string]string
+	Body       []byte
+	Idempotent bool // POST 等方法的请求若可安全重放 (如查询类接口) 设为 true 以启用重试
+}
+
+// Response 响应，非 2xx 状态码不作为 error 返回，由调用方判断
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// OK 是否 2xx
+func (r *Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// host 单个 host 的运行时状态
+type host struct {
+	cfg      HostConfig
+	client   *http.Client
+	limiter  *limiter
+	breaker  *breaker
+	redactor redactor
+}
+
+func newHost(cfg HostConfig) *host {
+	cfg.setDefault()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+
+	return &host{
+		cfg:      cfg,
+		client:   &http.Client{Transport: transport},
+		limiter:  newLimiter(cfg.RateLimit, cfg.Burst),
+		breaker:  newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		redactor: newRedactor(cfg.RedactFields),
+	}
+}
+
+// Client 按 host 区分配置的 HTTP 客户端
+type Client struct {
+	mu      sync.RWMutex
+	base    HostConfig
+	configs map[string]HostConfig
+	hosts   map[string]*host
+}
+
+// New 创建客户端，base 为未单独配置的 host 使用的默认配置
+func New(base HostConfig) *Client {
+	return &Client{
+		base:    base,
+		configs: map[string]HostConfig{},
+		hosts:   map[string]*host{},
+	}
+}
+
+// Configure 设置 host 的配置 (host 不含协议，如 api.trongrid.io)，会重置该 host 的限流与熔断状态
+func (c *Client) Configure(hostname string, cfg HostConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.configs[hostname] = cfg
+	delete(c.hosts, hostname)
+}
+
+func (c *Client) host(hostname string) *host {
+	c.mu.RLock()
+	h, ok := c.hosts[hostname]
+	c.mu.RUnlock()
+	if ok {
+		return h
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if h, ok = c.hosts[hostname]; ok {
+		return h
+	}
+	cfg, ok := c.configs[hostname]
+	if !ok {
+		cfg = c.base
+	}
+	h = newHost(cfg)
+	c.hosts[hostname] = h
+	return h
+}
+
+// Do 发送请求，按 host 配置限流、熔断、重试
+func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("httpclient: parse url: %w", err)
+	}
+	h := c.host(u.Host)
+
+	maxRetries := 0
+	if req.Idempotent || h.cfg.RetryPOST || isIdempotent(req.Method) {
+		maxRetries = h.cfg.MaxRetries
+	}
+
+	log.Debugf("[HTTP] %s %s header:%v body:%s",
+		req.Method, h.redactor.url(u), h.redactor.header(toHeader(req.Header)), h.logBody(req.Body))
+
+	var (
+		resp *Response
+		wait time.Duration
+	)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err = sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+		// 先限流再熔断判断：allow 可能占用半开探测名额，之后必须以 success/failure 结束
+		if err = h.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		if !h.breaker.allow() {
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, u.Host)
+		}
+
+		start := time.Now()
+		resp, err = h.send(ctx, req)
+		cost := time.Since(start)
+
+		if err != nil {
+			h.breaker.failure()
+			if ctx.Err() != nil || attempt >= maxRetries {
+				log.Warnf("[HTTP] %s %s cost:%s err:%v", req.Method, h.redactor.url(u), cost, err)
+				return nil, err
+			}
+			wait = h.backoff(attempt)
+			log.Warnf("[HTTP] %s %s retry %d/%d in %s, err:%v", req.Method, h.redactor.url(u), attempt+1, maxRetries, wait, err)
+			continue
+		}
+
+		log.Debugf("[HTTP] %s %s status:%d cost:%s body:%s",
+			req.Method, h.redactor.url(u), resp.StatusCode, cost, h.logBody(resp.Body))
+
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			h.breaker.failure()
+		} else {
+			h.breaker.success()
+		}
+		if !retryableStatus(resp.StatusCode) || attempt >= maxRetries {
+			return resp, nil
+		}
+		wait = max(h.backoff(attempt), retryAfter(resp.Header))
+		log.Warnf("[HTTP] %s %s retry %d/%d in %s, status:%d", req.Method, h.redactor.url(u), attempt+1, maxRetries, wait, resp.StatusCode)
+	}
+}
+
+// send 单次请求
+func (h *host) send(ctx context.Context, req *Request) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("httpclient: create request: %w", err)
+	}
+	for k, v := range req.Header {
+		httpReq.Header.Set(k, v)
+	}
+
+	httpResp, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, h.redactor.err(err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("httpclient: read response: %w", err)
+	}
+	return &Response{StatusCode: httpResp.StatusCode, Header: httpResp.Header, Body: respBody}, nil
+}
+
+// backoff 指数退避 + 抖动，结果落在 [d/2, d)
+func (h *host) backoff(attempt int) time.Duration {
+	d := h.cfg.RetryBackoff << attempt
+	if d <= 0 || d > h.cfg.MaxBackoff {
+		d = h.cfg.MaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func (h *host) logBody(b []byte) string {
+	if !h.cfg.LogBody {
+		return fmt.Sprintf("(%d bytes)", len(b))
+	}
+	return h.redactor.body(b)
+}
+
+// isIdempotent HTTP 语义上的幂等方法
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryableStatus 可重试的状态码
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter 解析 Retry-After 秒数
+func retryAfter(h http.Header) time.Duration {
+	sec, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || sec <= 0 {
+		return 0
+	}
+	return time.Duration(sec) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func toHeader(m map[string]string) http.Header {
+	h := make(http.Header, len(m))
+	for k, v := range m {
+		h.Set(k, v)
+	}
+	return h
+}
+
+// ==================== 默认客户端 ====================
+
+var std = New(HostConfig{})
+
+// Default 默认客户端
+func Default() *Client {
+	return std
+}
+
+// Configure 设置默认客户端的 host 配置
+func Configure(hostname string, cfg HostConfig) {
+	std.Configure(hostname, cfg)
+}
+
+// Do 使用默认客户端发送请求
+func Do(ctx context.Context, req *Request) (*Response, error) {
+	return std.Do(ctx, req)
+}
+
+// Get 发送 GET 请求
+func Get(ctx context.Context, url string, header map[string]string) (*Response, error) {
+	return std.Do(ctx, &Request{Method: http.MethodGet, URL: url, Header: header})
+}
+
+// PostJSON 发送 JSON 请求体的 POST 请求
+func PostJSON(ctx context.Context, url string, body any, header map[string]string) (*Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("httpclient: marshal body: %w", err)
+	}
+	h := map[string]string{"Content-Type": "application/json"}
+	for k, v := range header {
+		h[k] = v
+	}
+	return std.Do(ctx, &Request{Method: http.MethodPost, URL: url, Header: h, Body: data})
+}
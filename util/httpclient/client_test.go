@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, status ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(status) {
+			w.WriteHeader(status[n-1])
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testClient(srv *httptest.Server, cfg HostConfig) *Client {
+	u, _ := url.Parse(srv.URL)
+	c := New(HostConfig{})
+	cfg.RetryBackoff = time.Millisecond
+	c.Configure(u.Host, cfg)
+	return c
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	srv, calls := newTestServer(t, 503, 502)
+	c := testClient(srv, HostConfig{MaxRetries: 2})
+
+	resp, err := c.Do(context.Background(), &Request{Method: http.MethodGet, URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || string(resp.Body) != "ok" || calls.Load() != 3 {
+		t.Fatalf("status=%d body=%s calls=%d", resp.StatusCode, resp.Body, calls.Load())
+	}
+}
+
+func TestNoRetryPost(t *testing.T) {
+	srv, calls := newTestServer(t, 503)
+	c := testClient(srv, HostConfig{MaxRetries: 2})
+
+	resp, err := c.Do(context.Background(), &Request{Method: http.MethodPost, URL: srv.URL, Body: []byte("{}")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 503 || calls.Load() != 1 {
+		t.Fatalf("status=%d calls=%d", resp.StatusCode, calls.Load())
+	}
+
+	resp, err = c.Do(context.Background(), &Request{Method: http.MethodPost, URL: srv.URL, Idempotent: true})
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("idempotent post: %v", err)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	srv, calls := newTestServer(t, 500, 500, 500)
+	c := testClient(srv, HostConfig{MaxRetries: -1, BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		c.Do(context.Background(), &Request{Method: http.MethodGet, URL: srv.URL})
+	}
+	_, err := c.Do(context.Background(), &Request{Method: http.MethodGet, URL: srv.URL})
+	if !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Fatalf("want circuit open, err=%v calls=%d", err, calls.Load())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	// 冷却后放行探测请求，仍失败则继续熔断
+	if _, err = c.Do(context.Background(), &Request{Method: http.MethodGet, URL: srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Do(context.Background(), &Request{Method: http.MethodGet, URL: srv.URL}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("want circuit open after failed probe, err=%v", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv, _ := newTestServer(t)
+	c := testClient(srv, HostConfig{RateLimit: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.Do(context.Background(), &Request{Method: http.MethodGet, URL: srv.URL}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cost := time.Since(start); cost < 90*time.Millisecond {
+		t.Fatalf("rate limit not applied, cost=%s", cost)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	r := newRedactor([]string{"uid"})
+
+	body := r.body([]byte(`{"appid":"a","secret":"s","data":{"access_token":"t","uid":"1"}}`))
+	if strings.Contains(body, `"s"`) || strings.Contains(body, `"t"`) || strings.Contains(body, `"1"`) || !strings.Contains(body, `"a"`) {
+		t.Fatalf("body not redacted: %s", body)
+	}
+
+	u, _ := url.Parse("https://example.com/x?api_key=k&id=2")
+	if got := r.url(u); strings.Contains(got, "k&") || !strings.Contains(got, "id=2") {
+		t.Fatalf("url not redacted: %s", got)
+	}
+
+	u, _ = url.Parse("https://a.quiknode.pro/38651257af9f5ef32ca03dce1a09994b1003d1fb/jsonrpc")
+	if got := r.url(u); got != "https://a.quiknode.pro/***/jsonrpc" {
+		t.Fatalf("path not redacted: %s", got)
+	}
+
+	h := r.header(http.Header{"Tron-Pro-Api-Key": {"k"}, "Accept": {"json"}})
+	if h["Tron-Pro-Api-Key"] != redacted || h["Accept"] != "json" {
+		t.Fatalf("header not redacted: %v", h)
+	}
+}
+
+func TestRedactTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	const token = "38651257af9f5ef32ca03dce1a09994b1003d1fb"
+	c := testClient(srv, HostConfig{})
+	_, err := c.Do(context.Background(), &Request{Method: http.MethodGet, URL: srv.URL + "/" + token + "/jsonrpc?api_key=k"})
+	if err == nil {
+		t.Fatal("expected transport error")
+	}
+	if strings.Contains(err.Error(), token) || strings.Contains(err.Error(), "api_key=k") {
+		t.Fatalf("error leaks secret: %v", err)
+	}
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		t.Fatalf("expected *url.Error, got %T", err)
+	}
+}
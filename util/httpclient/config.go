@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"time"
+)
+
+// HostConfig 单个 host 的请求配置，未设置的字段使用默认值
+type HostConfig struct {
+	Timeout time.Duration // 单次请求超时，默认 30s
+
+	MaxRetries   int           // 最大重试次数 (不含首次请求)，默认 2；< 0 表示不重试
+	RetryBackoff time.Duration // 首次重试等待的基数，指数增长并加随机抖动，默认 200ms
+	MaxBackoff   time.Duration // 单次重试最长等待，默认 5s
+	RetryPOST    bool          // 非幂等请求 (POST/PATCH) 是否重试，默认不重试
+
+	RateLimit float64 // 每秒请求数上限，<= 0 表示不限流
+	Burst     int     // 令牌桶容量，默认等于 RateLimit 向上取整
+
+	BreakerThreshold int           // 连续失败多少次熔断，<= 0 表示不熔断
+	BreakerCooldown  time.Duration // 熔断持续时间，之后放行一个探测请求，默认 30s
+
+	MaxIdleConnsPerHost int // 每个 host 最大空闲连接，默认 20
+
+	LogBody      bool     // 是否在 Debug 日志中输出请求/响应体
+	RedactFields []string // 额外需要脱敏的 header / query / JSON 字段名
+}
+
+const (
+	defaultTimeout         = 30 * time.Second
+	defaultMaxRetries      = 2
+	defaultRetryBackoff    = 200 * time.Millisecond
+	defaultMaxBackoff      = 5 * time.Second
+	defaultBreakerCooldown = 30 * time.Second
+	defaultMaxIdlePerHost  = 20
+)
+
+func (c *HostConfig) setDefault() {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = defaultRetryBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+	if c.RateLimit > 0 && c.Burst <= 0 {
+		c.Burst = int(c.RateLimit)
+		if float64(c.Burst) < c.RateLimit {
+			c.Burst++
+		}
+	}
+	if c.BreakerCooldown <= 0 {
+		c.BreakerCooldown = defaultBreakerCooldown
+	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = defaultMaxIdlePerHost
+	}
+}
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redacted = "***"
+
+// defaultRedactFields 默认脱敏字段 (不区分大小写)
+var defaultRedactFields = []string{
+	"authorization", "cookie", "set-cookie",
+	"x-api-key", "api_key", "apikey", "tron-pro-api-key",
+	"x-token", "access-token", "access_token", "token", "session_key",
+	"secret", "password", "sign", "private_key",
+}
+
+type redactor map[string]struct{}
+
+func newRedactor(extra []string) redactor {
+	r := redactor{}
+	for _, f := range defaultRedactFields {
+		r[f] = struct{}{}
+	}
+	for _, f := range extra {
+		r[strings.ToLower(f)] = struct{}{}
+	}
+	return r
+}
+
+func (r redactor) hit(name string) bool {
+	_, ok := r[strings.ToLower(name)]
+	return ok
+}
+
+// header 脱敏后的 header
+func (r redactor) header(h http.Header) map[string]string {
+	res := make(map[string]string, len(h))
+	for k, v := range h {
+		if r.hit(k) {
+			res[k] = redacted
+		} else {
+			res[k] = strings.Join(v, ",")
+		}
+	}
+	return res
+}
+
+// url 脱敏 query 参数、路径中的密钥 (如 QuickNode 的 /<token>/jsonrpc) 与 userinfo 密码
+func (r redactor) url(u *url.URL) string {
+	c := *u
+	if segs := strings.Split(c.EscapedPath(), "/"); len(segs) > 1 {
+		changed := false
+		for i, seg := range segs {
+			if pathSecret(seg) {
+				segs[i] = redacted
+				changed = true
+			}
+		}
+		if changed {
+			c.RawPath = strings.Join(segs, "/")
+			c.Path, _ = url.PathUnescape(c.RawPath)
+		}
+	}
+	if c.RawQuery != "" {
+		q := c.Query()
+		for k := range q {
+			if r.hit(k) {
+				q.Set(k, redacted)
+			}
+		}
+		c.RawQuery = q.Encode()
+	}
+	return c.Redacted()
+}
+
+// err 脱敏错误中携带的 URL (net/http 返回的 *url.Error 含完整请求地址)
+func (r redactor) err(err error) error {
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		return err
+	}
+	u, perr := url.Parse(ue.URL)
+	if perr != nil {
+		return &url.Error{Op: ue.Op, URL: redacted, Err: ue.Err}
+	}
+	return &url.Error{Op: ue.Op, URL: r.url(u), Err: ue.Err}
+}
+
+// minPathSecretLen 路径段按密钥脱敏的最小长度
+const minPathSecretLen = 32
+
+// pathSecret 路径段是否像密钥：不短于 minPathSecretLen 的十六进制串
+func pathSecret(seg string) bool {
+	if len(seg) < minPathSecretLen {
+		return false
+	}
+	for i := 0; i < len(seg); i++ {
+		c := seg[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// body 脱敏 JSON 或表单请求体，其他格式原样返回
+func (r redactor) body(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err == nil {
+		out, _ := json.Marshal(r.value(v))
+		return string(out)
+	}
+
+	if q, err := url.ParseQuery(string(b)); err == nil && len(q) > 0 && !strings.ContainsAny(string(b), "{}<>\n") {
+		for k := range q {
+			if r.hit(k) {
+				q.Set(k, redacted)
+			}
+		}
+		return q.Encode()
+	}
+
+	return string(b)
+}
+
+func (r redactor) value(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			if r.hit(k) {
+				x[k] = redacted
+			} else {
+				x[k] = r.value(val)
+			}
+		}
+		return x
+	case []any:
+		for i := range x {
+			x[i] = r.value(x[i])
+		}
+		return x
+	default:
+		return v
+	}
+}
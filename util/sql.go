@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 )
 
+// OnDuplicate 生成单行 INSERT ... ON DUPLICATE KEY UPDATE 语句，列按名称排序保证语句稳定
 func OnDuplicate(table string, obj map[string]interface{}) (string, []interface{}) {
-	var keys []string
-	var values []interface{}
-
-	for k, v := range obj {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
 		keys = append(keys, k)
-		values = append(values, v)
+	}
+	sort.Strings(keys)
+
+	values := make([]interface{}, 0, len(keys)*2)
+	for _, k := range keys {
+		values = append(values, obj[k])
 	}
 
 	buf := new(bytes.Buffer)